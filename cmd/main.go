@@ -1,8 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
 	"github.com/alissonmunhoz/go-crud-products/internal/config"
 	"github.com/alissonmunhoz/go-crud-products/internal/router"
+	"github.com/alissonmunhoz/go-crud-products/internal/tracing"
 )
 
 var (
@@ -15,15 +25,79 @@ var (
 // @host      localhost:8080
 // @BasePath  /v1
 // @schemes   http
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the JWT.
 func main() {
+	args := os.Args[1:]
+
+	// go-crud-products config print [flags]: mostra a configuração efetiva
+	if len(args) >= 2 && args[0] == "config" && args[1] == "print" {
+		os.Exit(printConfig(args[2:]))
+	}
 
+	cfg, err := config.Load(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+
+	if err := config.InitLogging(cfg.Log); err != nil {
+		fmt.Fprintf(os.Stderr, "logging initialization error: %v\n", err)
+		os.Exit(2)
+	}
 	logger = *config.GetLogger("main")
 
-	err := config.Init()
+	// SIGTERM vem do Kubernetes no rollout; as requisições em andamento são drenadas
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err = config.Init(ctx, cfg)
 	if err != nil {
 		logger.Errorf("Config initalization error: %v", err)
 		return
 	}
 
-	router.Initialize()
+	shutdownTracing, err := tracing.Init(ctx, config.GetTracing())
+	if err != nil {
+		logger.Errorf("Tracing initialization error: %v", err)
+		return
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Errorf("Tracing shutdown error: %v", err)
+		}
+	}()
+
+	if err := router.Initialize(ctx); err != nil {
+		logger.Errorf("Router initialization error: %v", err)
+	}
+
+	if err := config.Close(); err != nil {
+		logger.Errorf("Database close error: %v", err)
+	}
+}
+
+func printConfig(args []string) int {
+	cfg, err := config.Load(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 2
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error printing configuration: %v\n", err)
+		return 1
+	}
+	return 0
 }
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const chainHeadID = 1
+
+// appends from this process queue here instead of piling up on the row lock
+var mu sync.Mutex
+
+// ComputeHash derives the chain hash of an entry from its content and the
+// hash of the entry before it.
+func ComputeHash(e schemas.AuditEntry) string {
+	parts := []string{
+		e.PrevHash,
+		e.TenantID,
+		e.Actor,
+		e.IP,
+		e.RequestID,
+		e.Method,
+		e.Route,
+		e.Path,
+		strconv.Itoa(e.Status),
+		strconv.FormatInt(e.LatencyMs, 10),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Append links the entry to the current head of the chain and stores it.
+func Append(db *gorm.DB, e *schemas.AuditEntry) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	// MySQL keeps milliseconds; hash what will be read back
+	e.CreatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	// the tenant plugin would only fill it in on insert, after hashing
+	if id, ok := tenant.FromContext(db.Statement.Context); ok && e.TenantID == "" {
+		e.TenantID = id
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		head := schemas.AuditChainHead{ID: chainHeadID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&head).Error; err != nil {
+			return fmt.Errorf("error initializing audit chain: %v", err)
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&head, chainHeadID).Error; err != nil {
+			return fmt.Errorf("error locking audit chain: %v", err)
+		}
+
+		e.PrevHash = head.Hash
+		e.Hash = ComputeHash(*e)
+
+		if err := tx.Create(e).Error; err != nil {
+			return fmt.Errorf("error saving audit entry: %v", err)
+		}
+
+		return tx.Model(&head).Update("hash", e.Hash).Error
+	})
+}
+
+// VerifyChain checks that every entry links to prev and that its content
+// still matches its hash. It returns the hash to continue from and, when the
+// chain is broken, the ID of the first offending entry.
+func VerifyChain(entries []schemas.AuditEntry, prev string) (string, uint) {
+	for _, e := range entries {
+		if e.PrevHash != prev || ComputeHash(e) != e.Hash {
+			return prev, e.ID
+		}
+		prev = e.Hash
+	}
+	return prev, 0
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func buildChain(n int) []schemas.AuditEntry {
+	entries := make([]schemas.AuditEntry, 0, n)
+	prev := ""
+	for i := 0; i < n; i++ {
+		e := schemas.AuditEntry{
+			ID:        uint(i + 1),
+			TenantID:  "loja1",
+			Actor:     "maria",
+			Method:    "PUT",
+			Route:     "/v1/product",
+			Path:      "/v1/product?id=7",
+			Status:    200,
+			PrevHash:  prev,
+			CreatedAt: time.Date(2025, 1, 1, 10, 0, i, 0, time.UTC),
+		}
+		e.Hash = ComputeHash(e)
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestVerifyChain(t *testing.T) {
+	t.Run("cadeia íntegra é válida", func(t *testing.T) {
+		entries := buildChain(5)
+
+		last, brokenAt := VerifyChain(entries, "")
+		require.Zero(t, brokenAt)
+		require.Equal(t, entries[4].Hash, last)
+	})
+
+	t.Run("verificação continua entre lotes", func(t *testing.T) {
+		entries := buildChain(6)
+
+		prev, brokenAt := VerifyChain(entries[:3], "")
+		require.Zero(t, brokenAt)
+		_, brokenAt = VerifyChain(entries[3:], prev)
+		require.Zero(t, brokenAt)
+	})
+
+	t.Run("detecta conteúdo alterado", func(t *testing.T) {
+		entries := buildChain(5)
+		entries[2].Status = 500
+
+		_, brokenAt := VerifyChain(entries, "")
+		require.Equal(t, uint(3), brokenAt)
+	})
+
+	t.Run("detecta entrada movida para outro tenant", func(t *testing.T) {
+		entries := buildChain(5)
+		entries[1].TenantID = "loja2"
+
+		_, brokenAt := VerifyChain(entries, "")
+		require.Equal(t, uint(2), brokenAt)
+	})
+
+	t.Run("detecta entrada removida", func(t *testing.T) {
+		entries := buildChain(5)
+		entries = append(entries[:1], entries[2:]...)
+
+		_, brokenAt := VerifyChain(entries, "")
+		require.Equal(t, uint(3), brokenAt)
+	})
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyTag = "pk"
+	// last_used_at is refreshed at most this often to avoid a write per request
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	ErrRevokedAPIKey = errors.New("api key revoked")
+	ErrExpiredAPIKey = errors.New("api key expired")
+)
+
+// GenerateAPIKey issues new key material in the form pk_<prefix>_<secret>.
+// The prefix identifies the key in storage; only the hash of the whole key
+// is kept.
+func GenerateAPIKey() (plain, prefix, hash string, err error) {
+	p := make([]byte, 6)
+	s := make([]byte, 32)
+	if _, err = rand.Read(p); err != nil {
+		return "", "", "", err
+	}
+	if _, err = rand.Read(s); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(p)
+	plain = fmt.Sprintf("%s_%s_%s", apiKeyTag, prefix, base64.RawURLEncoding.EncodeToString(s))
+	return plain, prefix, HashAPIKey(plain), nil
+}
+
+func HashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func apiKeyPrefix(plain string) (string, bool) {
+	parts := strings.SplitN(plain, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func SplitScopes(scopes string) []Permission {
+	var out []Permission
+	for _, s := range strings.Split(scopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, Permission(s))
+		}
+	}
+	return out
+}
+
+// APIKeyAuthenticator resolves X-API-Key values to principals.
+type APIKeyAuthenticator struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyAuthenticator(db *gorm.DB) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{db: db}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, plain string) (*Principal, error) {
+	prefix, ok := apiKeyPrefix(plain)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	// the key itself tells which tenant the caller belongs to
+	db := a.db.WithContext(tenant.WithoutScope(ctx))
+
+	var key schemas.APIKey
+	if err := db.Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashAPIKey(plain)), []byte(key.Hash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, ErrRevokedAPIKey
+	}
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, ErrExpiredAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		// best effort: a failed bookkeeping write must not reject the call
+		db.Model(&key).UpdateColumn("last_used_at", now)
+	}
+
+	return &Principal{
+		// the id survives rotation, so audit entries stay attributable
+		Subject:     fmt.Sprintf("apikey:%d", key.ID),
+		Method:      MethodAPIKey,
+		Tenant:      key.TenantID,
+		Permissions: SplitScopes(key.Scopes),
+	}, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+)
+
+func newMockGorm(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, *sql.DB) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	dialector := mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	})
+
+	gdb, err := gorm.Open(dialector, &gorm.Config{
+		Logger: glogger.Default.LogMode(glogger.Silent),
+	})
+	require.NoError(t, err)
+
+	return gdb, mock, sqlDB
+}
+
+var apiKeyCols = []string{"id", "name", "prefix", "hash", "scopes", "created_by", "expires_at", "last_used_at", "revoked_at", "created_at", "updated_at"}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	plain, prefix, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+	now := time.Now()
+
+	t.Run("gera chave com prefixo recuperável", func(t *testing.T) {
+		p, ok := apiKeyPrefix(plain)
+		require.True(t, ok)
+		require.Equal(t, prefix, p)
+		require.Equal(t, hash, HashAPIKey(plain))
+	})
+
+	t.Run("rejeita formato inválido sem consultar o banco", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		_, err := NewAPIKeyAuthenticator(gdb).Authenticate(context.Background(), "qualquer-coisa")
+		require.ErrorIs(t, err, ErrInvalidAPIKey)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("aceita chave válida com escopos e atualiza último uso", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*api_keys.*WHERE prefix`).
+			WithArgs(prefix, 1).
+			WillReturnRows(sqlmock.NewRows(apiKeyCols).
+				AddRow(3, "erp", prefix, hash, "product:read,stock:adjust", "admin", nil, nil, nil, now, now))
+		mock.ExpectBegin()
+		mock.ExpectExec(`(?is)UPDATE.*api_keys.*SET.*last_used_at`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		p, err := NewAPIKeyAuthenticator(gdb).Authenticate(context.Background(), plain)
+		require.NoError(t, err)
+		require.Equal(t, "apikey:3", p.Subject)
+		require.True(t, p.Can(StockAdjust))
+		require.False(t, p.Can(PriceWrite))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rejeita segredo diferente com mesmo prefixo", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*api_keys`).WillReturnRows(sqlmock.NewRows(apiKeyCols).
+			AddRow(3, "erp", prefix, hash, "product:read", "admin", nil, nil, nil, now, now))
+
+		_, err := NewAPIKeyAuthenticator(gdb).Authenticate(context.Background(), "pk_"+prefix+"_outro")
+		require.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("rejeita chave revogada", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*api_keys`).WillReturnRows(sqlmock.NewRows(apiKeyCols).
+			AddRow(3, "erp", prefix, hash, "product:read", "admin", nil, nil, now, now, now))
+
+		_, err := NewAPIKeyAuthenticator(gdb).Authenticate(context.Background(), plain)
+		require.ErrorIs(t, err, ErrRevokedAPIKey)
+	})
+
+	t.Run("rejeita chave expirada", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*api_keys`).WillReturnRows(sqlmock.NewRows(apiKeyCols).
+			AddRow(3, "erp", prefix, hash, "product:read", "admin", now.Add(-time.Hour), nil, nil, now, now))
+
+		_, err := NewAPIKeyAuthenticator(gdb).Authenticate(context.Background(), plain)
+		require.ErrorIs(t, err, ErrExpiredAPIKey)
+	})
+}
@@ -0,0 +1,41 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+const (
+	// SubjectKey holds the authenticated subject in gin.Context
+	SubjectKey   = "subject"
+	principalKey = "principal"
+)
+
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "apikey"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	// Method tells how the caller proved its identity; empty when
+	// authentication is disabled
+	Method string
+	// Tenant is set when the credential is bound to a single tenant
+	Tenant      string
+	Roles       []string
+	Permissions []Permission
+	Claims      map[string]interface{}
+}
+
+func SetPrincipal(ctx *gin.Context, p *Principal) {
+	ctx.Set(principalKey, p)
+	ctx.Set(SubjectKey, p.Subject)
+}
+
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	v, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil, false
+	}
+	p, ok := v.(*Principal)
+	return p, ok
+}
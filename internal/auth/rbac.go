@@ -0,0 +1,91 @@
+package auth
+
+import "github.com/gin-gonic/gin"
+
+type Permission string
+
+const (
+	ProductRead   Permission = "product:read"
+	ProductWrite  Permission = "product:write"
+	ProductDelete Permission = "product:delete"
+	StockAdjust   Permission = "stock:adjust"
+	PriceWrite    Permission = "price:write"
+	AuditRead     Permission = "audit:read"
+	APIKeyManage  Permission = "apikey:manage"
+	// suppliers and what each charges for a product
+	SupplierManage Permission = "supplier:manage"
+	// cost prices and margins on product reads
+	CostRead Permission = "cost:read"
+	// webhook subscriptions and their delivery log
+	WebhookManage Permission = "webhook:manage"
+	// operating across tenants: picking one per request and checking the
+	// audit chain of every tenant. Never part of a tenant role nor of API
+	// key scopes.
+	PlatformAdmin Permission = "platform:admin"
+)
+
+const (
+	RoleAdmin      = "admin"
+	RoleCatalog    = "catalog"
+	RoleWarehouse  = "warehouse"
+	RoleViewer     = "viewer"
+	RolePurchasing = "purchasing"
+	RolePlatform   = "platform"
+)
+
+var AllPermissions = []Permission{
+	ProductRead, ProductWrite, ProductDelete, StockAdjust, PriceWrite, AuditRead, APIKeyManage, SupplierManage, CostRead, WebhookManage,
+}
+
+var rolePermissions = map[string][]Permission{
+	RoleAdmin:      AllPermissions,
+	RoleCatalog:    {ProductRead, ProductWrite, PriceWrite},
+	RoleWarehouse:  {ProductRead, StockAdjust},
+	RoleViewer:     {ProductRead},
+	RolePurchasing: {ProductRead, SupplierManage, CostRead},
+	RolePlatform:   {PlatformAdmin},
+}
+
+// Can reports whether the principal holds perm, either granted directly or
+// through one of its roles.
+func (p *Principal) Can(perm Permission) bool {
+	for _, granted := range p.Permissions {
+		if granted == perm {
+			return true
+		}
+	}
+	for _, role := range p.Roles {
+		for _, granted := range rolePermissions[role] {
+			if granted == perm {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Can reports whether the caller of the request holds perm. Requests without
+// a principal hold nothing.
+func Can(ctx *gin.Context, perm Permission) bool {
+	p, ok := GetPrincipal(ctx)
+	return ok && p.Can(perm)
+}
+
+// Missing returns the first permission in perms the caller does not hold.
+func Missing(ctx *gin.Context, perms ...Permission) (Permission, bool) {
+	for _, perm := range perms {
+		if !Can(ctx, perm) {
+			return perm, true
+		}
+	}
+	return "", false
+}
+
+func IsKnownPermission(perm Permission) bool {
+	for _, p := range AllPermissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipalCan(t *testing.T) {
+	t.Run("estoquista ajusta estoque mas não preço nem remove", func(t *testing.T) {
+		p := &Principal{Subject: "joao", Roles: []string{RoleWarehouse}}
+
+		require.True(t, p.Can(StockAdjust))
+		require.True(t, p.Can(ProductRead))
+		require.False(t, p.Can(PriceWrite))
+		require.False(t, p.Can(ProductDelete))
+	})
+
+	t.Run("apenas admin remove produtos", func(t *testing.T) {
+		for role := range rolePermissions {
+			p := &Principal{Roles: []string{role}}
+			require.Equal(t, role == RoleAdmin, p.Can(ProductDelete), role)
+		}
+	})
+
+	t.Run("compras vê custos e cuida de fornecedores sem mexer no catálogo", func(t *testing.T) {
+		p := &Principal{Roles: []string{RolePurchasing}}
+
+		require.True(t, p.Can(CostRead))
+		require.True(t, p.Can(SupplierManage))
+		require.False(t, p.Can(ProductWrite))
+		require.False(t, p.Can(PriceWrite))
+	})
+
+	t.Run("apenas o papel de plataforma opera entre tenants", func(t *testing.T) {
+		for role := range rolePermissions {
+			p := &Principal{Roles: []string{role}}
+			require.Equal(t, role == RolePlatform, p.Can(PlatformAdmin), role)
+		}
+		require.False(t, IsKnownPermission(PlatformAdmin))
+	})
+
+	t.Run("permissões diretas valem sem papel", func(t *testing.T) {
+		p := &Principal{Permissions: []Permission{PriceWrite}}
+
+		require.True(t, p.Can(PriceWrite))
+		require.False(t, p.Can(ProductWrite))
+	})
+
+	t.Run("papel desconhecido não concede nada", func(t *testing.T) {
+		p := &Principal{Roles: []string{"root"}}
+
+		require.False(t, p.Can(ProductRead))
+	})
+}
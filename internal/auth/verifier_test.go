@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+)
+
+const testSecret = "segredo-de-teste"
+
+func signHS256(t *testing.T, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func writeJWKS(t *testing.T, kid string, key *rsa.PublicKey) string {
+	set := map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	raw, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+	return path
+}
+
+func TestVerifier(t *testing.T) {
+	v, err := NewVerifier(config.AuthConfig{HS256Secret: testSecret, Issuer: "products"})
+	require.NoError(t, err)
+
+	t.Run("aceita token HS256 válido", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{
+			"sub":   "maria",
+			"iss":   "products",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"roles": []string{"admin"},
+		})
+
+		p, err := v.Verify(token)
+		require.NoError(t, err)
+		require.Equal(t, "maria", p.Subject)
+		require.Equal(t, []string{"admin"}, p.Roles)
+	})
+
+	t.Run("rejeita token expirado", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{
+			"sub": "maria",
+			"iss": "products",
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		})
+
+		_, err := v.Verify(token)
+		require.ErrorIs(t, err, ErrExpiredToken)
+	})
+
+	t.Run("rejeita token sem exp", func(t *testing.T) {
+		_, err := v.Verify(signHS256(t, jwt.MapClaims{"sub": "maria", "iss": "products"}))
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("rejeita emissor diferente", func(t *testing.T) {
+		token := signHS256(t, jwt.MapClaims{
+			"sub": "maria",
+			"iss": "outro",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+
+		_, err := v.Verify(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("rejeita token vazio", func(t *testing.T) {
+		_, err := v.Verify("")
+		require.ErrorIs(t, err, ErrMissingToken)
+	})
+
+	t.Run("aceita RS256 com chave do JWKS e rejeita alg não configurado", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		rv, err := NewVerifier(config.AuthConfig{JWKSFile: writeJWKS(t, "k1", &key.PublicKey)})
+		require.NoError(t, err)
+
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub": "integracao",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		tok.Header["kid"] = "k1"
+		signed, err := tok.SignedString(key)
+		require.NoError(t, err)
+
+		p, err := rv.Verify(signed)
+		require.NoError(t, err)
+		require.Equal(t, "integracao", p.Subject)
+
+		// sem segredo HS256 configurado, tokens HS256 não são aceitos
+		_, err = rv.Verify(signHS256(t, jwt.MapClaims{"sub": "x", "exp": time.Now().Add(time.Hour).Unix()}))
+		require.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type AlertsConfig struct {
+	// por onde saem os alertas de estoque baixo: log, webhook e/ou smtp;
+	// none desliga
+	Notifiers []string `cfg:"notifiers" env:"ALERT_NOTIFIERS" default:"log"`
+	// mudanças de estoque esperando avaliação; com a fila cheia são descartadas
+	QueueSize int `cfg:"queueSize" env:"ALERT_QUEUE_SIZE" default:"1024"`
+
+	WebhookURL     string        `cfg:"webhookURL" env:"ALERT_WEBHOOK_URL"`
+	WebhookTimeout time.Duration `cfg:"webhookTimeout" env:"ALERT_WEBHOOK_TIMEOUT" default:"5s"`
+
+	// servidor SMTP; o padrão é um Mailpit/MailHog local
+	SMTPAddr     string   `cfg:"smtpAddr" env:"ALERT_SMTP_ADDR" default:"localhost:1025"`
+	SMTPUsername string   `cfg:"smtpUsername" env:"ALERT_SMTP_USERNAME"`
+	SMTPPassword string   `cfg:"smtpPassword" env:"ALERT_SMTP_PASSWORD" secret:"true"`
+	SMTPFrom     string   `cfg:"smtpFrom" env:"ALERT_SMTP_FROM" default:"estoque@localhost"`
+	SMTPTo       []string `cfg:"smtpTo" env:"ALERT_SMTP_TO"`
+	// prazo para conectar e entregar cada e-mail
+	SMTPTimeout time.Duration `cfg:"smtpTimeout" env:"ALERT_SMTP_TIMEOUT" default:"10s"`
+}
+
+func (c AlertsConfig) validate() error {
+	var errs []error
+	for _, n := range c.Notifiers {
+		switch n {
+		case "log", "none":
+		case "webhook":
+			if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("alerts.webhookURL (ALERT_WEBHOOK_URL) is %q, expected an http(s) URL for the webhook notifier", c.WebhookURL))
+			}
+		case "smtp":
+			if c.SMTPAddr == "" || c.SMTPFrom == "" || len(c.SMTPTo) == 0 {
+				errs = append(errs, errors.New("alerts.smtpAddr, alerts.smtpFrom and alerts.smtpTo (ALERT_SMTP_ADDR, ALERT_SMTP_FROM, ALERT_SMTP_TO) are required for the smtp notifier"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("alerts.notifiers (ALERT_NOTIFIERS) has unknown notifier %q, expected log, webhook, smtp or none", n))
+		}
+	}
+	if c.QueueSize < 1 {
+		errs = append(errs, errors.New("alerts.queueSize (ALERT_QUEUE_SIZE) must be positive"))
+	}
+	if c.WebhookTimeout <= 0 {
+		errs = append(errs, errors.New("alerts.webhookTimeout (ALERT_WEBHOOK_TIMEOUT) must be positive"))
+	}
+	if c.SMTPTimeout <= 0 {
+		errs = append(errs, errors.New("alerts.smtpTimeout (ALERT_SMTP_TIMEOUT) must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+type AuthConfig struct {
+	Enabled            bool   `cfg:"enabled" env:"AUTH_ENABLED" default:"true"`
+	HS256Secret        string `cfg:"hs256Secret" env:"JWT_HS256_SECRET" secret:"true"`
+	RS256PublicKeyFile string `cfg:"rs256PublicKeyFile" env:"JWT_RS256_PUBLIC_KEY_FILE"`
+	JWKSFile           string `cfg:"jwksFile" env:"JWT_JWKS_FILE"`
+	Issuer             string `cfg:"issuer" env:"JWT_ISSUER"`
+	Audience           string `cfg:"audience" env:"JWT_AUDIENCE"`
+	// rotas (padrão do Gin) liberadas sem token
+	PublicPaths []string `cfg:"publicPaths" env:"AUTH_PUBLIC_PATHS" default:"/swagger/*any"`
+}
+
+func (c AuthConfig) validate() error {
+	if c.Enabled && c.HS256Secret == "" && c.RS256PublicKeyFile == "" && c.JWKSFile == "" {
+		return errors.New("auth.enabled is true but no JWT key is configured: set auth.hs256Secret (JWT_HS256_SECRET), auth.rs256PublicKeyFile (JWT_RS256_PUBLIC_KEY_FILE) or auth.jwksFile (JWT_JWKS_FILE)")
+	}
+	return nil
+}
+
+// helper para listas separadas por vírgula
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -1,20 +1,48 @@
 package config
 
 import (
+	"context"
 	"fmt"
 
 	"gorm.io/gorm"
 )
 
 var (
-	db     *gorm.DB
-	logger *Logger
+	db          *gorm.DB
+	logger      *Logger
+	auth        AuthConfig
+	tenancy     TenancyConfig
+	cors        CORSConfig
+	rateLimit   RateLimitConfig
+	idempotency IdempotencyConfig
+	server      ServerConfig
+	metricsCfg  MetricsConfig
+	tracing     TracingConfig
+	searchCfg   SearchConfig
+	mediaCfg    MediaConfig
+	alertsCfg   AlertsConfig
+	webhooksCfg WebhooksConfig
+	outboxCfg   OutboxConfig
 )
 
-func Init() error {
-	var err error
+// Init stores cfg for the getters below and connects to the database.
+func Init(ctx context.Context, cfg Config) error {
+	auth = cfg.Auth
+	tenancy = cfg.Tenancy
+	cors = cfg.CORS
+	rateLimit = cfg.RateLimit
+	idempotency = cfg.Idempotency
+	server = cfg.Server
+	metricsCfg = cfg.Metrics
+	tracing = cfg.Tracing
+	searchCfg = cfg.Search
+	mediaCfg = cfg.Media
+	alertsCfg = cfg.Alerts
+	webhooksCfg = cfg.Webhooks
+	outboxCfg = cfg.Outbox
 
-	db, err = InitializeMySQL()
+	var err error
+	db, err = InitializeMySQL(ctx, cfg.Database)
 
 	if err != nil {
 		return fmt.Errorf("error initializing mysql: %v", err)
@@ -27,6 +55,70 @@ func GetMySQL() *gorm.DB {
 	return db
 }
 
+func GetAuth() AuthConfig {
+	return auth
+}
+
+func GetTenancy() TenancyConfig {
+	return tenancy
+}
+
+func GetCORS() CORSConfig {
+	return cors
+}
+
+func GetRateLimit() RateLimitConfig {
+	return rateLimit
+}
+
+func GetIdempotency() IdempotencyConfig {
+	return idempotency
+}
+
+func GetServer() ServerConfig {
+	return server
+}
+
+func GetMetrics() MetricsConfig {
+	return metricsCfg
+}
+
+func GetTracing() TracingConfig {
+	return tracing
+}
+
+func GetSearch() SearchConfig {
+	return searchCfg
+}
+
+func GetMedia() MediaConfig {
+	return mediaCfg
+}
+
+func GetAlerts() AlertsConfig {
+	return alertsCfg
+}
+
+func GetWebhooks() WebhooksConfig {
+	return webhooksCfg
+}
+
+func GetOutbox() OutboxConfig {
+	return outboxCfg
+}
+
+// Close releases the database pool. Call it once the HTTP server has drained.
+func Close() error {
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
 func GetLogger(p string) *Logger {
 
 	logger = NewLogger(p)
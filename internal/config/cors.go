@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AnyOrigin libera qualquer origem; não pode ser combinado com credenciais.
+const AnyOrigin = "*"
+
+type CORSConfig struct {
+	// origens exatas (https://app.exemplo.com), padrões de subdomínio
+	// (https://*.exemplo.com) ou "*"
+	AllowOrigins     []string      `cfg:"allowOrigins" env:"CORS_ALLOW_ORIGINS" default:"http://localhost:3000"`
+	AllowMethods     []string      `cfg:"allowMethods" env:"CORS_ALLOW_METHODS" default:"GET,POST,PUT,DELETE,PATCH,OPTIONS"`
+	AllowHeaders     []string      `cfg:"allowHeaders" env:"CORS_ALLOW_HEADERS" default:"Origin,Content-Type,Authorization,X-API-Key,X-Request-ID,Idempotency-Key"`
+	ExposeHeaders    []string      `cfg:"exposeHeaders" env:"CORS_EXPOSE_HEADERS" default:"Content-Length,ETag,Link,X-Total-Count,X-Request-ID,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After,Idempotent-Replayed"`
+	AllowCredentials bool          `cfg:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS" default:"true"`
+	MaxAge           time.Duration `cfg:"maxAge" env:"CORS_MAX_AGE" default:"12h"`
+}
+
+// originPattern é uma origem da configuração já normalizada; com wildcard,
+// host guarda o sufixo (".exemplo.com").
+type originPattern struct {
+	scheme   string
+	host     string
+	wildcard bool
+}
+
+func parseOriginPattern(origin string) (originPattern, error) {
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil {
+		return originPattern{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return originPattern{}, errors.New("scheme must be http or https")
+	}
+	if u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return originPattern{}, errors.New("expected scheme://host[:port] without path, query or credentials")
+	}
+
+	p := originPattern{scheme: u.Scheme, host: u.Host}
+	if strings.HasPrefix(p.host, "*.") {
+		p.wildcard = true
+		p.host = p.host[1:]
+	}
+	if strings.Contains(p.host, "*") {
+		return originPattern{}, errors.New("wildcard is only allowed as the leftmost label (https://*.example.com)")
+	}
+	// *.com liberaria qualquer site do TLD
+	if p.wildcard && strings.Count(strings.Split(p.host, ":")[0], ".") < 2 {
+		return originPattern{}, errors.New("wildcard must be followed by at least two labels (https://*.example.com)")
+	}
+	return p, nil
+}
+
+func (p originPattern) matches(scheme, host string) bool {
+	if p.scheme != scheme {
+		return false
+	}
+	if p.wildcard {
+		return len(host) > len(p.host) && strings.HasSuffix(host, p.host)
+	}
+	return host == p.host
+}
+
+// allowHeader acrescenta name aos headers liberados, se ainda não estiver lá.
+// O header de tenant entra assim, com o nome configurado em TENANT_HEADER.
+func (c *CORSConfig) allowHeader(name string) {
+	if name == "" {
+		return
+	}
+	for _, h := range c.AllowHeaders {
+		if strings.EqualFold(h, name) {
+			return
+		}
+	}
+	c.AllowHeaders = append(c.AllowHeaders, name)
+}
+
+// AllowsAnyOrigin indica se a política libera todas as origens ("*").
+func (c CORSConfig) AllowsAnyOrigin() bool {
+	return len(c.AllowOrigins) == 1 && c.AllowOrigins[0] == AnyOrigin
+}
+
+// AllowsOrigin confere o header Origin da requisição contra as origens
+// configuradas, incluindo os padrões de subdomínio.
+func (c CORSConfig) AllowsOrigin(origin string) bool {
+	if c.AllowsAnyOrigin() {
+		return true
+	}
+	u, err := url.Parse(strings.ToLower(origin))
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, allowed := range c.AllowOrigins {
+		p, err := parseOriginPattern(allowed)
+		if err == nil && p.matches(u.Scheme, u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c CORSConfig) validate() error {
+	var errs []error
+	if len(c.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("cors.allowOrigins (CORS_ALLOW_ORIGINS) must list at least one origin"))
+	}
+	for _, origin := range c.AllowOrigins {
+		if origin == AnyOrigin {
+			if len(c.AllowOrigins) > 1 {
+				errs = append(errs, errors.New("cors.allowOrigins (CORS_ALLOW_ORIGINS): \"*\" cannot be combined with other origins"))
+			}
+			if c.AllowCredentials {
+				errs = append(errs, errors.New("cors.allowOrigins (CORS_ALLOW_ORIGINS) is \"*\" while cors.allowCredentials (CORS_ALLOW_CREDENTIALS) is true: list the origins explicitly or disable credentials"))
+			}
+			continue
+		}
+		if _, err := parseOriginPattern(origin); err != nil {
+			errs = append(errs, fmt.Errorf("cors.allowOrigins (CORS_ALLOW_ORIGINS): invalid origin %q: %w", origin, err))
+		}
+	}
+
+	if len(c.AllowMethods) == 0 {
+		errs = append(errs, errors.New("cors.allowMethods (CORS_ALLOW_METHODS) must list at least one method"))
+	}
+	for _, method := range c.AllowMethods {
+		if method != strings.ToUpper(method) || strings.ContainsAny(method, " *") {
+			errs = append(errs, fmt.Errorf("cors.allowMethods (CORS_ALLOW_METHODS): invalid method %q", method))
+		}
+	}
+
+	// com credenciais o navegador trata "*" como nome literal de header
+	if c.AllowCredentials {
+		for _, h := range append(append([]string{}, c.AllowHeaders...), c.ExposeHeaders...) {
+			if h == "*" {
+				errs = append(errs, errors.New("cors.allowHeaders and cors.exposeHeaders cannot contain \"*\" while cors.allowCredentials (CORS_ALLOW_CREDENTIALS) is true"))
+				break
+			}
+		}
+	}
+
+	if c.MaxAge < 0 {
+		errs = append(errs, errors.New("cors.maxAge (CORS_MAX_AGE) must not be negative"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type DatabaseConfig struct {
+	Host     string `cfg:"host" env:"DB_HOST" default:"localhost"`
+	Port     int    `cfg:"port" env:"DB_PORT" default:"3306"`
+	User     string `cfg:"user" env:"DB_USER" default:"root"`
+	Password string `cfg:"password" env:"DB_PASSWORD" default:"root" secret:"true"`
+	Name     string `cfg:"name" env:"DB_NAME" default:"products"`
+
+	MaxIdleConns    int           `cfg:"maxIdleConns" env:"DB_MAX_IDLE_CONNS" default:"10"`
+	MaxOpenConns    int           `cfg:"maxOpenConns" env:"DB_MAX_OPEN_CONNS" default:"50"`
+	ConnMaxLifetime time.Duration `cfg:"connMaxLifetime" env:"DB_CONN_MAX_LIFETIME" default:"60m"`
+	// quanto tempo esperar o MySQL subir antes de desistir
+	StartupTimeout time.Duration `cfg:"startupTimeout" env:"DB_STARTUP_TIMEOUT" default:"2m"`
+	// queries mais lentas que isso são logadas como WARN
+	SlowQueryThreshold time.Duration `cfg:"slowQueryThreshold" env:"DB_SLOW_QUERY_THRESHOLD" default:"200ms"`
+}
+
+// DSN recomendado pelo GORM: charset utf8mb4 + parseTime + loc
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=true&loc=Local",
+		c.User, c.Password, c.Host, c.Port, c.Name,
+	)
+}
+
+func (c DatabaseConfig) validate() error {
+	var errs []error
+	if c.Host == "" {
+		errs = append(errs, errors.New("database.host (DB_HOST) is required"))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("database.port (DB_PORT) is %d, expected 1-65535", c.Port))
+	}
+	if c.Name == "" {
+		errs = append(errs, errors.New("database.name (DB_NAME) is required"))
+	}
+	if c.MaxOpenConns <= 0 {
+		errs = append(errs, errors.New("database.maxOpenConns (DB_MAX_OPEN_CONNS) must be positive"))
+	}
+	if c.MaxIdleConns < 0 || c.MaxIdleConns > c.MaxOpenConns {
+		errs = append(errs, errors.New("database.maxIdleConns (DB_MAX_IDLE_CONNS) must be between 0 and database.maxOpenConns"))
+	}
+	if c.ConnMaxLifetime < 0 || c.StartupTimeout < 0 || c.SlowQueryThreshold < 0 {
+		errs = append(errs, errors.New("database.connMaxLifetime, database.startupTimeout and database.slowQueryThreshold must not be negative"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+)
+
+// gormLogger routes GORM's logging through the service logger. Failed
+// statements are errors, statements slower than the threshold are
+// warnings and, with LOG_LEVEL=debug, every statement is logged. Statements
+// are logged with placeholders: the values can hold personal data and
+// secrets, as with the traces.
+type gormLogger struct {
+	logger        *Logger
+	slowThreshold time.Duration
+	level         glogger.LogLevel
+}
+
+func NewGormLogger(slowThreshold time.Duration) glogger.Interface {
+	return &gormLogger{
+		logger:        NewLogger("gorm"),
+		slowThreshold: slowThreshold,
+		level:         glogger.Info,
+	}
+}
+
+func (g *gormLogger) LogMode(level glogger.LogLevel) glogger.Interface {
+	clone := *g
+	clone.level = level
+	return &clone
+}
+
+func (g *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= glogger.Info {
+		g.logger.WithContext(ctx).Infof(msg, args...)
+	}
+}
+
+func (g *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= glogger.Warn {
+		g.logger.WithContext(ctx).Warnf(msg, args...)
+	}
+}
+
+func (g *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if g.level >= glogger.Error {
+		g.logger.WithContext(ctx).Errorf(msg, args...)
+	}
+}
+
+// ParamsFilter drops the bound values before GORM renders the statement for
+// Trace.
+func (g *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (g *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if g.level <= glogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	logger := g.logger.WithContext(ctx)
+
+	attrs := func() []slog.Attr {
+		sql, rows := fc()
+		return []slog.Attr{
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Float64("elapsed_ms", float64(elapsed.Microseconds())/1000),
+		}
+	}
+
+	switch {
+	case err != nil && g.level >= glogger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		logger.LogAttrs(slog.LevelError, "query error", append(attrs(), slog.String("error", err.Error()))...)
+	case g.slowThreshold > 0 && elapsed > g.slowThreshold && g.level >= glogger.Warn:
+		logger.LogAttrs(slog.LevelWarn, fmt.Sprintf("slow query (over %s)", g.slowThreshold), attrs()...)
+	case g.level >= glogger.Info && logLevel.Level() <= slog.LevelDebug:
+		logger.LogAttrs(slog.LevelDebug, "query", attrs()...)
+	}
+}
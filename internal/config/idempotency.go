@@ -0,0 +1,25 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type IdempotencyConfig struct {
+	// por quanto tempo a resposta de uma Idempotency-Key é reaproveitada
+	TTL time.Duration `cfg:"ttl" env:"IDEMPOTENCY_TTL" default:"24h"`
+	// por quanto tempo uma requisição em andamento segura a chave; depois
+	// disso uma nova tentativa pode assumi-la
+	Lease time.Duration `cfg:"lease" env:"IDEMPOTENCY_LEASE" default:"1m"`
+}
+
+func (c IdempotencyConfig) validate() error {
+	var errs []error
+	if c.TTL <= 0 {
+		errs = append(errs, errors.New("idempotency.ttl (IDEMPOTENCY_TTL) must be positive"))
+	}
+	if c.Lease <= 0 || c.Lease > c.TTL {
+		errs = append(errs, errors.New("idempotency.lease (IDEMPOTENCY_LEASE) must be positive and at most the ttl"))
+	}
+	return errors.Join(errs...)
+}
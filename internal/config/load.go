@@ -0,0 +1,423 @@
+package config
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the config file when --config is not given.
+const ConfigFileEnv = "CONFIG_FILE"
+
+const redacted = "<redacted>"
+
+// Config is the whole service configuration. Every leaf field declares its
+// file key (cfg), environment variable (env) and default; the command line
+// flag is derived from the file path, e.g. server.readTimeout becomes
+// --server.read-timeout. Fields marked secret can also be read from a file
+// through <ENV>_FILE, <key>File or --<flag>-file, and are redacted by Print.
+type Config struct {
+	Server      ServerConfig      `cfg:"server"`
+	Metrics     MetricsConfig     `cfg:"metrics"`
+	Database    DatabaseConfig    `cfg:"database"`
+	Log         LogConfig         `cfg:"log"`
+	Auth        AuthConfig        `cfg:"auth"`
+	Tenancy     TenancyConfig     `cfg:"tenancy"`
+	CORS        CORSConfig        `cfg:"cors"`
+	RateLimit   RateLimitConfig   `cfg:"rateLimit"`
+	Idempotency IdempotencyConfig `cfg:"idempotency"`
+	Tracing     TracingConfig     `cfg:"tracing"`
+	Search      SearchConfig      `cfg:"search"`
+	Media       MediaConfig       `cfg:"media"`
+	Alerts      AlertsConfig      `cfg:"alerts"`
+	Webhooks    WebhooksConfig    `cfg:"webhooks"`
+	Outbox      OutboxConfig      `cfg:"outbox"`
+}
+
+// Validate checks every section and reports all problems at once.
+func (c Config) Validate() error {
+	return errors.Join(
+		c.Server.validate(),
+		c.Metrics.validate(),
+		c.Database.validate(),
+		c.Log.validate(),
+		c.Auth.validate(),
+		c.Tenancy.validate(),
+		c.CORS.validate(),
+		c.RateLimit.validate(),
+		c.Idempotency.validate(),
+		c.Tracing.validate(),
+		c.Search.validate(),
+		c.Media.validate(),
+		c.Alerts.validate(),
+		c.Webhooks.validate(),
+		c.Outbox.validate(),
+	)
+}
+
+// Load builds the configuration from, in increasing precedence: defaults,
+// the YAML or TOML file given by --config (or CONFIG_FILE), environment
+// variables and command line flags. The result is validated.
+func Load(args []string) (Config, error) {
+	var cfg Config
+	fields := collectFields(reflect.ValueOf(&cfg).Elem(), "")
+
+	for _, f := range fields {
+		if f.def == "" {
+			continue
+		}
+		if err := setString(f.value, f.def); err != nil {
+			return cfg, fmt.Errorf("default of %s: %v", f.key, err)
+		}
+	}
+
+	fs := flag.NewFlagSet("go-crud-products", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv(ConfigFileEnv), "YAML or TOML config file")
+	for _, f := range fields {
+		fs.String(f.flag, "", fmt.Sprintf("%s (env %s)", f.key, f.env))
+		if f.secret {
+			fs.String(f.flag+"-file", "", fmt.Sprintf("file holding %s (env %s_FILE)", f.key, f.env))
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	if *configFile != "" {
+		if err := applyFile(fields, *configFile); err != nil {
+			return cfg, err
+		}
+	}
+
+	for _, f := range fields {
+		if v := os.Getenv(f.env); v != "" {
+			if err := setString(f.value, v); err != nil {
+				return cfg, fmt.Errorf("%s: %v", f.env, err)
+			}
+		}
+		if path := os.Getenv(f.env + "_FILE"); f.secret && path != "" {
+			if err := setFromFile(f.value, path); err != nil {
+				return cfg, fmt.Errorf("%s_FILE: %v", f.env, err)
+			}
+		}
+	}
+
+	byFlag := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byFlag[f.flag] = f
+	}
+	var flagErr error
+	fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "config" || flagErr != nil {
+			return
+		}
+		if f, ok := byFlag[strings.TrimSuffix(fl.Name, "-file")]; ok && f.secret && strings.HasSuffix(fl.Name, "-file") {
+			flagErr = wrapErr("--"+fl.Name, setFromFile(f.value, fl.Value.String()))
+			return
+		}
+		flagErr = wrapErr("--"+fl.Name, setString(byFlag[fl.Name].value, fl.Value.String()))
+	})
+	if flagErr != nil {
+		return cfg, flagErr
+	}
+	cfg.CORS.allowHeader(cfg.Tenancy.Header)
+
+	return cfg, cfg.Validate()
+}
+
+// Print writes the effective configuration as YAML with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	node := toNode(reflect.ValueOf(c), false)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+type field struct {
+	key    string
+	env    string
+	flag   string
+	def    string
+	secret bool
+	value  reflect.Value
+}
+
+func collectFields(v reflect.Value, prefix string) []field {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("cfg")
+		if key == "" {
+			continue
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fv := v.Field(i)
+		if sf.Type.Kind() == reflect.Struct && !isScalar(fv) {
+			out = append(out, collectFields(fv, key)...)
+			continue
+		}
+
+		out = append(out, field{
+			key:    key,
+			env:    sf.Tag.Get("env"),
+			flag:   flagName(key),
+			def:    sf.Tag.Get("default"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  fv,
+		})
+	}
+	return out
+}
+
+// isScalar tells leaf values that happen to be structs, such as a rate limit.
+func isScalar(v reflect.Value) bool {
+	_, ok := v.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// flagName turns server.readTimeout into server.read-timeout.
+func flagName(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if unicode.IsUpper(r) {
+			b.WriteByte('-')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setString(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q, expected something like 30s or 5m", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q, expected true or false", s)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		v.Set(reflect.ValueOf(splitList(s)))
+	case v.Kind() == reflect.Slice && (v.Type().Elem().Kind() == reflect.Int || v.Type().Elem().Kind() == reflect.Int64):
+		parts := splitList(s)
+		list := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, p := range parts {
+			n, err := strconv.ParseInt(p, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", p)
+			}
+			list.Index(i).SetInt(n)
+		}
+		v.Set(list)
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
+
+func setFromFile(v reflect.Value, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return setString(v, strings.TrimSpace(string(data)))
+}
+
+func applyFile(fields []field, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %v", err)
+	}
+
+	tree := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("unsupported config file %s: expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing config file %s: %v", path, err)
+	}
+
+	values := map[string]any{}
+	flatten(tree, "", values)
+
+	for _, f := range fields {
+		if raw, ok := values[f.key]; ok {
+			delete(values, f.key)
+			if err := setString(f.value, fileScalar(raw)); err != nil {
+				return fmt.Errorf("%s: %s: %v", path, f.key, err)
+			}
+		}
+		if raw, ok := values[f.key+"File"]; ok && f.secret {
+			delete(values, f.key+"File")
+			if err := setFromFile(f.value, fileScalar(raw)); err != nil {
+				return fmt.Errorf("%s: %sFile: %v", path, f.key, err)
+			}
+		}
+	}
+
+	if len(values) > 0 {
+		unknown := make([]string, 0, len(values))
+		for k := range values {
+			unknown = append(unknown, k)
+		}
+		sort.Strings(unknown)
+		return fmt.Errorf("%s: unknown keys: %s", path, strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// flatten turns nested sections into dotted keys. Maps under rateLimit.routes
+// stay whole since their keys are route patterns, not config keys.
+func flatten(tree map[string]any, prefix string, out map[string]any) {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok && key != "rateLimit.routes" {
+			flatten(m, key, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// fileScalar brings file values to the same textual form used by env vars.
+func fileScalar(v any) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []any:
+		parts := make([]string, 0, len(t))
+		for _, item := range t {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(t))
+		for _, k := range keys {
+			parts = append(parts, k+"="+fmt.Sprint(t[k]))
+		}
+		return strings.Join(parts, ";")
+	}
+	return fmt.Sprint(v)
+}
+
+func toNode(v reflect.Value, secret bool) *yaml.Node {
+	if secret {
+		if v.IsZero() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Value: "", Style: yaml.DoubleQuotedStyle}
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: redacted}
+	}
+
+	// route limits read back as a mapping, the same shape the file accepts
+	if v.Kind() == reflect.Map {
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range keys {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: k.String()},
+				toNode(v.MapIndex(k), false))
+		}
+		return node
+	}
+
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, _ := m.MarshalText()
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: string(text)}
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: v.Interface().(time.Duration).String()}
+	case v.Kind() == reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := sf.Tag.Get("cfg")
+			if key == "" {
+				continue
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+				toNode(v.Field(i), sf.Tag.Get("secret") == "true"))
+		}
+		return node
+	case v.Kind() == reflect.Slice:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := 0; i < v.Len(); i++ {
+			node.Content = append(node.Content, toNode(v.Index(i), false))
+		}
+		return node
+	}
+
+	node := &yaml.Node{}
+	_ = node.Encode(v.Interface())
+	return node
+}
+
+func wrapErr(source string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %v", source, err)
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("aplica defaults", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, ":8080", cfg.Server.Addr)
+		require.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
+		require.Equal(t, 3306, cfg.Database.Port)
+		require.Equal(t, 600, cfg.RateLimit.Default.Requests)
+		require.Equal(t, 1200, cfg.RateLimit.PerIP.Requests)
+		require.Equal(t, []string{"http://localhost:3000"}, cfg.CORS.AllowOrigins)
+		require.Contains(t, cfg.CORS.AllowHeaders, "X-Tenant-ID")
+		require.Subset(t, cfg.CORS.ExposeHeaders, []string{"ETag", "Link", "X-Total-Count"})
+	})
+
+	t.Run("libera no cors o header de tenant configurado", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+		t.Setenv("TENANT_HEADER", "X-Store")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Contains(t, cfg.CORS.AllowHeaders, "X-Store")
+		require.NotContains(t, cfg.CORS.AllowHeaders, "X-Tenant-ID")
+
+		t.Setenv("CORS_ALLOW_HEADERS", "Content-Type,x-store")
+		cfg, err = Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Content-Type", "x-store"}, cfg.CORS.AllowHeaders)
+	})
+
+	t.Run("flag vence env, que vence o arquivo", func(t *testing.T) {
+		file := writeFile(t, "config.yaml", `
+server:
+  addr: ":7000"
+  readTimeout: 20s
+database:
+  host: db.interno
+auth:
+  hs256Secret: segredo
+rateLimit:
+  routes:
+    GET /v1/products: 10/s
+`)
+		t.Setenv("DB_HOST", "db.env")
+		t.Setenv("SERVER_ADDR", ":7001")
+
+		cfg, err := Load([]string{"--config", file, "--server.addr", ":7002"})
+		require.NoError(t, err)
+		require.Equal(t, ":7002", cfg.Server.Addr)
+		require.Equal(t, 20*time.Second, cfg.Server.ReadTimeout)
+		require.Equal(t, "db.env", cfg.Database.Host)
+		require.Equal(t, 10, cfg.RateLimit.Routes["GET /v1/products"].Requests)
+	})
+
+	t.Run("lê arquivo toml", func(t *testing.T) {
+		file := writeFile(t, "config.toml", `
+[auth]
+enabled = false
+
+[database]
+port = 3307
+`)
+		cfg, err := Load([]string{"--config", file})
+		require.NoError(t, err)
+		require.Equal(t, 3307, cfg.Database.Port)
+		require.False(t, cfg.Auth.Enabled)
+	})
+
+	t.Run("lê segredos de arquivos", func(t *testing.T) {
+		secret := writeFile(t, "jwt", "do-arquivo\n")
+		password := writeFile(t, "db", "senha-do-arquivo")
+		t.Setenv("JWT_HS256_SECRET_FILE", secret)
+
+		cfg, err := Load([]string{"--database.password-file", password})
+		require.NoError(t, err)
+		require.Equal(t, "do-arquivo", cfg.Auth.HS256Secret)
+		require.Equal(t, "senha-do-arquivo", cfg.Database.Password)
+	})
+
+	t.Run("reporta todos os erros de validação", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+		t.Setenv("RATE_LIMIT_STORE", "memcached")
+
+		_, err := Load([]string{"--server.read-timeout", "0s", "--tracing.sample-ratio", "2"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "server.readTimeout (SERVER_READ_TIMEOUT) must be positive")
+		require.Contains(t, err.Error(), "rateLimit.store (RATE_LIMIT_STORE)")
+		require.Contains(t, err.Error(), "tracing.sampleRatio")
+	})
+
+	t.Run("lê o listener das métricas", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Empty(t, cfg.Metrics.Addr, "sem listener à parte o /metrics fica na porta da API")
+
+		t.Setenv("METRICS_ADDR", "9090")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "metrics.addr (METRICS_ADDR)")
+
+		t.Setenv("METRICS_ADDR", ":9090")
+		cfg, err = Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, ":9090", cfg.Metrics.Addr)
+	})
+
+	t.Run("recusa políticas de CORS inseguras", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		for origins, want := range map[string]string{
+			"*":                                 "cors.allowCredentials (CORS_ALLOW_CREDENTIALS) is true",
+			"*,https://app.example.com":         "cannot be combined with other origins",
+			"https://*.com":                     "at least two labels",
+			"https://app.*.example.com":         "leftmost label",
+			"app.example.com":                   "scheme must be http or https",
+			"https://app.example.com/dashboard": "without path",
+		} {
+			t.Setenv("CORS_ALLOW_ORIGINS", origins)
+			_, err := Load(nil)
+			require.ErrorContains(t, err, want, origins)
+		}
+
+		t.Setenv("CORS_ALLOW_ORIGINS", "*")
+		cfg, err := Load([]string{"--cors.allow-credentials=false"})
+		require.NoError(t, err)
+		require.True(t, cfg.CORS.AllowsAnyOrigin())
+
+		t.Setenv("CORS_ALLOW_ORIGINS", "https://app.example.com,https://*.staging.example.com")
+		cfg, err = Load(nil)
+		require.NoError(t, err)
+		require.True(t, cfg.CORS.AllowsOrigin("https://APP.example.com"))
+		require.True(t, cfg.CORS.AllowsOrigin("https://pr-7.staging.example.com"))
+		require.False(t, cfg.CORS.AllowsOrigin("https://staging.example.com"))
+	})
+
+	t.Run("lê facetas e faixas de preço", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+		t.Setenv("SEARCH_PRICE_BUCKETS", "1000, 2500")
+
+		cfg, err := Load([]string{"--search.facets=brand,price"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"brand", "price"}, cfg.Search.Facets)
+		require.Equal(t, []int64{1000, 2500}, cfg.Search.PriceBuckets)
+
+		t.Setenv("SEARCH_PRICE_BUCKETS", "2500,1000")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "ascending order")
+
+		t.Setenv("SEARCH_PRICE_BUCKETS", "")
+		t.Setenv("SEARCH_REFRESH_INTERVAL", "-1s")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "search.refreshInterval (SEARCH_REFRESH_INTERVAL) is -1s")
+	})
+
+	t.Run("lê storage e miniaturas de mídia", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, []int{160, 480, 1024}, cfg.Media.ThumbnailWidths)
+		require.Equal(t, int64(10<<20), cfg.Media.MaxUploadBytes())
+
+		t.Setenv("MEDIA_THUMBNAIL_WIDTHS", "200, 8000")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "has width 8000")
+
+		t.Setenv("MEDIA_THUMBNAIL_WIDTHS", "")
+		t.Setenv("MEDIA_STORAGE", "s3")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "required for s3 storage")
+
+		t.Setenv("MEDIA_S3_ENDPOINT", "minio:9000")
+		t.Setenv("MEDIA_S3_BUCKET", "produtos")
+		cfg, err = Load(nil)
+		require.NoError(t, err)
+		require.True(t, cfg.Media.S3.UseSSL)
+	})
+
+	t.Run("lê notificadores de estoque baixo", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"log"}, cfg.Alerts.Notifiers)
+
+		t.Setenv("ALERT_NOTIFIERS", "log, webhook, smtp, pombo")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, `unknown notifier "pombo"`)
+		require.ErrorContains(t, err, "expected an http(s) URL for the webhook notifier")
+		require.ErrorContains(t, err, "required for the smtp notifier")
+
+		t.Setenv("ALERT_NOTIFIERS", "webhook, smtp")
+		t.Setenv("ALERT_WEBHOOK_URL", "https://hooks.example.com/estoque")
+		t.Setenv("ALERT_SMTP_TO", "compras@example.com")
+		cfg, err = Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"webhook", "smtp"}, cfg.Alerts.Notifiers)
+		require.Equal(t, 10*time.Second, cfg.Alerts.SMTPTimeout)
+	})
+
+	t.Run("confere o backoff dos webhooks", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, 8, cfg.Webhooks.MaxAttempts)
+		require.Equal(t, 30*time.Second, cfg.Webhooks.InitialBackoff)
+
+		t.Setenv("WEBHOOK_INITIAL_BACKOFF", "2h")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "no longer than webhooks.maxBackoff")
+	})
+
+	t.Run("escolhe o broker do outbox", func(t *testing.T) {
+		t.Setenv("JWT_HS256_SECRET", "segredo")
+
+		cfg, err := Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, "memory", cfg.Outbox.Broker)
+		require.Equal(t, 20, cfg.Outbox.MaxAttempts)
+
+		t.Setenv("OUTBOX_BROKER", "kafka-rest")
+		_, err = Load(nil)
+		require.ErrorContains(t, err, "expected an http(s) URL for the kafka-rest broker")
+
+		t.Setenv("OUTBOX_KAFKA_REST_URL", "http://redpanda:8082")
+		cfg, err = Load(nil)
+		require.NoError(t, err)
+		require.Equal(t, "kafka-rest", cfg.Outbox.Broker)
+	})
+
+	t.Run("aponta valor inválido e chave desconhecida", func(t *testing.T) {
+		t.Setenv("SERVER_IDLE_TIMEOUT", "muito")
+		_, err := Load(nil)
+		require.ErrorContains(t, err, "SERVER_IDLE_TIMEOUT")
+
+		t.Setenv("SERVER_IDLE_TIMEOUT", "")
+		file := writeFile(t, "config.yaml", "server:\n  adress: \":80\"\n")
+		_, err = Load([]string{"--config", file})
+		require.ErrorContains(t, err, "unknown keys: server.adress")
+	})
+}
+
+func TestPrint(t *testing.T) {
+	t.Setenv("JWT_HS256_SECRET", "nao-pode-aparecer")
+
+	cfg, err := Load(nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, cfg.Print(&buf))
+
+	out := buf.String()
+	require.NotContains(t, out, "nao-pode-aparecer")
+	require.Contains(t, out, "hs256Secret: "+redacted)
+	require.Contains(t, out, "readTimeout: 15s")
+	require.Contains(t, out, "default: 600/m")
+	require.Contains(t, out, "level: INFO")
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+type LogConfig struct {
+	// json ou text
+	Format string     `cfg:"format" env:"LOG_FORMAT" default:"json"`
+	Level  slog.Level `cfg:"level" env:"LOG_LEVEL" default:"info"`
+}
+
+func (c LogConfig) validate() error {
+	if c.Format != "json" && c.Format != "text" {
+		return fmt.Errorf("log.format (LOG_FORMAT) is %q, expected json or text", c.Format)
+	}
+	return nil
+}
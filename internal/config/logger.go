@@ -1,55 +1,146 @@
 package config
 
 import (
+	"context"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Logger is a thin wrapper over slog that keeps the printf style API used
+// across the code base. Every logger carries a component attribute and,
+// when bound to a request context, the request fields.
 type Logger struct {
-	debug   *log.Logger
-	info    *log.Logger
-	warning *log.Logger
-	err     *log.Logger
-	writer  io.Writer
+	l   *slog.Logger
+	ctx context.Context
 }
 
+// logLevel is shared by every handler so the level can be changed at runtime.
+var logLevel = new(slog.LevelVar)
+
 func NewLogger(p string) *Logger {
-	writer := io.Writer(os.Stdout)
-	logger := log.New(writer, p, log.Ldate|log.Ltime)
-
-	return &Logger{
-		debug:   log.New(writer, "DEBUG: ", logger.Flags()),
-		info:    log.New(writer, "INFO: ", logger.Flags()),
-		warning: log.New(writer, "WARNING: ", logger.Flags()),
-		err:     log.New(writer, "ERROR: ", logger.Flags()),
-		writer:  writer,
+	return &Logger{l: slog.Default().With("component", p), ctx: context.Background()}
+}
+
+// InitLogging installs the process wide slog handler. It must run before
+// any logger is created.
+func InitLogging(cfg LogConfig) error {
+	return configureLogging(os.Stdout, cfg.Format, cfg.Level)
+}
+
+func configureLogging(w io.Writer, format string, level slog.Level) error {
+	logLevel.Set(level)
+
+	opts := &slog.HandlerOptions{Level: logLevel}
+
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case "json":
+		h = slog.NewJSONHandler(w, opts)
+	case "text":
+		h = slog.NewTextHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q: expected json or text", format)
 	}
+
+	slog.SetDefault(slog.New(contextHandler{h}))
+	return nil
+}
+
+// With returns a logger that adds args (key/value pairs) to every record.
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{l: l.l.With(args...), ctx: l.ctx}
+}
+
+// WithContext binds the logger to ctx so records carry the request fields
+// and trace id stored in it.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	return &Logger{l: l.l, ctx: ctx}
+}
+
+func (l *Logger) log(level slog.Level, msg string) {
+	l.l.Log(l.ctx, level, msg)
 }
 
 // Create Non-Formatted Logs
 func (l *Logger) Debug(v ...interface{}) {
-	l.debug.Println(v...)
+	l.log(slog.LevelDebug, fmt.Sprint(v...))
 }
 func (l *Logger) Info(v ...interface{}) {
-	l.info.Println(v...)
+	l.log(slog.LevelInfo, fmt.Sprint(v...))
 }
 func (l *Logger) Warn(v ...interface{}) {
-	l.warning.Println(v...)
+	l.log(slog.LevelWarn, fmt.Sprint(v...))
 }
 func (l *Logger) Error(v ...interface{}) {
-	l.err.Println(v...)
+	l.log(slog.LevelError, fmt.Sprint(v...))
 }
 
 func (l *Logger) Debugf(format string, v ...interface{}) {
-	l.debug.Printf(format, v...)
+	l.log(slog.LevelDebug, fmt.Sprintf(format, v...))
 }
 func (l *Logger) Infof(format string, v ...interface{}) {
-	l.info.Printf(format, v...)
+	l.log(slog.LevelInfo, fmt.Sprintf(format, v...))
 }
 func (l *Logger) Warnf(format string, v ...interface{}) {
-	l.warning.Printf(format, v...)
+	l.log(slog.LevelWarn, fmt.Sprintf(format, v...))
 }
 func (l *Logger) Errorf(format string, v ...interface{}) {
-	l.err.Printf(format, v...)
+	l.log(slog.LevelError, fmt.Sprintf(format, v...))
+}
+
+// LogAttrs logs a record with structured attributes, for callers that have
+// more than a message to say.
+func (l *Logger) LogAttrs(level slog.Level, msg string, attrs ...slog.Attr) {
+	l.l.LogAttrs(l.ctx, level, msg, attrs...)
+}
+
+type logFieldsKey struct{}
+
+// logFields holds the request fields collected by the middleware chain.
+// Middleware run one after the other on the request goroutine, so no
+// locking is needed.
+type logFields struct {
+	attrs []slog.Attr
+}
+
+// WithLogFields prepares ctx to collect request fields through AddLogFields.
+func WithLogFields(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return context.WithValue(ctx, logFieldsKey{}, &logFields{attrs: attrs})
+}
+
+// AddLogFields adds fields to every record logged with ctx from now on. It is
+// a no-op when ctx was not prepared by WithLogFields.
+func AddLogFields(ctx context.Context, attrs ...slog.Attr) {
+	if f, ok := ctx.Value(logFieldsKey{}).(*logFields); ok {
+		f.attrs = append(f.attrs, attrs...)
+	}
+}
+
+// contextHandler adds the request fields and the trace id found in the
+// record context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if f, ok := ctx.Value(logFieldsKey{}).(*logFields); ok {
+		r.AddAttrs(f.attrs...)
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
 }
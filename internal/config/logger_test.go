@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func captureLogs(t *testing.T, level slog.Level) *bytes.Buffer {
+	t.Helper()
+	orig := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(orig) })
+
+	var buf bytes.Buffer
+	require.NoError(t, configureLogging(&buf, "json", level))
+	return &buf
+}
+
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var out []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &rec))
+		out = append(out, rec)
+	}
+	return out
+}
+
+func TestLogger(t *testing.T) {
+	t.Run("grava json com componente e campos da requisição", func(t *testing.T) {
+		buf := captureLogs(t, slog.LevelInfo)
+
+		ctx := WithLogFields(context.Background(), slog.String("request_id", "abc"))
+		AddLogFields(ctx, slog.String("user", "maria"))
+		NewLogger("handler").WithContext(ctx).Errorf("error updating product: %v", errors.New("boom"))
+
+		recs := records(t, buf)
+		require.Len(t, recs, 1)
+		require.Equal(t, "ERROR", recs[0]["level"])
+		require.Equal(t, "handler", recs[0]["component"])
+		require.Equal(t, "error updating product: boom", recs[0]["msg"])
+		require.Equal(t, "abc", recs[0]["request_id"])
+		require.Equal(t, "maria", recs[0]["user"])
+	})
+
+	t.Run("filtra abaixo do nível mínimo", func(t *testing.T) {
+		buf := captureLogs(t, slog.LevelWarn)
+
+		logger := NewLogger("test")
+		logger.Infof("ignorado")
+		logger.Warnf("gravado")
+
+		recs := records(t, buf)
+		require.Len(t, recs, 1)
+		require.Equal(t, "gravado", recs[0]["msg"])
+	})
+
+	t.Run("rejeita formato inválido", func(t *testing.T) {
+		require.Error(t, configureLogging(&bytes.Buffer{}, "xml", slog.LevelInfo))
+	})
+}
+
+func TestGormLogger(t *testing.T) {
+	query := func() (string, int64) { return "SELECT * FROM `products`", 3 }
+
+	t.Run("avisa sobre query lenta", func(t *testing.T) {
+		buf := captureLogs(t, slog.LevelInfo)
+
+		NewGormLogger(100*time.Millisecond).Trace(context.Background(), time.Now().Add(-time.Second), query, nil)
+
+		recs := records(t, buf)
+		require.Len(t, recs, 1)
+		require.Equal(t, "WARN", recs[0]["level"])
+		require.Equal(t, "SELECT * FROM `products`", recs[0]["sql"])
+	})
+
+	t.Run("só loga queries rápidas em debug", func(t *testing.T) {
+		buf := captureLogs(t, slog.LevelInfo)
+		NewGormLogger(time.Second).Trace(context.Background(), time.Now(), query, nil)
+		require.Empty(t, records(t, buf))
+
+		buf = captureLogs(t, slog.LevelDebug)
+		NewGormLogger(time.Second).Trace(context.Background(), time.Now(), query, nil)
+		require.Len(t, records(t, buf), 1)
+	})
+
+	t.Run("registra erro da query", func(t *testing.T) {
+		buf := captureLogs(t, slog.LevelInfo)
+
+		NewGormLogger(time.Second).Trace(context.Background(), time.Now(), query, errors.New("deadlock"))
+
+		recs := records(t, buf)
+		require.Len(t, recs, 1)
+		require.Equal(t, "ERROR", recs[0]["level"])
+		require.Equal(t, "deadlock", recs[0]["error"])
+	})
+	t.Run("não loga os valores da query", func(t *testing.T) {
+		buf := captureLogs(t, slog.LevelDebug)
+
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+		gdb, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			&gorm.Config{Logger: NewGormLogger(time.Second)})
+		require.NoError(t, err)
+
+		mock.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		var ids []int
+		require.NoError(t, gdb.Table("webhook_subscriptions").Where("secret = ?", "whsec_segredo").Pluck("id", &ids).Error)
+
+		recs := records(t, buf)
+		require.Len(t, recs, 1)
+		require.Equal(t, "SELECT `id` FROM `webhook_subscriptions` WHERE secret = ?", recs[0]["sql"])
+		require.NotContains(t, buf.String(), "whsec_segredo")
+	})
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+)
+
+type MediaConfig struct {
+	// local (diretório servido pela própria API, com autenticação) ou s3
+	Storage string `cfg:"storage" env:"MEDIA_STORAGE" default:"local"`
+	// diretório dos arquivos no storage local
+	Dir string `cfg:"dir" env:"MEDIA_DIR" default:"./data/media"`
+	// caminho em que a API serve o storage local
+	BaseURL string `cfg:"baseURL" env:"MEDIA_BASE_URL" default:"/media"`
+	// tamanho máximo de cada upload, em MB
+	MaxUploadMB int `cfg:"maxUploadMB" env:"MEDIA_MAX_UPLOAD_MB" default:"10"`
+	// larguras das miniaturas geradas, em pixels
+	ThumbnailWidths []int `cfg:"thumbnailWidths" env:"MEDIA_THUMBNAIL_WIDTHS" default:"160,480,1024"`
+	// quantas imagens cada produto pode ter
+	MaxPerProduct int `cfg:"maxPerProduct" env:"MEDIA_MAX_PER_PRODUCT" default:"20"`
+	// uploads decodificados ao mesmo tempo; os demais esperam a vez
+	MaxConcurrent int `cfg:"maxConcurrent" env:"MEDIA_MAX_CONCURRENT" default:"4"`
+
+	S3 MediaS3Config `cfg:"s3"`
+}
+
+// MediaS3Config aponta para um bucket compatível com S3 (AWS, MinIO, R2...).
+type MediaS3Config struct {
+	Endpoint  string `cfg:"endpoint" env:"MEDIA_S3_ENDPOINT"`
+	Bucket    string `cfg:"bucket" env:"MEDIA_S3_BUCKET"`
+	Region    string `cfg:"region" env:"MEDIA_S3_REGION"`
+	AccessKey string `cfg:"accessKey" env:"MEDIA_S3_ACCESS_KEY"`
+	SecretKey string `cfg:"secretKey" env:"MEDIA_S3_SECRET_KEY" secret:"true"`
+	UseSSL    bool   `cfg:"useSSL" env:"MEDIA_S3_USE_SSL" default:"true"`
+	// de onde os clientes leem os arquivos, por exemplo uma CDN; vazio usa
+	// o próprio endpoint
+	PublicURL string `cfg:"publicURL" env:"MEDIA_S3_PUBLIC_URL"`
+}
+
+func (c MediaConfig) validate() error {
+	var errs []error
+	switch c.Storage {
+	case "local":
+		if c.Dir == "" {
+			errs = append(errs, errors.New("media.dir (MEDIA_DIR) is required for local storage"))
+		}
+		if len(c.BaseURL) < 2 || c.BaseURL[0] != '/' {
+			errs = append(errs, fmt.Errorf("media.baseURL (MEDIA_BASE_URL) is %q, expected a path such as /media", c.BaseURL))
+		}
+	case "s3":
+		if c.S3.Endpoint == "" || c.S3.Bucket == "" {
+			errs = append(errs, errors.New("media.s3.endpoint (MEDIA_S3_ENDPOINT) and media.s3.bucket (MEDIA_S3_BUCKET) are required for s3 storage"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("media.storage (MEDIA_STORAGE) is %q, expected local or s3", c.Storage))
+	}
+	if c.MaxUploadMB < 1 {
+		errs = append(errs, errors.New("media.maxUploadMB (MEDIA_MAX_UPLOAD_MB) must be positive"))
+	}
+	if c.MaxPerProduct < 1 {
+		errs = append(errs, errors.New("media.maxPerProduct (MEDIA_MAX_PER_PRODUCT) must be positive"))
+	}
+	if c.MaxConcurrent < 1 {
+		errs = append(errs, errors.New("media.maxConcurrent (MEDIA_MAX_CONCURRENT) must be positive"))
+	}
+	if len(c.ThumbnailWidths) == 0 {
+		errs = append(errs, errors.New("media.thumbnailWidths (MEDIA_THUMBNAIL_WIDTHS) needs at least one width"))
+	}
+	for _, w := range c.ThumbnailWidths {
+		if w < 16 || w > 4096 {
+			errs = append(errs, fmt.Errorf("media.thumbnailWidths (MEDIA_THUMBNAIL_WIDTHS) has width %d, expected 16-4096", w))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// MaxUploadBytes é o limite de MaxUploadMB em bytes.
+func (c MediaConfig) MaxUploadBytes() int64 {
+	return int64(c.MaxUploadMB) << 20
+}
+
+// NewStorage cria o storage escolhido em Storage.
+func (c MediaConfig) NewStorage() (media.Storage, error) {
+	if c.Storage == "s3" {
+		return media.NewS3Storage(media.S3Options{
+			Endpoint:  c.S3.Endpoint,
+			Bucket:    c.S3.Bucket,
+			Region:    c.S3.Region,
+			AccessKey: c.S3.AccessKey,
+			SecretKey: c.S3.SecretKey,
+			UseSSL:    c.S3.UseSSL,
+			PublicURL: c.S3.PublicURL,
+		})
+	}
+	return media.NewLocalStorage(c.Dir, c.BaseURL)
+}
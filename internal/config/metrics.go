@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fmt"
+	"net"
+)
+
+type MetricsConfig struct {
+	// endereço de um listener só para o /metrics, fora da porta da API
+	// (ex.: :9090); vazio serve o /metrics na porta da API, com autenticação
+	Addr string `cfg:"addr" env:"METRICS_ADDR"`
+}
+
+func (c MetricsConfig) validate() error {
+	if c.Addr == "" {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return fmt.Errorf("metrics.addr (METRICS_ADDR) is %q, expected host:port such as :9090", c.Addr)
+	}
+	return nil
+}
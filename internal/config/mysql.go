@@ -1,56 +1,136 @@
 package config
 
 import (
+	"context"
 	"fmt"
-	"os"
+	"sync/atomic"
 	"time"
 
+	"github.com/alissonmunhoz/go-crud-products/internal/metrics"
 	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
-func InitializeMySQL() (*gorm.DB, error) {
-	logger := GetLogger("mysql")
+// migrated fica verdadeiro depois que as migrações rodam; usado pelo /readyz
+var migrated atomic.Bool
 
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "3306")
-	user := getEnv("DB_USER", "root")
-	pass := getEnv("DB_PASSWORD", "root")
-	name := getEnv("DB_NAME", "products")
+func Migrated() bool {
+	return migrated.Load()
+}
 
-	// DSN recomendado pelo GORM: charset utf8mb4 + parseTime + loc
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=true&loc=Local",
-		user, pass, host, port, name,
-	)
+func InitializeMySQL(ctx context.Context, cfg DatabaseConfig) (*gorm.DB, error) {
+	logger := GetLogger("mysql")
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	db, err := openWithRetry(ctx, cfg)
 	if err != nil {
 		logger.Errorf("mysql connection error: %v", err)
 		return nil, err
 	}
 
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		return nil, err
+	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	// os valores das queries ficam de fora dos spans: podem conter dados pessoais
+	if err := db.Use(gormtracing.NewPlugin(
+		gormtracing.WithDBSystem("mysql"),
+		gormtracing.WithoutQueryVariables(),
+		gormtracing.WithoutMetrics(),
+	)); err != nil {
+		return nil, err
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(50)
-	sqlDB.SetConnMaxLifetime(60 * time.Minute)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	// Migrações
-	if err := db.AutoMigrate(&schemas.Product{}); err != nil {
+	if err := db.AutoMigrate(
+		&schemas.Product{},
+		&schemas.ProductRevision{},
+		&schemas.AttributeDefinition{},
+		&schemas.AttributeKey{},
+		&schemas.ProductMedia{},
+		&schemas.Brand{},
+		&schemas.Supplier{},
+		&schemas.ProductSupplier{},
+		&schemas.PurchaseOrder{},
+		&schemas.PurchaseOrderLine{},
+		&schemas.StockMovement{},
+		&schemas.WebhookSubscription{},
+		&schemas.WebhookDelivery{},
+		&schemas.OutboxEvent{},
+		&schemas.AuditEntry{},
+		&schemas.AuditChainHead{},
+		&schemas.APIKey{},
+		&schemas.IdempotencyKey{},
+	); err != nil {
 		logger.Errorf("mysql automigration error: %v", err)
 		return nil, err
 	}
 
+	if err := migrateAuditTriggers(db); err != nil {
+		logger.Errorf("mysql audit trigger migration error: %v", err)
+		return nil, err
+	}
+
+	migrated.Store(true)
+
 	return db, nil
 }
 
-// helper para env com default
-func getEnv(key, def string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
+// openWithRetry espera o MySQL subir (comum no docker-compose e no Kubernetes)
+// em vez de encerrar o processo na primeira falha de conexão.
+func openWithRetry(ctx context.Context, cfg DatabaseConfig) (*gorm.DB, error) {
+	logger := GetLogger("mysql")
+
+	deadline := time.Now().Add(cfg.StartupTimeout)
+	backoff := time.Second
+
+	gormCfg := &gorm.Config{TranslateError: true, Logger: NewGormLogger(cfg.SlowQueryThreshold)}
+
+	for attempt := 1; ; attempt++ {
+		db, err := gorm.Open(mysql.Open(cfg.DSN()), gormCfg)
+		if err == nil {
+			return db, nil
+		}
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("mysql not reachable after %d attempts: %v", attempt, err)
+		}
+
+		logger.Warnf("mysql not reachable (attempt %d), retrying in %s: %v", attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 10*time.Second)
+	}
+}
+
+// o audit log é append-only: o próprio banco recusa UPDATE e DELETE
+func migrateAuditTriggers(db *gorm.DB) error {
+	statements := []string{
+		"DROP TRIGGER IF EXISTS audit_entries_no_update",
+		"CREATE TRIGGER audit_entries_no_update BEFORE UPDATE ON audit_entries FOR EACH ROW " +
+			"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_entries is append-only'",
+		"DROP TRIGGER IF EXISTS audit_entries_no_delete",
+		"CREATE TRIGGER audit_entries_no_delete BEFORE DELETE ON audit_entries FOR EACH ROW " +
+			"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_entries is append-only'",
+	}
+	for _, stmt := range statements {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
 	}
-	return def
+	return nil
 }
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type OutboxConfig struct {
+	// memory (barramento em processo) ou kafka-rest
+	Broker       string        `cfg:"broker" env:"OUTBOX_BROKER" default:"memory"`
+	PollInterval time.Duration `cfg:"pollInterval" env:"OUTBOX_POLL_INTERVAL" default:"1s"`
+	BatchSize    int           `cfg:"batchSize" env:"OUTBOX_BATCH_SIZE" default:"100"`
+	// tempo que um lote fica reservado para o relay que o pegou
+	Lease time.Duration `cfg:"lease" env:"OUTBOX_LEASE" default:"1m"`
+	// falhas antes de o evento ser posto de lado
+	MaxAttempts int `cfg:"maxAttempts" env:"OUTBOX_MAX_ATTEMPTS" default:"20"`
+	// espera após a primeira falha, dobrada a cada nova falha até MaxBackoff
+	InitialBackoff time.Duration `cfg:"initialBackoff" env:"OUTBOX_INITIAL_BACKOFF" default:"1s"`
+	MaxBackoff     time.Duration `cfg:"maxBackoff" env:"OUTBOX_MAX_BACKOFF" default:"5m"`
+	// eventos já publicados são apagados depois deste tempo
+	Retention time.Duration `cfg:"retention" env:"OUTBOX_RETENTION" default:"168h"`
+
+	// REST Proxy do Kafka (ou o HTTP Proxy do Redpanda)
+	KafkaRESTURL string        `cfg:"kafkaRestURL" env:"OUTBOX_KAFKA_REST_URL"`
+	Topic        string        `cfg:"topic" env:"OUTBOX_TOPIC" default:"products"`
+	Timeout      time.Duration `cfg:"timeout" env:"OUTBOX_TIMEOUT" default:"10s"`
+}
+
+func (c OutboxConfig) validate() error {
+	var errs []error
+	switch c.Broker {
+	case "memory":
+	case "kafka-rest":
+		if u, err := url.Parse(c.KafkaRESTURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("outbox.kafkaRestURL (OUTBOX_KAFKA_REST_URL) is %q, expected an http(s) URL for the kafka-rest broker", c.KafkaRESTURL))
+		}
+		if c.Topic == "" {
+			errs = append(errs, errors.New("outbox.topic (OUTBOX_TOPIC) is required for the kafka-rest broker"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("outbox.broker (OUTBOX_BROKER) is %q, expected memory or kafka-rest", c.Broker))
+	}
+	if c.PollInterval <= 0 {
+		errs = append(errs, errors.New("outbox.pollInterval (OUTBOX_POLL_INTERVAL) must be positive"))
+	}
+	if c.BatchSize < 1 {
+		errs = append(errs, errors.New("outbox.batchSize (OUTBOX_BATCH_SIZE) must be positive"))
+	}
+	if c.Lease <= 0 {
+		errs = append(errs, errors.New("outbox.lease (OUTBOX_LEASE) must be positive"))
+	}
+	if c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("outbox.maxAttempts (OUTBOX_MAX_ATTEMPTS) must be positive"))
+	}
+	if c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff {
+		errs = append(errs, errors.New("outbox.initialBackoff (OUTBOX_INITIAL_BACKOFF) must be positive and no longer than outbox.maxBackoff (OUTBOX_MAX_BACKOFF)"))
+	}
+	if c.Retention <= 0 {
+		errs = append(errs, errors.New("outbox.retention (OUTBOX_RETENTION) must be positive"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("outbox.timeout (OUTBOX_TIMEOUT) must be positive"))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/ratelimit"
+)
+
+type RateLimitConfig struct {
+	Enabled bool            `cfg:"enabled" env:"RATE_LIMIT_ENABLED" default:"true"`
+	Default ratelimit.Limit `cfg:"default" env:"RATE_LIMIT_DEFAULT" default:"600/m"`
+	// chave "MÉTODO /rota" no formato do Gin, ex.: "GET /v1/products"
+	Routes RouteLimits `cfg:"routes" env:"RATE_LIMIT_ROUTES" default:"GET /v1/products=120/m;POST /v1/product=30/m:10"`
+	// por IP, antes da autenticação, somando todas as rotas
+	PerIP ratelimit.Limit `cfg:"perIP" env:"RATE_LIMIT_PER_IP" default:"1200/m"`
+	// memory ou redis
+	Store         string `cfg:"store" env:"RATE_LIMIT_STORE" default:"memory"`
+	RedisAddr     string `cfg:"redisAddr" env:"RATE_LIMIT_REDIS_ADDR" default:"localhost:6379"`
+	RedisPassword string `cfg:"redisPassword" env:"RATE_LIMIT_REDIS_PASSWORD" secret:"true"`
+}
+
+func (c RateLimitConfig) validate() error {
+	if c.Store != "memory" && c.Store != "redis" {
+		return fmt.Errorf("rateLimit.store (RATE_LIMIT_STORE) is %q, expected memory or redis", c.Store)
+	}
+	return nil
+}
+
+// RouteLimits maps "METHOD /route" to its own limit. As text it is written
+// as "GET /v1/products=120/m;POST /v1/product=30/m:10".
+type RouteLimits map[string]ratelimit.Limit
+
+func (r *RouteLimits) UnmarshalText(text []byte) error {
+	routes := RouteLimits{}
+	for _, entry := range strings.Split(string(text), ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		route, spec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid route limit %q: expected \"METHOD /path=limit\"", entry)
+		}
+		limit, err := ratelimit.ParseLimit(spec)
+		if err != nil {
+			return err
+		}
+		routes[strings.Join(strings.Fields(route), " ")] = limit
+	}
+	*r = routes
+	return nil
+}
+
+func (r RouteLimits) MarshalText() ([]byte, error) {
+	keys := make([]string, 0, len(r))
+	for k := range r {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(r))
+	for _, k := range keys {
+		limit, _ := r[k].MarshalText()
+		parts = append(parts, k+"="+string(limit))
+	}
+	return []byte(strings.Join(parts, ";")), nil
+}
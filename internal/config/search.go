@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+type SearchConfig struct {
+	// mysql (índice FULLTEXT) ou memory (índice invertido em processo)
+	Backend string `cfg:"backend" env:"SEARCH_BACKEND" default:"mysql"`
+	// tamanho máximo, em caracteres, do trecho destacado da descrição
+	SnippetLength int `cfg:"snippetLength" env:"SEARCH_SNIPPET_LENGTH" default:"160"`
+	// facetas calculadas na listagem e na busca com facets=true; os nomes
+	// são conferidos por search.NewFacetSpec
+	Facets []string `cfg:"facets" env:"SEARCH_FACETS" default:"category,brand,status,price,inStock"`
+	// limites das faixas de preço, na mesma unidade de price
+	PriceBuckets []int64 `cfg:"priceBuckets" env:"SEARCH_PRICE_BUCKETS" default:"5000,10000,20000,50000"`
+	// a cada quanto as sugestões e o índice memory são remontados a partir
+	// do banco, para ver o que as outras réplicas gravaram; 0 desliga
+	RefreshInterval time.Duration `cfg:"refreshInterval" env:"SEARCH_REFRESH_INTERVAL" default:"5m"`
+}
+
+func (c SearchConfig) validate() error {
+	if c.Backend != "mysql" && c.Backend != "memory" {
+		return fmt.Errorf("search.backend (SEARCH_BACKEND) is %q, expected mysql or memory", c.Backend)
+	}
+	if c.SnippetLength < 20 {
+		return fmt.Errorf("search.snippetLength (SEARCH_SNIPPET_LENGTH) is %d, expected at least 20", c.SnippetLength)
+	}
+	if c.RefreshInterval < 0 {
+		return fmt.Errorf("search.refreshInterval (SEARCH_REFRESH_INTERVAL) is %s, expected 0 or more", c.RefreshInterval)
+	}
+	for i := 1; i < len(c.PriceBuckets); i++ {
+		if c.PriceBuckets[i] <= c.PriceBuckets[i-1] {
+			return fmt.Errorf("search.priceBuckets (SEARCH_PRICE_BUCKETS) must be in ascending order")
+		}
+	}
+	return nil
+}
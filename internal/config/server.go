@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ServerConfig struct {
+	Addr              string        `cfg:"addr" env:"SERVER_ADDR" default:":8080"`
+	ReadTimeout       time.Duration `cfg:"readTimeout" env:"SERVER_READ_TIMEOUT" default:"15s"`
+	ReadHeaderTimeout time.Duration `cfg:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT" default:"5s"`
+	WriteTimeout      time.Duration `cfg:"writeTimeout" env:"SERVER_WRITE_TIMEOUT" default:"30s"`
+	IdleTimeout       time.Duration `cfg:"idleTimeout" env:"SERVER_IDLE_TIMEOUT" default:"60s"`
+	// tempo máximo para drenar as requisições em andamento no shutdown
+	ShutdownTimeout time.Duration `cfg:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
+	// espera entre o /readyz passar a falhar e o servidor parar de aceitar
+	// conexões, para o balanceador tirar a instância de rotação
+	ShutdownDelay  time.Duration `cfg:"shutdownDelay" env:"SERVER_SHUTDOWN_DELAY" default:"0s"`
+	MaxHeaderBytes int           `cfg:"maxHeaderBytes" env:"SERVER_MAX_HEADER_BYTES" default:"1048576"`
+	// proxies cujo X-Forwarded-For é aceito como IP do cliente; vazio não confia em nenhum
+	TrustedProxies []string `cfg:"trustedProxies" env:"SERVER_TRUSTED_PROXIES"`
+	// TLS é ligado quando certificado e chave são informados
+	TLSCertFile string `cfg:"tlsCertFile" env:"TLS_CERT_FILE"`
+	TLSKeyFile  string `cfg:"tlsKeyFile" env:"TLS_KEY_FILE"`
+	// com um CA de clientes o servidor exige certificado do cliente (mTLS)
+	TLSClientCAFile string `cfg:"tlsClientCAFile" env:"TLS_CLIENT_CA_FILE"`
+}
+
+func (c ServerConfig) TLSEnabled() bool {
+	return c.TLSCertFile != ""
+}
+
+func (c ServerConfig) validate() error {
+	var errs []error
+
+	positive := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"server.readTimeout (SERVER_READ_TIMEOUT)", c.ReadTimeout},
+		{"server.readHeaderTimeout (SERVER_READ_HEADER_TIMEOUT)", c.ReadHeaderTimeout},
+		{"server.writeTimeout (SERVER_WRITE_TIMEOUT)", c.WriteTimeout},
+		{"server.idleTimeout (SERVER_IDLE_TIMEOUT)", c.IdleTimeout},
+		{"server.shutdownTimeout (SERVER_SHUTDOWN_TIMEOUT)", c.ShutdownTimeout},
+	}
+	for _, p := range positive {
+		if p.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive", p.key))
+		}
+	}
+
+	if c.ShutdownDelay < 0 {
+		errs = append(errs, errors.New("server.shutdownDelay (SERVER_SHUTDOWN_DELAY) must not be negative"))
+	}
+	if c.MaxHeaderBytes <= 0 {
+		errs = append(errs, errors.New("server.maxHeaderBytes (SERVER_MAX_HEADER_BYTES) must be positive"))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("server.tlsCertFile (TLS_CERT_FILE) and server.tlsKeyFile (TLS_KEY_FILE) must be set together"))
+	}
+	if c.TLSClientCAFile != "" && !c.TLSEnabled() {
+		errs = append(errs, errors.New("server.tlsClientCAFile (TLS_CLIENT_CA_FILE) requires server.tlsCertFile and server.tlsKeyFile"))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,18 @@
+package config
+
+import "errors"
+
+type TenancyConfig struct {
+	Enabled bool `cfg:"enabled" env:"MULTI_TENANCY_ENABLED" default:"false"`
+	// header aceito quando o token não traz o claim de tenant
+	Header string `cfg:"header" env:"TENANT_HEADER" default:"X-Tenant-ID"`
+	// domínio base para extrair o tenant do subdomínio (ex.: loja1.api.exemplo.com)
+	BaseDomain string `cfg:"baseDomain" env:"TENANT_BASE_DOMAIN"`
+}
+
+func (c TenancyConfig) validate() error {
+	if c.Enabled && c.Header == "" && c.BaseDomain == "" {
+		return errors.New("tenancy.enabled is true but neither tenancy.header (TENANT_HEADER) nor tenancy.baseDomain (TENANT_BASE_DOMAIN) is set")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import "fmt"
+
+type TracingConfig struct {
+	// none, otlp, stdout ou file
+	Exporter    string `cfg:"exporter" env:"TRACING_EXPORTER" default:"none"`
+	ServiceName string `cfg:"serviceName" env:"OTEL_SERVICE_NAME" default:"go-crud-products"`
+	// arquivo usado pelo exporter file
+	File string `cfg:"file" env:"TRACING_FILE" default:"traces.json"`
+	// fração das traces iniciadas aqui que são gravadas (0 a 1); traces
+	// vindas de outro serviço seguem a decisão do chamador
+	SampleRatio float64 `cfg:"sampleRatio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+func (c TracingConfig) validate() error {
+	switch c.Exporter {
+	case "none", "otlp", "stdout", "file":
+	default:
+		return fmt.Errorf("tracing.exporter (TRACING_EXPORTER) is %q, expected none, otlp, stdout or file", c.Exporter)
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sampleRatio (TRACING_SAMPLE_RATIO) is %v, expected a number between 0 and 1", c.SampleRatio)
+	}
+	return nil
+}
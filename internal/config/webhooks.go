@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+type WebhooksConfig struct {
+	// intervalo entre as buscas por entregas pendentes
+	PollInterval time.Duration `cfg:"pollInterval" env:"WEBHOOK_POLL_INTERVAL" default:"2s"`
+	BatchSize    int           `cfg:"batchSize" env:"WEBHOOK_BATCH_SIZE" default:"50"`
+	Timeout      time.Duration `cfg:"timeout" env:"WEBHOOK_TIMEOUT" default:"10s"`
+	// entregas do lote enviadas ao mesmo tempo
+	Concurrency int `cfg:"concurrency" env:"WEBHOOK_CONCURRENCY" default:"8"`
+	// tentativas antes de a entrega ser dada como falha
+	MaxAttempts int `cfg:"maxAttempts" env:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	// espera após a primeira falha, dobrada a cada nova falha até MaxBackoff
+	InitialBackoff time.Duration `cfg:"initialBackoff" env:"WEBHOOK_INITIAL_BACKOFF" default:"30s"`
+	MaxBackoff     time.Duration `cfg:"maxBackoff" env:"WEBHOOK_MAX_BACKOFF" default:"1h"`
+	// aceita URLs em loopback e redes privadas; só para desenvolvimento
+	AllowPrivateTargets bool `cfg:"allowPrivateTargets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS" default:"false"`
+}
+
+func (c WebhooksConfig) validate() error {
+	var errs []error
+	if c.PollInterval <= 0 {
+		errs = append(errs, errors.New("webhooks.pollInterval (WEBHOOK_POLL_INTERVAL) must be positive"))
+	}
+	if c.BatchSize < 1 {
+		errs = append(errs, errors.New("webhooks.batchSize (WEBHOOK_BATCH_SIZE) must be positive"))
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, errors.New("webhooks.timeout (WEBHOOK_TIMEOUT) must be positive"))
+	}
+	if c.Concurrency < 1 {
+		errs = append(errs, errors.New("webhooks.concurrency (WEBHOOK_CONCURRENCY) must be positive"))
+	}
+	if c.MaxAttempts < 1 {
+		errs = append(errs, errors.New("webhooks.maxAttempts (WEBHOOK_MAX_ATTEMPTS) must be positive"))
+	}
+	if c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff {
+		errs = append(errs, errors.New("webhooks.initialBackoff (WEBHOOK_INITIAL_BACKOFF) must be positive and no longer than webhooks.maxBackoff (WEBHOOK_MAX_BACKOFF)"))
+	}
+	return errors.Join(errs...)
+}
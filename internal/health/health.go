@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check reports whether a dependency is usable. It must honour ctx.
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Registry holds the readiness checks and the background workers of the
+// process. Once draining starts the service reports itself as not ready so
+// the load balancer stops sending traffic before the server shuts down.
+type Registry struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	checks  map[string]Check
+	workers map[string]bool
+	running sync.WaitGroup
+
+	draining atomic.Bool
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{
+		timeout: timeout,
+		checks:  map[string]Check{},
+		workers: map[string]bool{},
+	}
+}
+
+func (r *Registry) Register(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = check
+}
+
+// Go runs a background worker and keeps track of it. A worker that returns
+// before the process starts draining makes the service not ready.
+func (r *Registry) Go(name string, worker func()) {
+	r.mu.Lock()
+	r.workers[name] = true
+	r.mu.Unlock()
+
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+		defer func() {
+			r.mu.Lock()
+			r.workers[name] = false
+			r.mu.Unlock()
+		}()
+		worker()
+	}()
+}
+
+// Wait blocks until every worker started with Go has returned, or until
+// timeout elapses. It is called on shutdown, after the workers' context is
+// cancelled, so the database is not closed under a worker still using it.
+func (r *Registry) Wait(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		r.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var running []string
+	for name, ok := range r.workers {
+		if ok {
+			running = append(running, name)
+		}
+	}
+	sort.Strings(running)
+	return fmt.Errorf("background workers still running after %s: %s", timeout, strings.Join(running, ", "))
+}
+
+func (r *Registry) SetDraining() {
+	r.draining.Store(true)
+}
+
+func (r *Registry) Draining() bool {
+	return r.draining.Load()
+}
+
+// Ready runs every check concurrently, each bounded by the registry timeout.
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mu.Lock()
+	checks := make(map[string]Check, len(r.checks)+1)
+	for name, check := range r.checks {
+		checks[name] = check
+	}
+	if len(r.workers) > 0 {
+		checks["workers"] = r.checkWorkers
+	}
+	r.mu.Unlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, r.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(checkCtx)
+			res := CheckResult{Status: StatusOK, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				res.Status = StatusUnavailable
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			report.Checks[name] = res
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	for _, res := range report.Checks {
+		if res.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	if r.Draining() {
+		report.Status = StatusUnavailable
+		report.Checks["shutdown"] = CheckResult{Status: StatusUnavailable, Error: "server is shutting down"}
+	}
+
+	return report
+}
+
+func (r *Registry) checkWorkers(context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stopped []string
+	for name, running := range r.workers {
+		if !running {
+			stopped = append(stopped, name)
+		}
+	}
+	if len(stopped) == 0 || r.Draining() {
+		return nil
+	}
+	sort.Strings(stopped)
+	return fmt.Errorf("background workers stopped: %s", strings.Join(stopped, ", "))
+}
+
+// Liveness only tells the process is up and serving requests.
+func Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Readiness answers 503 when any dependency is unavailable or the server is
+// draining.
+func (r *Registry) Readiness(ctx *gin.Context) {
+	report := r.Ready(ctx.Request.Context())
+
+	code := http.StatusOK
+	if report.Status != StatusOK {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
+}
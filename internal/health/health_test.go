@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGinHealth(r *Registry) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.GET("/healthz", Liveness)
+	e.GET("/readyz", r.Readiness)
+	return e
+}
+
+func getReport(t *testing.T, e *gin.Engine) (int, Report) {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestHealth(t *testing.T) {
+	t.Run("liveness responde ok", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		setupGinHealth(NewRegistry(time.Second)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("readiness retorna 200 com todos os checks ok", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		r.Register("mysql", func(context.Context) error { return nil })
+
+		code, report := getReport(t, setupGinHealth(r))
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, StatusOK, report.Checks["mysql"].Status)
+	})
+
+	t.Run("readiness retorna 503 com detalhe do check que falhou", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		r.Register("mysql", func(context.Context) error { return errors.New("connection refused") })
+		r.Register("migrations", func(context.Context) error { return nil })
+
+		code, report := getReport(t, setupGinHealth(r))
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Equal(t, "connection refused", report.Checks["mysql"].Error)
+		require.Equal(t, StatusOK, report.Checks["migrations"].Status)
+	})
+
+	t.Run("check lento respeita o timeout", func(t *testing.T) {
+		r := NewRegistry(10 * time.Millisecond)
+		r.Register("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+		code, _ := getReport(t, setupGinHealth(r))
+		require.Equal(t, http.StatusServiceUnavailable, code)
+	})
+
+	t.Run("worker parado deixa o serviço indisponível", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		done := make(chan struct{})
+		r.Go("cleanup", func() { close(done) })
+		<-done
+		require.Eventually(t, func() bool {
+			_, report := getReport(t, setupGinHealth(r))
+			return report.Checks["workers"].Status == StatusUnavailable
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("readiness falha durante o shutdown", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		r.SetDraining()
+
+		code, report := getReport(t, setupGinHealth(r))
+		require.Equal(t, http.StatusServiceUnavailable, code)
+		require.Equal(t, StatusUnavailable, report.Checks["shutdown"].Status)
+	})
+
+	t.Run("wait espera os workers terminarem", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		stop := make(chan struct{})
+		finished := false
+		r.Go("relay", func() {
+			<-stop
+			time.Sleep(10 * time.Millisecond)
+			finished = true
+		})
+		close(stop)
+
+		require.NoError(t, r.Wait(time.Second))
+		require.True(t, finished)
+	})
+
+	t.Run("wait desiste depois do timeout e diz quem ficou rodando", func(t *testing.T) {
+		r := NewRegistry(time.Second)
+		stop := make(chan struct{})
+		defer close(stop)
+		r.Go("dispatcher", func() { <-stop })
+
+		err := r.Wait(10 * time.Millisecond)
+		require.ErrorContains(t, err, "background workers still running after 10ms: dispatcher")
+	})
+}
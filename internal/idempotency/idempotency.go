@@ -0,0 +1,143 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInProgress = errors.New("idempotency: a request with this key is still in progress")
+	ErrKeyReused  = errors.New("idempotency: key reused with a different request")
+)
+
+// Store keeps idempotency records in the database so every replica sees
+// the same keys. Records are tenant scoped through the context.
+type Store struct {
+	db    *gorm.DB
+	ttl   time.Duration
+	lease time.Duration
+	now   func() time.Time
+}
+
+// NewStore keeps answers for ttl. A request in progress holds its key for
+// lease; when it crashes without settling the key, a retry may take it
+// over once the lease has passed.
+func NewStore(db *gorm.DB, ttl, lease time.Duration) *Store {
+	return &Store{db: db, ttl: ttl, lease: lease, now: time.Now}
+}
+
+// Reserve claims keyHash for a new request. It returns nil when the caller
+// owns the key and must run the request, or the stored record when the
+// request was already answered and the response should be replayed.
+func (s *Store) Reserve(ctx context.Context, keyHash, requestHash string) (*schemas.IdempotencyKey, error) {
+	db := s.db.WithContext(ctx)
+
+	// a second attempt is only needed when an expired record was dropped
+	for attempt := 0; attempt < 2; attempt++ {
+		now := s.now()
+		lockedUntil := now.Add(s.lease)
+		record := schemas.IdempotencyKey{
+			KeyHash:     keyHash,
+			RequestHash: requestHash,
+			LockedUntil: &lockedUntil,
+			ExpiresAt:   now.Add(s.ttl),
+		}
+		err := db.Create(&record).Error
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, err
+		}
+
+		var existing schemas.IdempotencyKey
+		if err := db.Where("key_hash = ?", keyHash).First(&existing).Error; err != nil {
+			return nil, err
+		}
+
+		if existing.ExpiresAt.Before(now) {
+			if err := db.Where("id = ? AND expires_at = ?", existing.ID, existing.ExpiresAt).
+				Delete(&schemas.IdempotencyKey{}).Error; err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, ErrKeyReused
+		}
+		if existing.Status == 0 {
+			return nil, s.takeOver(db, existing, lockedUntil)
+		}
+		return &existing, nil
+	}
+
+	return nil, ErrInProgress
+}
+
+// takeOver hands an in-progress record to the caller once its lease has
+// passed, which means the request that reserved it never settled it. Only
+// one retry wins the update; the others keep getting ErrInProgress.
+func (s *Store) takeOver(db *gorm.DB, existing schemas.IdempotencyKey, lockedUntil time.Time) error {
+	if existing.LockedUntil != nil && !existing.LockedUntil.Before(s.now()) {
+		return ErrInProgress
+	}
+
+	query := db.Model(&schemas.IdempotencyKey{}).Where("id = ? AND status = 0", existing.ID)
+	if existing.LockedUntil == nil {
+		query = query.Where("locked_until IS NULL")
+	} else {
+		query = query.Where("locked_until = ?", *existing.LockedUntil)
+	}
+	res := query.Update("locked_until", lockedUntil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrInProgress
+	}
+	return nil
+}
+
+// Complete stores the response for a reserved key.
+func (s *Store) Complete(ctx context.Context, keyHash string, status int, contentType string, body []byte) error {
+	return s.db.WithContext(ctx).Model(&schemas.IdempotencyKey{}).
+		Where("key_hash = ?", keyHash).
+		Updates(map[string]any{"status": status, "content_type": contentType, "body": body, "locked_until": nil}).Error
+}
+
+// Release drops a reserved key so the client can retry, used when the
+// request failed on our side.
+func (s *Store) Release(ctx context.Context, keyHash string) error {
+	return s.db.WithContext(ctx).Where("key_hash = ?", keyHash).Delete(&schemas.IdempotencyKey{}).Error
+}
+
+// DeleteExpired removes expired records of every tenant.
+func (s *Store) DeleteExpired(ctx context.Context) (int64, error) {
+	res := s.db.WithContext(tenant.WithoutScope(ctx)).
+		Where("expires_at < ?", s.now()).
+		Delete(&schemas.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
+
+// RunCleanup calls DeleteExpired every interval until ctx is done.
+func (s *Store) RunCleanup(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.DeleteExpired(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
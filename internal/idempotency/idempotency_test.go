@@ -0,0 +1,175 @@
+package idempotency
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+)
+
+func newStore(t *testing.T) (*Store, sqlmock.Sqlmock, time.Time) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent), TranslateError: true})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := NewStore(gdb, time.Hour, time.Minute)
+	s.now = func() time.Time { return now }
+	return s, mock, now
+}
+
+var keyCols = []string{"id", "key_hash", "request_hash", "status", "content_type", "body", "locked_until", "expires_at"}
+
+// duplicate espera a inserção recusada pela chave já existente e a leitura
+// do registro guardado.
+func duplicate(mock sqlmock.Sqlmock, rows *sqlmock.Rows) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_keys`")).
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `idempotency_keys` WHERE key_hash = ?")).
+		WithArgs("k", 1).
+		WillReturnRows(rows)
+}
+
+func TestReserve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reserva uma chave nova com o prazo de processamento", func(t *testing.T) {
+		s, mock, now := newStore(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_keys`")).
+			WithArgs(sqlmock.AnyArg(), "k", "r", 0, "", sqlmock.AnyArg(), now.Add(time.Minute), now.Add(time.Hour), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		stored, err := s.Reserve(ctx, "k", "r")
+		require.NoError(t, err)
+		require.Nil(t, stored)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("devolve a resposta já gravada", func(t *testing.T) {
+		s, mock, now := newStore(t)
+
+		duplicate(mock, sqlmock.NewRows(keyCols).AddRow(1, "k", "r", 201, "application/json", []byte(`{"id":7}`), nil, now.Add(time.Hour)))
+
+		stored, err := s.Reserve(ctx, "k", "r")
+		require.NoError(t, err)
+		require.Equal(t, 201, stored.Status)
+		require.Equal(t, []byte(`{"id":7}`), stored.Body)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa a chave reutilizada com outra requisição", func(t *testing.T) {
+		s, mock, now := newStore(t)
+
+		duplicate(mock, sqlmock.NewRows(keyCols).AddRow(1, "k", "outra", 201, "", nil, nil, now.Add(time.Hour)))
+
+		_, err := s.Reserve(ctx, "k", "r")
+		require.ErrorIs(t, err, ErrKeyReused)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa enquanto o prazo de processamento não passou", func(t *testing.T) {
+		s, mock, now := newStore(t)
+
+		duplicate(mock, sqlmock.NewRows(keyCols).AddRow(1, "k", "r", 0, "", nil, now.Add(30*time.Second), now.Add(time.Hour)))
+
+		_, err := s.Reserve(ctx, "k", "r")
+		require.ErrorIs(t, err, ErrInProgress)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("assume a chave de uma requisição que não terminou no prazo", func(t *testing.T) {
+		s, mock, now := newStore(t)
+		lapsed := now.Add(-time.Second)
+
+		duplicate(mock, sqlmock.NewRows(keyCols).AddRow(1, "k", "r", 0, "", nil, lapsed, now.Add(time.Hour)))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `idempotency_keys` SET `locked_until`=?,`updated_at`=? WHERE (id = ? AND status = 0) AND locked_until = ?")).
+			WithArgs(now.Add(time.Minute), sqlmock.AnyArg(), 1, lapsed).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		stored, err := s.Reserve(ctx, "k", "r")
+		require.NoError(t, err)
+		require.Nil(t, stored)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("só uma nova tentativa assume a chave", func(t *testing.T) {
+		s, mock, now := newStore(t)
+		lapsed := now.Add(-time.Second)
+
+		duplicate(mock, sqlmock.NewRows(keyCols).AddRow(1, "k", "r", 0, "", nil, lapsed, now.Add(time.Hour)))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `idempotency_keys` SET `locked_until`=?")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		_, err := s.Reserve(ctx, "k", "r")
+		require.ErrorIs(t, err, ErrInProgress)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("apaga o registro expirado e reserva de novo", func(t *testing.T) {
+		s, mock, now := newStore(t)
+		expired := now.Add(-time.Minute)
+
+		duplicate(mock, sqlmock.NewRows(keyCols).AddRow(1, "k", "outra", 201, "", nil, nil, expired))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `idempotency_keys` WHERE id = ? AND expires_at = ?")).
+			WithArgs(1, expired).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_keys`")).WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		stored, err := s.Reserve(ctx, "k", "r")
+		require.NoError(t, err)
+		require.Nil(t, stored)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestComplete(t *testing.T) {
+	s, mock, _ := newStore(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `idempotency_keys` SET `body`=?,`content_type`=?,`locked_until`=?,`status`=?,`updated_at`=? WHERE key_hash = ?")).
+		WithArgs([]byte(`{}`), "application/json", nil, 201, sqlmock.AnyArg(), "k").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, s.Complete(context.Background(), "k", 201, "application/json", []byte(`{}`)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteExpired(t *testing.T) {
+	s, mock, now := newStore(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `idempotency_keys` WHERE expires_at < ?")).
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	n, err := s.DeleteExpired(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 3, n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,149 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"slices"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const jpegQuality = 85
+
+// largest decoded image accepted, a 24 megapixel photo with some room; an
+// RGBA image this size already takes 100 MB once decoded
+const maxPixels = 25_000_000
+
+var (
+	ErrUnsupportedType = errors.New("media: unsupported image type, expected JPEG, PNG, GIF or WebP")
+	ErrTooManyPixels   = errors.New("media: image has too many pixels")
+)
+
+var decoders = map[string]func([]byte) (image.Image, image.Config, error){
+	"image/jpeg": decodeWith(jpeg.Decode, jpeg.DecodeConfig),
+	"image/png":  decodeWith(png.Decode, png.DecodeConfig),
+	"image/gif":  decodeWith(gif.Decode, gif.DecodeConfig),
+	"image/webp": decodeWith(webp.Decode, webp.DecodeConfig),
+}
+
+// Image is an uploaded picture, decoded and checked.
+type Image struct {
+	ContentType string
+	Width       int
+	Height      int
+	img         image.Image
+}
+
+// Variant is an encoded rendition of an image.
+type Variant struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Decode sniffs the type of data from its content, never from the name or
+// headers the client sent, and decodes it. Dimensions are read before the
+// pixels so a small file cannot claim a huge canvas.
+func Decode(data []byte) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	decode, ok := decoders[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+	img, cfg, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Image{ContentType: contentType, Width: cfg.Width, Height: cfg.Height, img: img}, nil
+}
+
+func decodeWith(
+	decode func(r io.Reader) (image.Image, error),
+	decodeConfig func(r io.Reader) (image.Config, error),
+) func([]byte) (image.Image, image.Config, error) {
+	return func(data []byte) (image.Image, image.Config, error) {
+		cfg, err := decodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, cfg, fmt.Errorf("media: invalid image: %v", err)
+		}
+		if cfg.Width < 1 || cfg.Height < 1 || cfg.Width*cfg.Height > maxPixels {
+			return nil, cfg, ErrTooManyPixels
+		}
+		img, err := decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, cfg, fmt.Errorf("media: invalid image: %v", err)
+		}
+		return img, cfg, nil
+	}
+}
+
+// Variants renders the image at each width, never upscaling, as a JPEG (a
+// PNG when it has transparency). Widths at or above the original size
+// collapse into one rendition at the original size. There is no WebP
+// rendition: neither the standard library nor golang.org/x/image can encode
+// it, and a hand-rolled encoder is not worth maintaining here.
+func (i *Image) Variants(widths []int) ([]Variant, error) {
+	sizes := make([]int, 0, len(widths))
+	for _, w := range widths {
+		sizes = append(sizes, min(w, i.Width))
+	}
+	slices.Sort(sizes)
+	sizes = slices.Compact(sizes)
+
+	opaque := isOpaque(i.img)
+	variants := make([]Variant, 0, len(sizes))
+	for _, w := range sizes {
+		h := max(1, (i.Height*w+i.Width/2)/i.Width)
+		scaled := image.NewNRGBA(image.Rect(0, 0, w, h))
+		draw.CatmullRom.Scale(scaled, scaled.Rect, i.img, i.img.Bounds(), draw.Src, nil)
+
+		var buf bytes.Buffer
+		v := Variant{Name: fmt.Sprintf("w%d", w), Width: w, Height: h}
+		if opaque {
+			v.ContentType = "image/jpeg"
+			if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: jpegQuality}); err != nil {
+				return nil, err
+			}
+		} else {
+			v.ContentType = "image/png"
+			if err := png.Encode(&buf, scaled); err != nil {
+				return nil, err
+			}
+		}
+		v.Data = buf.Bytes()
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// Extension is the file extension used for a stored content type.
+func Extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
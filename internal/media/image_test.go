@@ -0,0 +1,131 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encoded(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	switch format {
+	case "png":
+		require.NoError(t, png.Encode(&buf, img))
+	case "jpeg":
+		require.NoError(t, jpeg.Encode(&buf, img, nil))
+	}
+	return buf.Bytes()
+}
+
+// whiteWebP é uma imagem WebP sem perdas de 800x600, toda branca.
+const whiteWebP = "UklGRhYAAABXRUJQVlA4TAoAAAAvH8OVAEX/I/of"
+
+func TestDecode(t *testing.T) {
+	photo := image.NewRGBA(image.Rect(0, 0, 800, 600))
+	for i := range photo.Pix {
+		photo.Pix[i] = 0xff
+	}
+
+	webpData, err := base64.StdEncoding.DecodeString(whiteWebP)
+	require.NoError(t, err)
+	files := map[string][]byte{
+		"png":  encoded(t, photo, "png"),
+		"jpeg": encoded(t, photo, "jpeg"),
+		"webp": webpData,
+	}
+	for format, data := range files {
+		t.Run("aceita "+format, func(t *testing.T) {
+			img, err := Decode(data)
+			require.NoError(t, err)
+			require.Equal(t, "image/"+format, img.ContentType)
+			require.Equal(t, 800, img.Width)
+			require.Equal(t, 600, img.Height)
+		})
+	}
+
+	t.Run("recusa o que não é imagem", func(t *testing.T) {
+		_, err := Decode([]byte("%PDF-1.7 não é uma foto"))
+		require.ErrorIs(t, err, ErrUnsupportedType)
+	})
+
+	t.Run("recusa imagem truncada", func(t *testing.T) {
+		data := encoded(t, photo, "png")
+		_, err := Decode(data[:len(data)/2])
+		require.ErrorContains(t, err, "invalid image")
+	})
+
+	t.Run("recusa dimensões absurdas antes de decodificar", func(t *testing.T) {
+		data := encoded(t, image.NewGray(image.Rect(0, 0, 1, 1)), "png")
+		// largura e altura do IHDR, que vem logo após a assinatura
+		copy(data[16:24], []byte{0, 0, 0x80, 0, 0, 0, 0x80, 0})
+		binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+		_, err := Decode(data)
+		require.ErrorIs(t, err, ErrTooManyPixels)
+	})
+}
+
+func TestVariants(t *testing.T) {
+	t.Run("gera jpeg sem ampliar", func(t *testing.T) {
+		img, err := Decode(encoded(t, image.NewRGBA(image.Rect(0, 0, 600, 400)), "jpeg"))
+		require.NoError(t, err)
+
+		variants, err := img.Variants([]int{160, 480, 1024})
+		require.NoError(t, err)
+
+		var got []string
+		for _, v := range variants {
+			got = append(got, v.Name+" "+v.ContentType)
+			decoded, _, err := image.Decode(bytes.NewReader(v.Data))
+			require.NoError(t, err)
+			require.Equal(t, v.Width, decoded.Bounds().Dx())
+		}
+		require.Equal(t, []string{"w160 image/jpeg", "w480 image/jpeg", "w600 image/jpeg"}, got)
+		require.Equal(t, 107, variants[0].Height)
+	})
+
+	t.Run("mantém transparência em png", func(t *testing.T) {
+		logo := image.NewNRGBA(image.Rect(0, 0, 300, 300))
+		logo.SetNRGBA(10, 10, color.NRGBA{255, 0, 0, 128})
+		img, err := Decode(encoded(t, logo, "png"))
+		require.NoError(t, err)
+
+		variants, err := img.Variants([]int{160})
+		require.NoError(t, err)
+		require.Equal(t, "image/png", variants[0].ContentType)
+	})
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir, "/media/")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, s.Put(ctx, "t1/7/a.jpg", strings.NewReader("foto"), 4, "image/jpeg"))
+	data, err := os.ReadFile(filepath.Join(dir, "t1", "7", "a.jpg"))
+	require.NoError(t, err)
+	require.Equal(t, "foto", string(data))
+	require.Equal(t, "/media/t1/7/a.jpg", s.URL("t1/7/a.jpg"))
+
+	require.NoError(t, s.Delete(ctx, "t1/7/a.jpg"))
+	require.NoError(t, s.Delete(ctx, "t1/7/a.jpg"), "apagar de novo não é erro")
+	_, err = os.Stat(filepath.Join(dir, "t1", "7", "a.jpg"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	for _, key := range []string{"", "../fora.jpg", "t1/../../fora.jpg", "/abs.jpg"} {
+		require.Error(t, s.Put(ctx, key, strings.NewReader("x"), 1, "image/jpeg"), key)
+	}
+}
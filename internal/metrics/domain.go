@@ -0,0 +1,72 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+var (
+	productsDesc = prometheus.NewDesc(namespace+"_catalog_products",
+		"Products in the catalog, excluding deleted ones.", []string{"tenant"}, nil)
+	stockUnitsDesc = prometheus.NewDesc(namespace+"_catalog_stock_units",
+		"Units in stock summed over all products.", []string{"tenant"}, nil)
+	lowStockDesc = prometheus.NewDesc(namespace+"_catalog_low_stock_products",
+		"Products whose quantity is at or below their reorder point.", []string{"tenant"}, nil)
+	scrapeErrorDesc = prometheus.NewDesc(namespace+"_catalog_scrape_error",
+		"1 when the last catalog query failed.", nil, nil)
+)
+
+// DomainCollector reads catalog gauges from the database at scrape time,
+// grouped by tenant. Low stock uses each product's reorder point, the same
+// rule as the stock alerts; products without one are never low.
+type DomainCollector struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+func NewDomainCollector(db *gorm.DB) *DomainCollector {
+	return &DomainCollector{db: db, timeout: 5 * time.Second}
+}
+
+func (c *DomainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- productsDesc
+	ch <- stockUnitsDesc
+	ch <- lowStockDesc
+	ch <- scrapeErrorDesc
+}
+
+type catalogRow struct {
+	TenantID   string
+	Products   int64
+	StockUnits int64
+	LowStock   int64
+}
+
+func (c *DomainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(tenant.WithoutScope(context.Background()), c.timeout)
+	defer cancel()
+
+	var rows []catalogRow
+	err := c.db.WithContext(ctx).Model(&schemas.Product{}).
+		Select("tenant_id, COUNT(*) AS products, COALESCE(SUM(quantity), 0) AS stock_units, " +
+			"COALESCE(SUM(CASE WHEN quantity <= reorder_point THEN 1 ELSE 0 END), 0) AS low_stock").
+		Group("tenant_id").
+		Scan(&rows).Error
+
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, 1)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, 0)
+
+	for _, r := range rows {
+		ch <- prometheus.MustNewConstMetric(productsDesc, prometheus.GaugeValue, float64(r.Products), r.TenantID)
+		ch <- prometheus.MustNewConstMetric(stockUnitsDesc, prometheus.GaugeValue, float64(r.StockUnits), r.TenantID)
+		ch <- prometheus.MustNewConstMetric(lowStockDesc, prometheus.GaugeValue, float64(r.LowStock), r.TenantID)
+	}
+}
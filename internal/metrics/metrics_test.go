@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func newMockGorm(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+	require.NoError(t, err)
+
+	return gdb, mock
+}
+
+func TestDomainCollector(t *testing.T) {
+	gdb, mock := newMockGorm(t)
+
+	mock.ExpectQuery(`(?is)SELECT tenant_id, COUNT\(\*\).*quantity <= reorder_point.*FROM.*products.*deleted_at.*IS NULL.*GROUP BY.*tenant_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"tenant_id", "products", "stock_units", "low_stock"}).
+			AddRow("loja1", 3, 42, 1).
+			AddRow("loja2", 1, 0, 1))
+
+	expected := `
+# HELP products_catalog_low_stock_products Products whose quantity is at or below their reorder point.
+# TYPE products_catalog_low_stock_products gauge
+products_catalog_low_stock_products{tenant="loja1"} 1
+products_catalog_low_stock_products{tenant="loja2"} 1
+# HELP products_catalog_stock_units Units in stock summed over all products.
+# TYPE products_catalog_stock_units gauge
+products_catalog_stock_units{tenant="loja1"} 42
+products_catalog_stock_units{tenant="loja2"} 0
+`
+	err := testutil.CollectAndCompare(NewDomainCollector(gdb), strings.NewReader(expected),
+		"products_catalog_low_stock_products", "products_catalog_stock_units")
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGormPlugin(t *testing.T) {
+	gdb, mock := newMockGorm(t)
+	require.NoError(t, gdb.Use(GormPlugin{}))
+
+	mock.ExpectQuery(`(?is)SELECT.*FROM.*products`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	before := testutil.CollectAndCount(DBQueryDuration)
+
+	var products []schemas.Product
+	require.NoError(t, gdb.Find(&products).Error)
+
+	require.Equal(t, before+1, testutil.CollectAndCount(DBQueryDuration))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ActorHeader    = "X-Actor"
+	AnonymousActor = "anonymous"
+)
+
+// Actor identifies who is performing the current request, for revisions and
+// the audit trail. It is the subject of the principal; the X-Actor header is
+// read by Unauthenticated alone, so with authentication enabled a request
+// that never authenticated, even one sending X-Actor, is anonymous.
+func Actor(ctx *gin.Context) string {
+	if p, ok := auth.GetPrincipal(ctx); ok {
+		return p.Subject
+	}
+	return AnonymousActor
+}
+
+// clientID identifies the caller for per-client state: the API key or user
+// when authenticated, the client IP otherwise.
+func clientID(ctx *gin.Context) string {
+	if p, ok := auth.GetPrincipal(ctx); ok {
+		switch p.Method {
+		case auth.MethodAPIKey:
+			return p.Subject
+		case auth.MethodJWT:
+			// the same subject may exist in more than one tenant
+			return "user:" + p.Tenant + "/" + p.Subject
+		}
+	}
+	return "ip:" + ctx.ClientIP()
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+)
+
+func TestActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("ignora X-Actor com autenticação ligada", func(t *testing.T) {
+		verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: "segredo"})
+		require.NoError(t, err)
+
+		var actor string
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			ctx.Next()
+			actor = Actor(ctx)
+		})
+		r.Use(Authenticate(verifier, nil, nil))
+		r.GET("/v1/products", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set(ActorHeader, "admin")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, AnonymousActor, actor)
+	})
+
+	t.Run("usa X-Actor com autenticação desligada", func(t *testing.T) {
+		var actor string
+		r := gin.New()
+		r.Use(Unauthenticated())
+		r.GET("/v1/products", func(ctx *gin.Context) { actor = Actor(ctx) })
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set(ActorHeader, "maria")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		require.Equal(t, "maria", actor)
+	})
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/audit"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Audit appends every mutating request, whatever its outcome, to the audit
+// trail once the handler has finished.
+func Audit(db *gorm.DB) gin.HandlerFunc {
+	logger := config.GetLogger("audit")
+
+	return func(ctx *gin.Context) {
+		if !isMutation(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+
+		start := time.Now()
+		ctx.Next()
+
+		entry := schemas.AuditEntry{
+			Actor:     Actor(ctx),
+			IP:        ctx.ClientIP(),
+			RequestID: GetRequestID(ctx),
+			Method:    ctx.Request.Method,
+			Route:     ctx.FullPath(),
+			Path:      ctx.Request.URL.RequestURI(),
+			Status:    ctx.Writer.Status(),
+			LatencyMs: time.Since(start).Milliseconds(),
+		}
+
+		// the entry is written even when the client has gone away; requests
+		// rejected before the tenant was resolved are kept under the default tenant
+		reqCtx := context.WithoutCancel(ctx.Request.Context())
+		if _, ok := tenant.FromContext(reqCtx); !ok {
+			reqCtx = tenant.WithTenant(reqCtx, tenant.Default)
+		}
+
+		if err := audit.Append(db.WithContext(reqCtx), &entry); err != nil {
+			logger.WithContext(ctx.Request.Context()).Errorf("audit append error (request %s): %v", entry.RequestID, err)
+		}
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+const APIKeyHeader = "X-API-Key"
+
+// Authenticate requires a valid bearer token, or an API key in X-API-Key, on
+// every route except the ones listed in publicPaths (Gin route patterns, e.g.
+// "/swagger/*any").
+func Authenticate(verifier *auth.Verifier, keys *auth.APIKeyAuthenticator, publicPaths []string) gin.HandlerFunc {
+	public := make(map[string]bool, len(publicPaths))
+	for _, p := range publicPaths {
+		public[p] = true
+	}
+
+	return func(ctx *gin.Context) {
+		if public[ctx.FullPath()] {
+			ctx.Next()
+			return
+		}
+
+		var (
+			principal *auth.Principal
+			err       error
+		)
+		if key := ctx.GetHeader(APIKeyHeader); key != "" {
+			principal, err = keys.Authenticate(ctx.Request.Context(), key)
+		} else {
+			principal, err = verifier.Verify(bearerToken(ctx))
+		}
+		if err != nil {
+			ctx.Header("WWW-Authenticate", `Bearer realm="products"`)
+			abortWithError(ctx, http.StatusUnauthorized, authErrorMessage(err))
+			return
+		}
+
+		auth.SetPrincipal(ctx, principal)
+		config.AddLogFields(ctx.Request.Context(), slog.String("user", principal.Subject))
+		ctx.Next()
+	}
+}
+
+func bearerToken(ctx *gin.Context) string {
+	header := ctx.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// token parsing details stay in the logs, not in the response
+func authErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, auth.ErrMissingToken):
+		return "missing bearer token"
+	case errors.Is(err, auth.ErrExpiredToken):
+		return "token expired"
+	case errors.Is(err, auth.ErrRevokedAPIKey):
+		return "api key revoked"
+	case errors.Is(err, auth.ErrExpiredAPIKey):
+		return "api key expired"
+	case errors.Is(err, auth.ErrInvalidAPIKey):
+		return "invalid api key"
+	}
+	return "invalid token"
+}
+
+// Unauthenticated stands in for Authenticate when authentication is disabled:
+// every caller gets full permissions, including picking any tenant, under the
+// name sent in X-Actor.
+func Unauthenticated() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		subject := ctx.GetHeader(ActorHeader)
+		if subject == "" {
+			subject = AnonymousActor
+		}
+		perms := append([]auth.Permission{auth.PlatformAdmin}, auth.AllPermissions...)
+		auth.SetPrincipal(ctx, &auth.Principal{Subject: subject, Permissions: perms})
+		config.AddLogFields(ctx.Request.Context(), slog.String("user", subject))
+		ctx.Next()
+	}
+}
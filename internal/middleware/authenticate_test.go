@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+)
+
+func setupGinAuth(t *testing.T) *gin.Engine {
+	verifier, err := auth.NewVerifier(config.AuthConfig{HS256Secret: "segredo"})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Authenticate(verifier, nil, []string{"/healthz"}))
+	r.GET("/healthz", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.GET("/v1/products", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString(auth.SubjectKey))
+	})
+	return r
+}
+
+func TestAuthenticate(t *testing.T) {
+	r := setupGinAuth(t)
+
+	t.Run("retorna 401 sem token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/products", nil))
+
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Body.String(), "missing bearer token")
+		require.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("retorna 401 com token expirado", func(t *testing.T) {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "maria",
+			"exp": time.Now().Add(-time.Hour).Unix(),
+		}).SignedString([]byte("segredo"))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Contains(t, w.Body.String(), "token expired")
+	})
+
+	t.Run("libera rotas públicas", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("expõe o subject para os handlers", func(t *testing.T) {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub": "maria",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("segredo"))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("Authorization", "bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "maria", w.Body.String())
+	})
+}
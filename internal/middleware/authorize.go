@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets the request through only when the caller holds
+// every one of perms.
+func RequirePermission(perms ...auth.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if missing, ok := auth.Missing(ctx, perms...); ok {
+			abortWithError(ctx, http.StatusForbidden, MissingPermissionMessage(missing))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireAnyPermission lets the request through when the caller holds at
+// least one of perms; handlers are expected to refine the check.
+func RequireAnyPermission(perms ...auth.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		for _, perm := range perms {
+			if auth.Can(ctx, perm) {
+				ctx.Next()
+				return
+			}
+		}
+		names := make([]string, 0, len(perms))
+		for _, perm := range perms {
+			names = append(names, string(perm))
+		}
+		abortWithError(ctx, http.StatusForbidden, "missing permission: one of "+strings.Join(names, ", "))
+	}
+}
+
+func MissingPermissionMessage(perm auth.Permission) string {
+	return "missing permission: " + string(perm)
+}
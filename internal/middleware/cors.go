@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS applies the configured cross-origin policy. Origins are matched by
+// cfg.AllowsOrigin so subdomain patterns are checked against the parsed host
+// rather than by string prefix and suffix.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	c := cors.Config{
+		AllowMethods:     cfg.AllowMethods,
+		AllowHeaders:     cfg.AllowHeaders,
+		ExposeHeaders:    cfg.ExposeHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	}
+	if cfg.AllowsAnyOrigin() {
+		c.AllowAllOrigins = true
+	} else {
+		c.AllowOriginFunc = cfg.AllowsOrigin
+	}
+	return cors.New(c)
+}
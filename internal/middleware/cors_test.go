@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+)
+
+func setupGinCORS(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORS(cfg))
+	r.GET("/v1/products", func(ctx *gin.Context) {
+		ctx.Header("ETag", `"v1"`)
+		ctx.Status(http.StatusOK)
+	})
+	return r
+}
+
+func corsRequest(r *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/v1/products", nil)
+	req.Header.Set("Origin", origin)
+	if method == http.MethodOptions {
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.staging.example.com"},
+		AllowMethods:     []string{http.MethodGet, http.MethodPost},
+		AllowHeaders:     []string{"Authorization", "Content-Type"},
+		ExposeHeaders:    []string{"ETag", "RateLimit-Remaining"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	r := setupGinCORS(cfg)
+
+	t.Run("preflight de origem exata", func(t *testing.T) {
+		w := corsRequest(r, http.MethodOptions, "https://app.example.com")
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, "GET,POST", w.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("subdomínio casa com o padrão", func(t *testing.T) {
+		w := corsRequest(r, http.MethodGet, "https://pr-42.staging.example.com")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "https://pr-42.staging.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "Etag,Ratelimit-Remaining", w.Header().Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("origens fora da política são recusadas", func(t *testing.T) {
+		for _, origin := range []string{
+			"https://staging.example.com",
+			"http://pr-42.staging.example.com",
+			"https://evil.com",
+			"https://app.example.com.evil.com",
+			"https://evilstaging.example.com",
+		} {
+			w := corsRequest(r, http.MethodGet, origin)
+			require.Equal(t, http.StatusForbidden, w.Code, origin)
+			require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"), origin)
+		}
+	})
+
+	t.Run("asterisco sem credenciais libera qualquer origem", func(t *testing.T) {
+		r := setupGinCORS(config.CORSConfig{
+			AllowOrigins: []string{config.AnyOrigin},
+			AllowMethods: []string{http.MethodGet},
+		})
+
+		w := corsRequest(r, http.MethodGet, "https://qualquer.dev")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
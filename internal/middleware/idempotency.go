@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/idempotency"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// set on responses replayed from a previous request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency makes retries of a request carrying an Idempotency-Key header
+// safe: the first response is stored and replayed for the same client,
+// route and key. Reusing a key with a different request is rejected with
+// 422. Server errors are not stored so the client can retry them.
+func Idempotency(store *idempotency.Store) gin.HandlerFunc {
+	logger := config.GetLogger("idempotency")
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(ctx, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			abortWithError(ctx, http.StatusBadRequest, "error reading request body")
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		keyHash := hashParts(clientID(ctx), ctx.Request.Method+" "+ctx.FullPath(), key)
+		requestHash := hashParts(ctx.Request.URL.RequestURI(), string(body))
+
+		stored, err := store.Reserve(ctx.Request.Context(), keyHash, requestHash)
+		switch {
+		case errors.Is(err, idempotency.ErrKeyReused):
+			abortWithError(ctx, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+			return
+		case errors.Is(err, idempotency.ErrInProgress):
+			abortWithError(ctx, http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+			return
+		case err != nil:
+			logger.WithContext(ctx.Request.Context()).Errorf("idempotency reserve error: %v", err)
+			abortWithError(ctx, http.StatusInternalServerError, "error checking Idempotency-Key")
+			return
+		}
+
+		if stored != nil {
+			ctx.Header(IdempotentReplayedHeader, "true")
+			ctx.Data(stored.Status, stored.ContentType, stored.Body)
+			ctx.Abort()
+			return
+		}
+
+		// the client may hang up before we finish; the record must still be settled
+		settleCtx := context.WithoutCancel(ctx.Request.Context())
+		defer func() {
+			if r := recover(); r != nil {
+				if err := store.Release(settleCtx, keyHash); err != nil {
+					logger.WithContext(ctx.Request.Context()).Errorf("idempotency release error: %v", err)
+				}
+				panic(r)
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+
+		ctx.Next()
+
+		if status := recorder.Status(); status >= http.StatusInternalServerError {
+			err = store.Release(settleCtx, keyHash)
+		} else {
+			err = store.Complete(settleCtx, keyHash, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			logger.WithContext(ctx.Request.Context()).Errorf("idempotency store error: %v", err)
+		}
+	}
+}
+
+// bodyRecorder keeps a copy of the response body as it is written.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0x1f})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/idempotency"
+)
+
+func newMockGorm(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, *sql.DB) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	dialector := mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	})
+
+	gdb, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         glogger.Default.LogMode(glogger.Silent),
+		TranslateError: true,
+	})
+	require.NoError(t, err)
+
+	return gdb, mock, sqlDB
+}
+
+var idempotencyCols = []string{"id", "key_hash", "request_hash", "status", "content_type", "body", "locked_until", "expires_at", "created_at", "updated_at"}
+
+func setupGinIdempotency(t *testing.T, calls *int, status int) (*gin.Engine, sqlmock.Sqlmock) {
+	gdb, mock, sqlDB := newMockGorm(t)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Idempotency(idempotency.NewStore(gdb, time.Hour, time.Minute)))
+	r.POST("/v1/product", func(ctx *gin.Context) {
+		*calls++
+		ctx.JSON(status, gin.H{"id": 7})
+	})
+	return r, mock
+}
+
+func postWithKey(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func expectDuplicateKey(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_keys`")).
+		WillReturnError(&mysqldriver.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	mock.ExpectRollback()
+}
+
+func TestIdempotency(t *testing.T) {
+	body := `{"name":"Teclado","price":299}`
+
+	t.Run("grava a resposta da primeira requisição", func(t *testing.T) {
+		calls := 0
+		r, mock := setupGinIdempotency(t, &calls, http.StatusCreated)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_keys`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(`(?is)UPDATE.*idempotency_keys.*SET.*body.*status`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		w := postWithKey(r, body)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, 1, calls)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("repete a resposta gravada sem chamar o handler", func(t *testing.T) {
+		calls := 0
+		r, mock := setupGinIdempotency(t, &calls, http.StatusCreated)
+
+		expectDuplicateKey(mock)
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*idempotency_keys.*WHERE.*key_hash`).WillReturnRows(sqlmock.NewRows(idempotencyCols).
+			AddRow(1, "k", hashParts("/v1/product", body), 201, "application/json; charset=utf-8", []byte(`{"id":7}`), nil, time.Now().Add(time.Hour), time.Now(), time.Now()))
+
+		w := postWithKey(r, body)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.JSONEq(t, `{"id":7}`, w.Body.String())
+		require.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+		require.Equal(t, 0, calls)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 422 quando a chave é reutilizada com outro corpo", func(t *testing.T) {
+		calls := 0
+		r, mock := setupGinIdempotency(t, &calls, http.StatusCreated)
+
+		expectDuplicateKey(mock)
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*idempotency_keys`).WillReturnRows(sqlmock.NewRows(idempotencyCols).
+			AddRow(1, "k", hashParts("/v1/product", `{"name":"Mouse"}`), 201, "application/json", []byte(`{}`), nil, time.Now().Add(time.Hour), time.Now(), time.Now()))
+
+		w := postWithKey(r, body)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		require.Equal(t, 0, calls)
+	})
+
+	t.Run("retorna 409 enquanto a primeira requisição não termina", func(t *testing.T) {
+		calls := 0
+		r, mock := setupGinIdempotency(t, &calls, http.StatusCreated)
+
+		expectDuplicateKey(mock)
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*idempotency_keys`).WillReturnRows(sqlmock.NewRows(idempotencyCols).
+			AddRow(1, "k", hashParts("/v1/product", body), 0, "", nil, time.Now().Add(time.Minute), time.Now().Add(time.Hour), time.Now(), time.Now()))
+
+		w := postWithKey(r, body)
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Equal(t, 0, calls)
+	})
+
+	t.Run("libera a chave quando o handler falha", func(t *testing.T) {
+		calls := 0
+		r, mock := setupGinIdempotency(t, &calls, http.StatusInternalServerError)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `idempotency_keys`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(`(?is)DELETE FROM.*idempotency_keys.*WHERE.*key_hash`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		w := postWithKey(r, body)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ignora requisições sem a header", func(t *testing.T) {
+		calls := 0
+		r, mock := setupGinIdempotency(t, &calls, http.StatusCreated)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/product", strings.NewReader(body)))
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, 1, calls)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
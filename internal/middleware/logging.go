@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Logging writes one structured record per request and prepares the request
+// context so every log made while serving it carries the request ID and
+// route. Authentication and tenant resolution add the user and tenant.
+// It must run after RequestID.
+func Logging() gin.HandlerFunc {
+	logger := config.GetLogger("http")
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		reqCtx := config.WithLogFields(ctx.Request.Context(),
+			slog.String("request_id", GetRequestID(ctx)),
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+		)
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		logger.WithContext(reqCtx).LogAttrs(level, "request",
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", ctx.Writer.Size()),
+			slog.String("client_ip", ctx.ClientIP()),
+		)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Metrics())
+	r.GET("/v1/product/:id", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	t.Run("rótulo usa o padrão da rota e não o caminho", func(t *testing.T) {
+		before := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/v1/product/:id", "200"))
+
+		for _, path := range []string{"/v1/product/1", "/v1/product/2"} {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		after := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/v1/product/:id", "200"))
+		require.Equal(t, float64(2), after-before)
+	})
+
+	t.Run("rotas inexistentes compartilham um rótulo", func(t *testing.T) {
+		before := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404"))
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/wp-admin.php", nil))
+
+		after := testutil.ToFloat64(metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404"))
+		require.Equal(t, float64(1), after-before)
+	})
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("redis down")
+}
+
+func setupGinRateLimit(t *testing.T, store ratelimit.Store) *gin.Engine {
+	t.Helper()
+	def, err := ratelimit.ParseLimit("2/m")
+	require.NoError(t, err)
+	create, err := ratelimit.ParseLimit("1/m")
+	require.NoError(t, err)
+
+	cfg := config.RateLimitConfig{
+		Enabled: true,
+		Default: def,
+		Routes:  map[string]ratelimit.Limit{"POST /v1/product": create},
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		if sub := ctx.GetHeader("X-Test-User"); sub != "" {
+			auth.SetPrincipal(ctx, &auth.Principal{Subject: sub, Method: auth.MethodJWT})
+		}
+		ctx.Next()
+	})
+	r.Use(RateLimit(cfg, store))
+	r.GET("/v1/products", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.POST("/v1/product", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+	return r
+}
+
+func doRequest(r *gin.Engine, method, path, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if user != "" {
+		req.Header.Set("X-Test-User", user)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Run("retorna 429 com Retry-After ao esgotar o limite", func(t *testing.T) {
+		r := setupGinRateLimit(t, ratelimit.NewMemoryStore())
+
+		w := doRequest(r, http.MethodGet, "/v1/products", "maria")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		require.Equal(t, "2;w=60;burst=2", w.Header().Get("RateLimit-Policy"))
+
+		doRequest(r, http.MethodGet, "/v1/products", "maria")
+		w = doRequest(r, http.MethodGet, "/v1/products", "maria")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "30", w.Header().Get("Retry-After"))
+		require.Contains(t, w.Body.String(), "rate limit exceeded")
+
+		// outro usuário tem bucket próprio
+		w = doRequest(r, http.MethodGet, "/v1/products", "joao")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("rota configurada usa bucket próprio", func(t *testing.T) {
+		r := setupGinRateLimit(t, ratelimit.NewMemoryStore())
+
+		require.Equal(t, http.StatusCreated, doRequest(r, http.MethodPost, "/v1/product", "").Code)
+		require.Equal(t, http.StatusTooManyRequests, doRequest(r, http.MethodPost, "/v1/product", "").Code)
+		require.Equal(t, http.StatusOK, doRequest(r, http.MethodGet, "/v1/products", "").Code)
+	})
+
+	t.Run("deixa passar quando o store falha", func(t *testing.T) {
+		r := setupGinRateLimit(t, failingStore{})
+
+		w := doRequest(r, http.MethodGet, "/v1/products", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestIPRateLimit(t *testing.T) {
+	limit, err := ratelimit.ParseLimit("2/m")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(IPRateLimit(limit, ratelimit.NewMemoryStore()))
+	r.GET("/v1/products", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+	r.POST("/v1/product", func(ctx *gin.Context) { ctx.Status(http.StatusCreated) })
+
+	t.Run("soma todas as rotas do mesmo IP, com ou sem usuário", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, doRequest(r, http.MethodGet, "/v1/products", "maria").Code)
+		require.Equal(t, http.StatusCreated, doRequest(r, http.MethodPost, "/v1/product", "joao").Code)
+
+		w := doRequest(r, http.MethodGet, "/v1/products", "")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "30", w.Header().Get("Retry-After"))
+	})
+}
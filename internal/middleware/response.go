@@ -0,0 +1,12 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// abortWithError mirrors the handlers' error body so clients see one format.
+func abortWithError(ctx *gin.Context, code int, msg string) {
+	ctx.Header("Content-type", "application/json")
+	ctx.AbortWithStatusJSON(code, gin.H{
+		"message": msg,
+		"errCode": code,
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant resolves the tenant of the request and stores it in the request
+// context, where the GORM tenant plugin picks it up. A tenant bound to the
+// credential wins; the header and the subdomain are only hints and must agree
+// with it. A credential without a tenant may only pick one through them when
+// it holds auth.PlatformAdmin. With multi-tenancy off every request runs as
+// tenant.Default.
+func Tenant(cfg config.TenancyConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := tenant.Default
+
+		if cfg.Enabled {
+			var (
+				code int
+				msg  string
+			)
+			id, code, msg = resolveTenant(ctx, cfg)
+			if code != 0 {
+				abortWithError(ctx, code, msg)
+				return
+			}
+		}
+
+		ctx.Request = ctx.Request.WithContext(tenant.WithTenant(ctx.Request.Context(), id))
+		config.AddLogFields(ctx.Request.Context(), slog.String("tenant", id))
+		ctx.Next()
+	}
+}
+
+func resolveTenant(ctx *gin.Context, cfg config.TenancyConfig) (string, int, string) {
+	var bound string
+	p, authenticated := auth.GetPrincipal(ctx)
+	if authenticated {
+		bound = p.Tenant
+	}
+
+	requested := ctx.GetHeader(cfg.Header)
+	if requested == "" {
+		requested = subdomainTenant(ctx.Request.Host, cfg.BaseDomain)
+	}
+
+	switch {
+	case bound != "" && requested != "" && requested != bound:
+		return "", http.StatusForbidden, "tenant mismatch"
+	case bound != "":
+		return bound, 0, ""
+	case authenticated && !p.Can(auth.PlatformAdmin):
+		return "", http.StatusForbidden, "credential is not bound to a tenant"
+	case requested == "":
+		return "", http.StatusBadRequest, "tenant is required"
+	case !tenant.IsValidID(requested):
+		return "", http.StatusBadRequest, "invalid tenant"
+	}
+
+	return requested, 0, ""
+}
+
+func subdomainTenant(host, baseDomain string) string {
+	if baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	suffix := "." + strings.TrimPrefix(baseDomain, ".")
+	if !strings.HasSuffix(host, suffix) {
+		return ""
+	}
+
+	sub := strings.TrimSuffix(host, suffix)
+	if strings.Contains(sub, ".") {
+		return ""
+	}
+	return sub
+}
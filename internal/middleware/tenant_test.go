@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+func setupGinTenant(cfg config.TenancyConfig, boundTenant string, roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		auth.SetPrincipal(ctx, &auth.Principal{Subject: "maria", Method: auth.MethodJWT, Tenant: boundTenant, Roles: roles})
+		ctx.Next()
+	})
+	r.Use(Tenant(cfg))
+	r.GET("/v1/products", func(ctx *gin.Context) {
+		id, _ := tenant.FromContext(ctx.Request.Context())
+		ctx.String(http.StatusOK, id)
+	})
+	return r
+}
+
+func TestTenant(t *testing.T) {
+	enabled := config.TenancyConfig{Enabled: true, Header: "X-Tenant-ID", BaseDomain: "api.exemplo.com"}
+
+	t.Run("usa o tenant padrão com multi-tenancy desligado", func(t *testing.T) {
+		r := setupGinTenant(config.TenancyConfig{Header: "X-Tenant-ID"}, "")
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("X-Tenant-ID", "loja1")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, tenant.Default, w.Body.String())
+	})
+
+	t.Run("claim do token tem prioridade", func(t *testing.T) {
+		r := setupGinTenant(enabled, "loja1")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/products", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "loja1", w.Body.String())
+	})
+
+	t.Run("retorna 403 quando header diverge do claim", func(t *testing.T) {
+		r := setupGinTenant(enabled, "loja1")
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("X-Tenant-ID", "loja2")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("retorna 403 para credencial sem tenant", func(t *testing.T) {
+		r := setupGinTenant(enabled, "", auth.RoleAdmin)
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("X-Tenant-ID", "loja2")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "not bound to a tenant")
+	})
+
+	t.Run("plataforma escolhe o tenant pelo header", func(t *testing.T) {
+		r := setupGinTenant(enabled, "", auth.RolePlatform)
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("X-Tenant-ID", "loja2")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "loja2", w.Body.String())
+	})
+
+	t.Run("resolve pelo subdomínio", func(t *testing.T) {
+		r := setupGinTenant(enabled, "", auth.RolePlatform)
+		req := httptest.NewRequest(http.MethodGet, "http://loja3.api.exemplo.com:8080/v1/products", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "loja3", w.Body.String())
+	})
+
+	t.Run("retorna 400 sem tenant", func(t *testing.T) {
+		r := setupGinTenant(enabled, "", auth.RolePlatform)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/products", nil))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "tenant is required")
+	})
+
+	t.Run("retorna 400 com tenant inválido", func(t *testing.T) {
+		r := setupGinTenant(enabled, "", auth.RolePlatform)
+		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
+		req.Header.Set("X-Tenant-ID", "../loja")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
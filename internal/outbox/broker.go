@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message is an outbox event on its way to the broker. Messages with the
+// same Key must be kept in order by the broker.
+type Message struct {
+	ID      string
+	Type    string
+	Key     string
+	Payload []byte
+}
+
+// Broker publishes messages. Publish returns only once the broker has
+// accepted the message; an error means it may or may not have it, and the
+// relay will publish it again.
+type Broker interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// MemoryBus hands messages to in-process subscribers, synchronously and in
+// publish order. It stands in for a real broker in tests and single node
+// setups.
+type MemoryBus struct {
+	mu       sync.RWMutex
+	handlers []func(context.Context, Message) error
+	// called for messages published while nobody is subscribed, which are
+	// otherwise dropped without a trace
+	unheard func(Message)
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{}
+}
+
+// Subscribe registers handler for every message published from now on. A
+// handler error fails the publish, so the message is retried.
+func (b *MemoryBus) Subscribe(handler func(context.Context, Message) error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// OnUnheard registers fn for messages published with no subscriber.
+func (b *MemoryBus) OnUnheard(fn func(Message)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.unheard = fn
+}
+
+func (b *MemoryBus) Publish(ctx context.Context, msg Message) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.handlers) == 0 && b.unheard != nil {
+		b.unheard(msg)
+	}
+	for _, h := range b.handlers {
+		if err := h(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// KafkaRESTBroker produces to a Kafka topic through the REST proxy API
+// (Confluent REST Proxy, Redpanda HTTP Proxy). The message key is the
+// record key, so all events of a product land on the same partition and
+// stay in order.
+type KafkaRESTBroker struct {
+	url    string
+	client *http.Client
+}
+
+func NewKafkaRESTBroker(baseURL, topic string, timeout time.Duration) *KafkaRESTBroker {
+	return &KafkaRESTBroker{
+		url:    strings.TrimRight(baseURL, "/") + "/topics/" + topic,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type kafkaOffsets struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+func (b *KafkaRESTBroker) Publish(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: msg.Key, Value: msg.Payload}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka rest: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kafka rest: %s answered %s", b.url, resp.Status)
+	}
+
+	// the proxy answers 200 even when producing a record failed
+	var offsets kafkaOffsets
+	if err := json.NewDecoder(resp.Body).Decode(&offsets); err != nil {
+		return fmt.Errorf("kafka rest: error decoding response: %v", err)
+	}
+	for _, o := range offsets.Offsets {
+		if o.ErrorCode != nil {
+			return fmt.Errorf("kafka rest: record rejected: %s", o.Error)
+		}
+	}
+	return nil
+}
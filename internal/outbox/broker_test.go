@@ -0,0 +1,70 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaRESTBroker(t *testing.T) {
+	msg := Message{ID: "ev-1", Type: "product.updated", Key: "7", Payload: []byte(`{"id":"ev-1","productId":7}`)}
+
+	t.Run("produz com a chave do produto", func(t *testing.T) {
+		var got kafkaRecords
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/topics/products", r.URL.Path)
+			require.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.Write([]byte(`{"offsets":[{"partition":0,"offset":42}]}`))
+		}))
+		defer srv.Close()
+
+		b := NewKafkaRESTBroker(srv.URL+"/", "products", time.Second)
+		require.NoError(t, b.Publish(context.Background(), msg))
+		require.Len(t, got.Records, 1)
+		require.Equal(t, "7", got.Records[0].Key)
+		require.JSONEq(t, string(msg.Payload), string(got.Records[0].Value))
+	})
+
+	t.Run("registro rejeitado é erro mesmo com 200", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"offsets":[{"error_code":50002,"error":"leader not available"}]}`))
+		}))
+		defer srv.Close()
+
+		err := NewKafkaRESTBroker(srv.URL, "products", time.Second).Publish(context.Background(), msg)
+		require.ErrorContains(t, err, "leader not available")
+	})
+
+	t.Run("status fora de 2xx é erro", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		err := NewKafkaRESTBroker(srv.URL, "products", time.Second).Publish(context.Background(), msg)
+		require.ErrorContains(t, err, "503")
+	})
+}
+
+func TestMemoryBus(t *testing.T) {
+	msg := Message{ID: "ev-1", Type: "product.updated", Key: "7"}
+
+	t.Run("avisa quando ninguém escuta", func(t *testing.T) {
+		bus := NewMemoryBus()
+		var unheard []string
+		bus.OnUnheard(func(m Message) { unheard = append(unheard, m.ID) })
+
+		require.NoError(t, bus.Publish(context.Background(), msg))
+		require.Equal(t, []string{"ev-1"}, unheard)
+
+		bus.Subscribe(func(context.Context, Message) error { return nil })
+		require.NoError(t, bus.Publish(context.Background(), msg))
+		require.Len(t, unheard, 1)
+	})
+}
@@ -0,0 +1,59 @@
+// Package outbox publishes domain events reliably: events are written to
+// the outbox table in the transaction that makes the change, and a relay
+// publishes them to the broker afterwards. Delivery is at least once and in
+// order for each product.
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// NewEvent builds an event about the product for the tenant in ctx.
+func NewEvent(ctx context.Context, eventType string, productID uint, data any) (schemas.Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return schemas.Event{}, fmt.Errorf("error encoding %s event: %v", eventType, err)
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return schemas.Event{}, fmt.Errorf("error generating event id: %v", err)
+	}
+
+	event := schemas.Event{
+		ID:         hex.EncodeToString(b),
+		Type:       eventType,
+		ProductID:  productID,
+		OccurredAt: time.Now(),
+		Data:       raw,
+	}
+	event.TenantID, _ = tenant.FromContext(ctx)
+	return event, nil
+}
+
+// Record writes event to the outbox. It must run in the transaction of the
+// change, so the event is published if and only if the change is committed.
+func Record(tx *gorm.DB, event schemas.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %v", event.Type, err)
+	}
+	row := schemas.OutboxEvent{
+		EventID:   event.ID,
+		Type:      event.Type,
+		ProductID: event.ProductID,
+		Payload:   string(payload),
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return fmt.Errorf("error writing event to the outbox: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Options tune the relay.
+type Options struct {
+	// events claimed per round
+	BatchSize int
+	// how long claimed events are left to the relay that claimed them; past
+	// that, the events of a relay that crashed are claimed again
+	Lease time.Duration
+	// failures before an event is given up and set aside, so the later
+	// events of its product can go
+	MaxAttempts int
+	// wait after the first failure, doubled on each new one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff is the wait before the next try of an event that failed attempts
+// times.
+func (o Options) Backoff(attempts int) time.Duration {
+	d := o.InitialBackoff
+	for i := 1; i < attempts && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, o.MaxBackoff)
+}
+
+// Relay publishes the outbox to a broker, oldest event first.
+type Relay struct {
+	db     *gorm.DB
+	broker Broker
+	opts   Options
+	now    func() time.Time
+}
+
+func NewRelay(db *gorm.DB, broker Broker, opts Options) *Relay {
+	return &Relay{db: db, broker: broker, opts: opts, now: time.Now}
+}
+
+// Run publishes pending events every interval until ctx is done.
+func (r *Relay) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.PublishPending(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// PublishPending publishes one batch of pending events and reports how many
+// made it. The batch is claimed in a short transaction, published with no
+// transaction open and marked in a second one. When an event fails, it is
+// retried with backoff and the later events of the same product wait for it;
+// other products go on. After MaxAttempts the event is set aside as failed.
+// A crash between publishing and marking publishes the event again once the
+// lease runs out, hence at least once.
+func (r *Relay) PublishPending(ctx context.Context) (int, error) {
+	db := r.db.WithContext(tenant.WithoutScope(ctx))
+
+	events, err := r.claim(db)
+	if err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	var (
+		errs      []error
+		failed    = map[uint]error{}
+		held      = map[uint]bool{}
+		published []uint
+		released  []uint
+	)
+	for _, e := range events {
+		if held[e.ProductID] {
+			released = append(released, e.ID)
+			continue
+		}
+		if err := r.broker.Publish(ctx, message(e)); err != nil {
+			held[e.ProductID] = true
+			failed[e.ID] = err
+			errs = append(errs, fmt.Errorf("error publishing event %s: %v", e.EventID, err))
+			continue
+		}
+		published = append(published, e.ID)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, e := range events {
+			pubErr, ok := failed[e.ID]
+			if !ok {
+				continue
+			}
+			attempts := e.Attempts + 1
+			updates := map[string]any{
+				"attempts":      attempts,
+				"last_error":    truncate(pubErr.Error()),
+				"claimed_until": nil,
+			}
+			if attempts >= r.opts.MaxAttempts {
+				updates["failed_at"] = r.now()
+				errs = append(errs, fmt.Errorf("gave up event %s after %d attempts", e.EventID, attempts))
+			} else {
+				updates["next_attempt_at"] = r.now().Add(r.opts.Backoff(attempts))
+			}
+			if err := tx.Model(&e).Updates(updates).Error; err != nil {
+				return fmt.Errorf("error recording outbox failure: %v", err)
+			}
+		}
+		if len(released) > 0 {
+			if err := tx.Model(&schemas.OutboxEvent{}).Where("id IN ?", released).
+				Update("claimed_until", nil).Error; err != nil {
+				return fmt.Errorf("error releasing outbox events: %v", err)
+			}
+		}
+		if len(published) > 0 {
+			if err := tx.Model(&schemas.OutboxEvent{}).Where("id IN ?", published).
+				Updates(map[string]any{"published_at": r.now(), "claimed_until": nil}).Error; err != nil {
+				return fmt.Errorf("error marking events published: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, errors.Join(append(errs, err)...)
+	}
+	return len(published), errors.Join(errs...)
+}
+
+// claim leases the oldest pending events. The rows are locked only while the
+// lease is written. Products with an event leased by another relay or
+// waiting to be retried are skipped entirely: their events are never
+// published out of order, and they don't take up the batch of the others.
+func (r *Relay) claim(db *gorm.DB) ([]schemas.OutboxEvent, error) {
+	var claimed []schemas.OutboxEvent
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		now := r.now()
+		waiting := tx.Model(&schemas.OutboxEvent{}).Select("product_id").
+			Where("published_at IS NULL AND failed_at IS NULL AND (claimed_until > ? OR next_attempt_at > ?)", now, now)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("published_at IS NULL AND failed_at IS NULL AND product_id NOT IN (?)", waiting).
+			Order("id").Limit(r.opts.BatchSize).Find(&claimed).Error; err != nil {
+			return fmt.Errorf("error loading outbox: %v", err)
+		}
+
+		if len(claimed) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(claimed))
+		for i, e := range claimed {
+			ids[i] = e.ID
+		}
+		if err := tx.Model(&schemas.OutboxEvent{}).Where("id IN ?", ids).
+			Update("claimed_until", now.Add(r.opts.Lease)).Error; err != nil {
+			return fmt.Errorf("error claiming outbox events: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// DeletePublished removes events published before the cutoff.
+func (r *Relay) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	res := r.db.WithContext(tenant.WithoutScope(ctx)).
+		Where("published_at < ?", before).
+		Delete(&schemas.OutboxEvent{})
+	return res.RowsAffected, res.Error
+}
+
+func message(e schemas.OutboxEvent) Message {
+	return Message{
+		ID:      e.EventID,
+		Type:    e.Type,
+		Key:     strconv.FormatUint(uint64(e.ProductID), 10),
+		Payload: []byte(e.Payload),
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > 1024 {
+		return s[:1024]
+	}
+	return s
+}
+
+// RunCleanup deletes events published more than retention ago, every
+// interval until ctx is done.
+func (r *Relay) RunCleanup(ctx context.Context, interval, retention time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.DeletePublished(ctx, r.now().Add(-retention)); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
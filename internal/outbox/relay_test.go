@@ -0,0 +1,146 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+)
+
+func newRelay(t *testing.T, broker Broker) (*Relay, sqlmock.Sqlmock, time.Time) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	r := NewRelay(gdb, broker, Options{BatchSize: 10, Lease: time.Minute, MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: time.Minute})
+	r.now = func() time.Time { return now }
+	return r, mock, now
+}
+
+// pending espera a busca dos eventos pendentes, fora os produtos reservados
+// ou aguardando nova tentativa, e a reserva dos ids em claimed.
+func pending(mock sqlmock.Sqlmock, rows *sqlmock.Rows, now time.Time, claimed ...driver.Value) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `outbox_events` WHERE published_at IS NULL AND failed_at IS NULL AND product_id NOT IN "+
+		"(SELECT `product_id` FROM `outbox_events` WHERE published_at IS NULL AND failed_at IS NULL AND (claimed_until > ? OR next_attempt_at > ?)) ORDER BY id LIMIT ? FOR UPDATE")).
+		WithArgs(now, now, 10).
+		WillReturnRows(rows)
+	if len(claimed) > 0 {
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `claimed_until`=? WHERE id IN (?" + strings.Repeat(",?", len(claimed)-1) + ")")).
+			WithArgs(append([]driver.Value{now.Add(time.Minute)}, claimed...)...).
+			WillReturnResult(sqlmock.NewResult(0, int64(len(claimed))))
+	}
+	mock.ExpectCommit()
+}
+
+var outboxCols = []string{"id", "event_id", "type", "product_id", "payload", "attempts"}
+
+func TestRelay(t *testing.T) {
+	t.Run("publica em ordem e marca como publicado", func(t *testing.T) {
+		bus := NewMemoryBus()
+		var got []Message
+		bus.Subscribe(func(_ context.Context, m Message) error {
+			got = append(got, m)
+			return nil
+		})
+
+		r, mock, now := newRelay(t, bus)
+		pending(mock, sqlmock.NewRows(outboxCols).
+			AddRow(1, "ev-1", "product.created", 7, `{"id":"ev-1"}`, 0).
+			AddRow(2, "ev-2", "stock.changed", 7, `{"id":"ev-2"}`, 0), now, 1, 2)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `claimed_until`=?,`published_at`=? WHERE id IN (?,?)")).
+			WithArgs(nil, now, 1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		n, err := r.PublishPending(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.Equal(t, []Message{
+			{ID: "ev-1", Type: "product.created", Key: "7", Payload: []byte(`{"id":"ev-1"}`)},
+			{ID: "ev-2", Type: "stock.changed", Key: "7", Payload: []byte(`{"id":"ev-2"}`)},
+		}, got)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("falha segura os eventos seguintes do mesmo produto", func(t *testing.T) {
+		bus := NewMemoryBus()
+		var got []string
+		bus.Subscribe(func(_ context.Context, m Message) error {
+			if m.ID == "ev-1" {
+				return errors.New("broker fora do ar")
+			}
+			got = append(got, m.ID)
+			return nil
+		})
+
+		r, mock, now := newRelay(t, bus)
+		pending(mock, sqlmock.NewRows(outboxCols).
+			AddRow(1, "ev-1", "product.updated", 7, `{}`, 2).
+			AddRow(2, "ev-2", "product.updated", 8, `{}`, 0).
+			AddRow(3, "ev-3", "stock.changed", 7, `{}`, 0), now, 1, 2, 3)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `attempts`=?,`claimed_until`=?,`last_error`=?,`next_attempt_at`=? WHERE `id` = ?")).
+			WithArgs(3, nil, "broker fora do ar", now.Add(4*time.Second), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `claimed_until`=? WHERE id IN (?)")).
+			WithArgs(nil, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `claimed_until`=?,`published_at`=? WHERE id IN (?)")).
+			WithArgs(nil, now, 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		n, err := r.PublishPending(context.Background())
+		require.ErrorContains(t, err, "error publishing event ev-1: broker fora do ar")
+		require.Equal(t, 1, n)
+		require.Equal(t, []string{"ev-2"}, got)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("põe de lado o evento após o máximo de tentativas", func(t *testing.T) {
+		bus := NewMemoryBus()
+		bus.Subscribe(func(context.Context, Message) error {
+			return errors.New("payload rejeitado")
+		})
+
+		r, mock, now := newRelay(t, bus)
+		pending(mock, sqlmock.NewRows(outboxCols).
+			AddRow(1, "ev-1", "product.updated", 7, `{}`, 4), now, 1)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `outbox_events` SET `attempts`=?,`claimed_until`=?,`failed_at`=?,`last_error`=? WHERE `id` = ?")).
+			WithArgs(5, nil, now, "payload rejeitado", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		n, err := r.PublishPending(context.Background())
+		require.ErrorContains(t, err, "gave up event ev-1 after 5 attempts")
+		require.Zero(t, n)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("sem eventos pendentes não marca nada", func(t *testing.T) {
+		r, mock, now := newRelay(t, NewMemoryBus())
+		pending(mock, sqlmock.NewRows(outboxCols), now)
+
+		n, err := r.PublishPending(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, n)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
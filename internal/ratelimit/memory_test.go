@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	t.Run("usa o número de requisições como burst", func(t *testing.T) {
+		l, err := ParseLimit("600/m")
+		require.NoError(t, err)
+		require.Equal(t, 600, l.Burst)
+		require.Equal(t, float64(10), l.Rate)
+		require.Equal(t, time.Minute, l.Period)
+	})
+
+	t.Run("aceita burst explícito", func(t *testing.T) {
+		l, err := ParseLimit("30/m:10")
+		require.NoError(t, err)
+		require.Equal(t, 10, l.Burst)
+		require.Equal(t, 30, l.Requests)
+	})
+
+	for _, v := range []string{"", "10", "0/s", "10/d", "10/s:0", "abc/m"} {
+		_, err := ParseLimit(v)
+		require.Error(t, err, v)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+	limit, err := ParseLimit("60/m:2")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("consome o burst e depois nega", func(t *testing.T) {
+		res, _ := s.Take(ctx, "k", limit)
+		require.True(t, res.Allowed)
+		require.Equal(t, 1, res.Remaining)
+
+		res, _ = s.Take(ctx, "k", limit)
+		require.True(t, res.Allowed)
+		require.Equal(t, 0, res.Remaining)
+
+		res, _ = s.Take(ctx, "k", limit)
+		require.False(t, res.Allowed)
+		require.Equal(t, time.Second, res.RetryAfter)
+	})
+
+	t.Run("buckets são independentes por chave", func(t *testing.T) {
+		res, _ := s.Take(ctx, "outra", limit)
+		require.True(t, res.Allowed)
+	})
+
+	t.Run("reabastece com o tempo", func(t *testing.T) {
+		now = now.Add(time.Second)
+		res, _ := s.Take(ctx, "k", limit)
+		require.True(t, res.Allowed)
+	})
+
+	t.Run("cleanup remove buckets ociosos", func(t *testing.T) {
+		now = now.Add(2 * time.Hour)
+		s.Cleanup()
+		require.Empty(t, s.buckets)
+	})
+}
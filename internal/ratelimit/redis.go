@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript runs the same arithmetic as refill/result atomically on
+// the Redis side. State lives in a hash {tokens, last} that expires once the
+// bucket would be full again.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1])
+local last = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+  last = now
+end
+
+local elapsed = now - last
+if elapsed > 0 then
+  tokens = math.min(burst, tokens + elapsed * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "last", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore shares buckets between replicas through any server speaking the
+// Redis protocol.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := float64(time.Now().UnixMicro()) / 1e6
+
+	res, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Rate, limit.Burst, strconv.FormatFloat(now, 'f', 6, 64)).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	allowed, _ := res[0].(int64)
+	remaining, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(remaining, 64)
+	if err != nil || math.IsNaN(tokens) {
+		tokens = 0
+	}
+
+	return result(tokens, allowed == 1, limit), nil
+}
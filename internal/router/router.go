@@ -1,26 +1,338 @@
 package router
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"time"
 
-	"github.com/gin-contrib/cors"
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/health"
+	"github.com/alissonmunhoz/go-crud-products/internal/idempotency"
+	"github.com/alissonmunhoz/go-crud-products/internal/metrics"
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/outbox"
+	"github.com/alissonmunhoz/go-crud-products/internal/ratelimit"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/alissonmunhoz/go-crud-products/internal/stockalert"
+	"github.com/alissonmunhoz/go-crud-products/internal/webhook"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func Initialize() {
+// Initialize builds the router and serves it until ctx is cancelled. It
+// returns only after the background workers have stopped, so the caller can
+// close the database.
+func Initialize(ctx context.Context) error {
 
-	router := gin.Default()
-
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+	router := gin.New()
+	// o IP do cliente identifica quem passa pelo rate limit e pela auditoria
+	if err := router.SetTrustedProxies(config.GetServer().TrustedProxies); err != nil {
+		return fmt.Errorf("error configuring trusted proxies: %v", err)
+	}
+	router.Use(gin.CustomRecovery(func(ctx *gin.Context, err any) {
+		config.GetLogger("http").WithContext(ctx.Request.Context()).Errorf("panic recovered: %v", err)
+		ctx.AbortWithStatus(http.StatusInternalServerError)
 	}))
+	// o span da requisição continua o trace recebido nos headers W3C (traceparent)
+	router.Use(otelgin.Middleware(config.GetTracing().ServiceName))
+	router.Use(middleware.RequestID(), middleware.Logging(), middleware.Metrics())
+
+	router.Use(middleware.CORS(config.GetCORS()))
+
+	checks := newHealthChecks()
+	// os workers param junto com o servidor, ou se a inicialização falha depois
+	// de eles subirem
+	defer func() {
+		if err := checks.Wait(config.GetServer().ShutdownTimeout); err != nil {
+			config.GetLogger("server").Errorf("%v", err)
+		}
+	}()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	router.GET("/healthz", health.Liveness)
+	router.GET("/readyz", checks.Readiness)
+
+	authn, err := newAuthenticator(config.GetAuth())
+	if err != nil {
+		return fmt.Errorf("error initializing authentication: %v", err)
+	}
+
+	if err := metrics.RegisterDB(config.GetMySQL()); err != nil {
+		return fmt.Errorf("error registering database metrics: %v", err)
+	}
+	// as métricas cobrem todos os tenants: ou ficam num listener à parte,
+	// fora do alcance dos clientes, ou exigem o papel platform
+	if cfg := config.GetMetrics(); cfg.Addr != "" {
+		if err := startMetricsServer(ctx, checks, cfg.Addr); err != nil {
+			return fmt.Errorf("error starting metrics server: %v", err)
+		}
+	} else {
+		router.GET("/metrics", authn, middleware.RequirePermission(auth.PlatformAdmin), gin.WrapH(metrics.Handler()))
+	}
+
+	ipLimiter, limiter, err := newRateLimiters(ctx, checks, config.GetRateLimit())
+	if err != nil {
+		return fmt.Errorf("error initializing rate limiter: %v", err)
+	}
+
+	idem := newIdempotency(ctx, checks, config.GetIdempotency())
+
+	index, err := newSearchIndex(ctx, config.GetSearch())
+	if err != nil {
+		return fmt.Errorf("error initializing search index: %v", err)
+	}
+	facets, err := search.NewFacetSpec(config.GetSearch().Facets, config.GetSearch().PriceBuckets)
+	if err != nil {
+		return fmt.Errorf("search.facets (SEARCH_FACETS): %v", err)
+	}
+
+	names := search.NewSuggester()
+	if err := names.Load(ctx, config.GetMySQL()); err != nil {
+		return fmt.Errorf("error loading product name suggestions: %v", err)
+	}
+	startSearchRefresh(ctx, checks, config.GetSearch(), index, names)
+
+	store, err := config.GetMedia().NewStorage()
+	if err != nil {
+		return fmt.Errorf("error initializing media storage: %v", err)
+	}
+
+	alerts := newStockAlerts(ctx, checks, config.GetAlerts())
+	startWebhooks(ctx, checks, config.GetWebhooks())
+	startOutboxRelay(ctx, checks, config.GetOutbox())
+
+	InitializeRoutes(router, ipLimiter, authn, limiter, idem, index, names, facets, store, alerts)
+
+	cfg := config.GetServer()
+
+	srv, err := newServer(cfg, router)
+	if err != nil {
+		return fmt.Errorf("error configuring http server: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %v", cfg.Addr, err)
+	}
+
+	return serve(ctx, srv, ln, cfg, checks.SetDraining)
+}
+
+func newHealthChecks() *health.Registry {
+	checks := health.NewRegistry(2 * time.Second)
+
+	checks.Register("mysql", func(ctx context.Context) error {
+		sqlDB, err := config.GetMySQL().DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	})
+	checks.Register("migrations", func(context.Context) error {
+		if !config.Migrated() {
+			return errors.New("migrations have not been applied")
+		}
+		return nil
+	})
+
+	return checks
+}
+
+// startMetricsServer serves only the metrics on addr until ctx is cancelled.
+func startMetricsServer(ctx context.Context, checks *health.Registry, addr string) error {
+	logger := config.GetLogger("metrics")
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: metrics.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	checks.Go("metrics-server", func() {
+		stop := context.AfterFunc(ctx, func() { srv.Close() })
+		defer stop()
+
+		logger.Infof("serving metrics on %s", ln.Addr())
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("metrics server error: %v", err)
+		}
+	})
+	return nil
+}
+
+// com a autenticação desligada todas as rotas ficam abertas, com todas as permissões
+func newAuthenticator(cfg config.AuthConfig) (gin.HandlerFunc, error) {
+	if !cfg.Enabled {
+		return middleware.Unauthenticated(), nil
+	}
+
+	verifier, err := auth.NewVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := auth.NewAPIKeyAuthenticator(config.GetMySQL())
+
+	return middleware.Authenticate(verifier, keys, cfg.PublicPaths), nil
+}
+
+// the first limiter goes by IP before authentication, the second by client
+// after it; both share the store
+func newRateLimiters(ctx context.Context, checks *health.Registry, cfg config.RateLimitConfig) (gin.HandlerFunc, gin.HandlerFunc, error) {
+	if !cfg.Enabled {
+		pass := func(ctx *gin.Context) { ctx.Next() }
+		return pass, pass, nil
+	}
+
+	var store ratelimit.Store
+	switch cfg.Store {
+	case "redis":
+		client := redis.NewClient(&redis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
+		if err := client.Ping(ctx).Err(); err != nil {
+			return nil, nil, fmt.Errorf("error connecting to redis at %s: %v", cfg.RedisAddr, err)
+		}
+		store = ratelimit.NewRedisStore(client, "ratelimit:")
+	default:
+		memory := ratelimit.NewMemoryStore()
+		checks.Go("ratelimit-cleanup", func() { memory.RunCleanup(ctx, 10*time.Minute) })
+		store = memory
+	}
+
+	return middleware.IPRateLimit(cfg.PerIP, store), middleware.RateLimit(cfg, store), nil
+}
+
+func newIdempotency(ctx context.Context, checks *health.Registry, cfg config.IdempotencyConfig) gin.HandlerFunc {
+	logger := config.GetLogger("idempotency")
+
+	store := idempotency.NewStore(config.GetMySQL(), cfg.TTL, cfg.Lease)
+	checks.Go("idempotency-cleanup", func() {
+		store.RunCleanup(ctx, time.Hour, func(err error) {
+			logger.Errorf("idempotency cleanup error: %v", err)
+		})
+	})
+
+	return middleware.Idempotency(store)
+}
+
+func newStockAlerts(ctx context.Context, checks *health.Registry, cfg config.AlertsConfig) *stockalert.Evaluator {
+	logger := config.GetLogger("stock-alerts")
+
+	alerts := stockalert.NewEvaluator(cfg.QueueSize, newNotifiers(cfg, logger)...)
+	checks.Go("stock-alerts", func() {
+		alerts.Run(ctx, func(err error) {
+			logger.Errorf("%v", err)
+		})
+	})
+	return alerts
+}
+
+// newNotifiers builds the notifiers listed in cfg.Notifiers; the log one
+// writes to logger.
+func newNotifiers(cfg config.AlertsConfig, logger *config.Logger) []stockalert.Notifier {
+	var notifiers []stockalert.Notifier
+	for _, n := range cfg.Notifiers {
+		switch n {
+		case "log":
+			notifiers = append(notifiers, stockalert.NewLogNotifier(logger.Warnf))
+		case "webhook":
+			notifiers = append(notifiers, stockalert.NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookTimeout))
+		case "smtp":
+			notifiers = append(notifiers, stockalert.NewSMTPNotifier(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPFrom, cfg.SMTPTo, cfg.SMTPTimeout))
+		}
+	}
+	return notifiers
+}
+
+// deliveries are queued by the handlers; this only sends them
+func startWebhooks(ctx context.Context, checks *health.Registry, cfg config.WebhooksConfig) {
+	logger := config.GetLogger("webhooks")
+
+	dispatcher := webhook.NewDispatcher(config.GetMySQL(), webhook.Options{
+		Timeout:             cfg.Timeout,
+		MaxAttempts:         cfg.MaxAttempts,
+		InitialBackoff:      cfg.InitialBackoff,
+		MaxBackoff:          cfg.MaxBackoff,
+		BatchSize:           cfg.BatchSize,
+		Concurrency:         cfg.Concurrency,
+		AllowPrivateTargets: cfg.AllowPrivateTargets,
+	})
+	checks.Go("webhooks", func() {
+		dispatcher.Run(ctx, cfg.PollInterval, func(err error) {
+			logger.Errorf("webhook delivery error: %v", err)
+		})
+	})
+}
+
+func startOutboxRelay(ctx context.Context, checks *health.Registry, cfg config.OutboxConfig) {
+	logger := config.GetLogger("outbox")
+
+	relay := outbox.NewRelay(config.GetMySQL(), newOutboxBroker(cfg, logger), outbox.Options{
+		BatchSize:      cfg.BatchSize,
+		Lease:          cfg.Lease,
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+	})
+	checks.Go("outbox-relay", func() {
+		relay.Run(ctx, cfg.PollInterval, func(err error) {
+			logger.Errorf("outbox relay error: %v", err)
+		})
+	})
+	checks.Go("outbox-cleanup", func() {
+		relay.RunCleanup(ctx, time.Hour, cfg.Retention, func(err error) {
+			logger.Errorf("outbox cleanup error: %v", err)
+		})
+	})
+}
+
+// nothing in this process subscribes to the memory bus, so with it events
+// are marked published and go nowhere; say so loudly
+func newOutboxBroker(cfg config.OutboxConfig, logger *config.Logger) outbox.Broker {
+	if cfg.Broker == "kafka-rest" {
+		return outbox.NewKafkaRESTBroker(cfg.KafkaRESTURL, cfg.Topic, cfg.Timeout)
+	}
+
+	logger.Warnf("OUTBOX_BROKER=memory: events are not sent to any broker; set OUTBOX_BROKER=kafka-rest to deliver them")
+	bus := outbox.NewMemoryBus()
+	bus.OnUnheard(func(m outbox.Message) {
+		logger.Warnf("outbox event %s (%s) dropped: the memory broker has no subscribers", m.ID, m.Type)
+	})
+	return bus
+}
+
+// the in-process indexes are only told about the writes of this replica;
+// rebuilding them from time to time brings in those of the others
+func startSearchRefresh(ctx context.Context, checks *health.Registry, cfg config.SearchConfig, index search.Index, names *search.Suggester) {
+	if cfg.RefreshInterval == 0 {
+		return
+	}
+	logger := config.GetLogger("search")
+
+	indexes := []search.Reloader{names}
+	if reloader, ok := index.(search.Reloader); ok {
+		indexes = append(indexes, reloader)
+	}
+	checks.Go("search-refresh", func() {
+		search.Refresh(ctx, config.GetMySQL(), cfg.RefreshInterval, func(err error) {
+			logger.Errorf("search index refresh error: %v", err)
+		}, indexes...)
+	})
+}
 
-	InitializeRoutes(router)
+// the memory index starts from what is already in the database
+func newSearchIndex(ctx context.Context, cfg config.SearchConfig) (search.Index, error) {
+	if cfg.Backend != "memory" {
+		return search.NewMySQLIndex(config.GetMySQL()), nil
+	}
 
-	router.Run(":8080")
+	index := search.NewMemoryIndex()
+	if err := index.Load(ctx, config.GetMySQL()); err != nil {
+		return nil, err
+	}
+	return index, nil
 }
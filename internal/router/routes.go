@@ -2,25 +2,94 @@ package router
 
 import (
 	_ "github.com/alissonmunhoz/go-crud-products/docs"
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
 	service "github.com/alissonmunhoz/go-crud-products/internal/service"
+	"github.com/alissonmunhoz/go-crud-products/internal/stockalert"
 
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func InitializeRoutes(router *gin.Engine) {
-	service.InitializeHandler()
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+func InitializeRoutes(router *gin.Engine, ipLimiter, authn, limiter, idem gin.HandlerFunc, index search.Index, names *search.Suggester, facets search.FacetSpec, store media.Storage, alerts *stockalert.Evaluator) {
+	service.InitializeHandler(index, names, facets, store, alerts)
+	router.GET("/swagger/*any", authn, ginSwagger.WrapHandler(swaggerfiles.Handler))
+
+	read := middleware.RequirePermission(auth.ProductRead)
+
+	// o storage local exige as mesmas credenciais da API e só entrega
+	// arquivos do tenant de quem pede
+	if cfg := config.GetMedia(); cfg.Storage == "local" {
+		router.GET(cfg.BaseURL+"/*key", ipLimiter, authn, middleware.Tenant(config.GetTenancy()), limiter, read, service.ServeProductMediaService)
+	}
 
 	v1 := router.Group("/v1")
+	// o limite por IP vem antes de qualquer trabalho com banco
+	v1.Use(ipLimiter, middleware.Audit(config.GetMySQL()), authn, middleware.Tenant(config.GetTenancy()), limiter)
 
 	{
-		v1.POST("/product", service.CreateProductService)
-		v1.DELETE("/product", service.DeleteProductService)
-		v1.PUT("/product", service.UpdateProductService)
-		v1.GET("/products", service.FindAllProductsService)
-		v1.GET("/product", service.FindProductService)
+
+		v1.POST("/product", middleware.RequirePermission(auth.ProductWrite), idem, service.CreateProductService)
+		v1.DELETE("/product", middleware.RequirePermission(auth.ProductDelete), service.DeleteProductService)
+		// permissões por campo são conferidas no handler
+		v1.PUT("/product", middleware.RequireAnyPermission(auth.ProductWrite, auth.PriceWrite, auth.StockAdjust), service.UpdateProductService)
+		v1.GET("/products", read, service.FindAllProductsService)
+		v1.GET("/products/search", read, service.SearchProductsService)
+		v1.GET("/products/suggest", read, service.SuggestProductsService)
+		v1.GET("/products/low-stock", read, service.FindLowStockProductsService)
+		v1.GET("/product", read, service.FindProductService)
+		v1.GET("/product/revisions", read, service.FindProductRevisionsService)
+		v1.GET("/product/revisions/diff", read, service.DiffProductRevisionsService)
+		v1.POST("/product/rollback", middleware.RequirePermission(auth.ProductWrite, auth.PriceWrite, auth.StockAdjust), idem, service.RollbackProductService)
+		v1.POST("/product/media", middleware.RequirePermission(auth.ProductWrite), service.CreateProductMediaService)
+		v1.GET("/product/media", read, service.FindProductMediaService)
+		v1.PUT("/product/media/order", middleware.RequirePermission(auth.ProductWrite), service.ReorderProductMediaService)
+		v1.DELETE("/product/media", middleware.RequirePermission(auth.ProductWrite), service.DeleteProductMediaService)
+		v1.GET("/product/suppliers", middleware.RequirePermission(auth.CostRead), service.FindProductSuppliersService)
+		v1.PUT("/product/suppliers", middleware.RequirePermission(auth.SupplierManage), service.SetProductSupplierService)
+		v1.DELETE("/product/suppliers", middleware.RequirePermission(auth.SupplierManage), service.DeleteProductSupplierService)
+		v1.GET("/product/stock", read, service.FindStockMovementsService)
+		v1.GET("/attributes", read, service.FindAllAttributeDefinitionsService)
+		v1.POST("/attributes", middleware.RequirePermission(auth.ProductWrite), service.CreateAttributeDefinitionService)
+		v1.DELETE("/attributes", middleware.RequirePermission(auth.ProductWrite), service.DeleteAttributeDefinitionService)
+		v1.GET("/brands", read, service.FindAllBrandsService)
+		v1.POST("/brands", middleware.RequirePermission(auth.ProductWrite), service.CreateBrandService)
+		v1.PUT("/brands", middleware.RequirePermission(auth.ProductWrite), service.UpdateBrandService)
+		v1.DELETE("/brands", middleware.RequirePermission(auth.ProductWrite), service.DeleteBrandService)
+		v1.GET("/suppliers", middleware.RequireAnyPermission(auth.SupplierManage, auth.CostRead), service.FindAllSuppliersService)
+		v1.POST("/suppliers", middleware.RequirePermission(auth.SupplierManage), service.CreateSupplierService)
+		v1.PUT("/suppliers", middleware.RequirePermission(auth.SupplierManage), service.UpdateSupplierService)
+		v1.DELETE("/suppliers", middleware.RequirePermission(auth.SupplierManage), service.DeleteSupplierService)
+		orders := middleware.RequireAnyPermission(auth.SupplierManage, auth.StockAdjust)
+		v1.GET("/purchase-orders", orders, service.FindAllPurchaseOrdersService)
+		v1.GET("/purchase-order", orders, service.FindPurchaseOrderService)
+		v1.POST("/purchase-order", middleware.RequirePermission(auth.SupplierManage), service.CreatePurchaseOrderService)
+		v1.PUT("/purchase-order", middleware.RequirePermission(auth.SupplierManage), service.UpdatePurchaseOrderService)
+		v1.POST("/purchase-order/send", middleware.RequirePermission(auth.SupplierManage), service.SendPurchaseOrderService)
+		v1.POST("/purchase-order/cancel", middleware.RequirePermission(auth.SupplierManage), service.CancelPurchaseOrderService)
+		// quem recebe a mercadoria é o estoque, não compras
+		v1.POST("/purchase-order/receive", middleware.RequirePermission(auth.StockAdjust), idem, service.ReceivePurchaseOrderService)
+		v1.GET("/audit", middleware.RequirePermission(auth.AuditRead), service.FindAuditEntriesService)
+		// a cadeia cobre todos os tenants
+		v1.GET("/audit/verify", middleware.RequirePermission(auth.PlatformAdmin), service.VerifyAuditChainService)
+
+		keys := v1.Group("/apikeys", middleware.RequirePermission(auth.APIKeyManage))
+		keys.POST("", service.CreateAPIKeyService)
+		keys.GET("", service.FindAllAPIKeysService)
+		keys.POST("/rotate", service.RotateAPIKeyService)
+		keys.DELETE("", service.RevokeAPIKeyService)
+
+		hooks := v1.Group("/webhooks", middleware.RequirePermission(auth.WebhookManage))
+		hooks.POST("", service.CreateWebhookService)
+		hooks.GET("", service.FindAllWebhooksService)
+		hooks.PUT("", service.UpdateWebhookService)
+		hooks.DELETE("", service.DeleteWebhookService)
+		hooks.GET("/deliveries", service.FindWebhookDeliveriesService)
+		hooks.POST("/deliveries/redeliver", service.RedeliverWebhookService)
 	}
 
 }
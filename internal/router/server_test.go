@@ -0,0 +1,72 @@
+package router
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+)
+
+func TestServe(t *testing.T) {
+	cfg := config.ServerConfig{
+		ReadTimeout:     time.Second,
+		WriteTimeout:    time.Second,
+		ShutdownTimeout: 2 * time.Second,
+		MaxHeaderBytes:  1 << 20,
+	}
+
+	t.Run("drena requisições em andamento no shutdown", func(t *testing.T) {
+		started := make(chan struct{})
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			time.Sleep(200 * time.Millisecond)
+			_, _ = io.WriteString(w, "ok")
+		})
+
+		srv, err := newServer(cfg, handler)
+		require.NoError(t, err)
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		drained := false
+		go func() { done <- serve(ctx, srv, ln, cfg, func() { drained = true }) }()
+
+		type result struct {
+			body string
+			err  error
+		}
+		res := make(chan result, 1)
+		go func() {
+			resp, err := http.Get("http://" + ln.Addr().String())
+			if err != nil {
+				res <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+			b, err := io.ReadAll(resp.Body)
+			res <- result{string(b), err}
+		}()
+
+		<-started
+		cancel()
+
+		r := <-res
+		require.NoError(t, r.err)
+		require.Equal(t, "ok", r.body)
+		require.NoError(t, <-done)
+		require.True(t, drained)
+	})
+
+	t.Run("falha com CA de clientes inexistente", func(t *testing.T) {
+		_, err := newServer(config.ServerConfig{TLSCertFile: "cert.pem", TLSKeyFile: "key.pem", TLSClientCAFile: "/nao/existe.pem"}, http.NotFoundHandler())
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,41 @@
+package schemas
+
+import (
+	"time"
+)
+
+// APIKey authenticates machine clients through the X-API-Key header. Only a
+// hash of the secret is stored; the secret itself is shown once on creation
+// and rotation.
+type APIKey struct {
+	ID         uint   `gorm:"primarykey"`
+	TenantID   string `gorm:"size:64;not null;default:default;index"`
+	Name       string `gorm:"size:255;not null"`
+	Prefix     string `gorm:"size:32;uniqueIndex;not null"`
+	Hash       string `gorm:"size:64;not null"`
+	Scopes     string `gorm:"size:1024"`
+	CreatedBy  string `gorm:"size:255"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type APIKeyResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  string     `json:"createdBy"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// APIKeySecretResponse is only returned when a secret is issued.
+type APIKeySecretResponse struct {
+	APIKeyResponse
+	Secret string `json:"secret"`
+}
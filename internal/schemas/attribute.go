@@ -0,0 +1,104 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	AttributeString  = "string"
+	AttributeNumber  = "number"
+	AttributeEnum    = "enum"
+	AttributeBoolean = "boolean"
+	// AttributeUnit is a number measured in the Unit of its definition
+	AttributeUnit = "unit"
+)
+
+func IsAttributeType(t string) bool {
+	switch t {
+	case AttributeString, AttributeNumber, AttributeEnum, AttributeBoolean, AttributeUnit:
+		return true
+	}
+	return false
+}
+
+// AttributeDefinition declares a custom field for the products of a
+// category, such as the voltage of appliances or the ISBN of books.
+type AttributeDefinition struct {
+	ID       uint   `gorm:"primarykey"`
+	TenantID string `gorm:"size:64;not null;default:default;index;uniqueIndex:idx_attribute_definitions_key"`
+	Category string `gorm:"size:64;not null;uniqueIndex:idx_attribute_definitions_key"`
+	Key      string `gorm:"size:64;not null;uniqueIndex:idx_attribute_definitions_key"`
+	Label    string `gorm:"size:255"`
+	Type     string `gorm:"size:16;not null"`
+	Required bool
+	// Options are the values allowed for an enum, comma separated
+	Options   string `gorm:"size:1024"`
+	Unit      string `gorm:"size:16"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// AttributeKey exists once per attribute key of a tenant. Locking it
+// serialises definitions of the key, so every category keeps one type and
+// unit.
+type AttributeKey struct {
+	ID       uint   `gorm:"primarykey"`
+	TenantID string `gorm:"size:64;not null;default:default;uniqueIndex:idx_attribute_keys_key"`
+	Key      string `gorm:"size:64;not null;uniqueIndex:idx_attribute_keys_key"`
+}
+
+// Numeric reports whether values of the attribute compare as numbers.
+func (d AttributeDefinition) Numeric() bool {
+	return d.Type == AttributeNumber || d.Type == AttributeUnit
+}
+
+type AttributeDefinitionResponse struct {
+	ID        uint      `json:"id"`
+	Category  string    `json:"category"`
+	Key       string    `json:"key"`
+	Label     string    `json:"label,omitempty"`
+	Type      string    `json:"type"`
+	Required  bool      `json:"required"`
+	Options   []string  `json:"options,omitempty"`
+	Unit      string    `json:"unit,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Attributes are the custom attribute values of a product, stored as a JSON
+// column: strings, numbers (float64) and booleans by attribute key.
+type Attributes map[string]any
+
+func (a Attributes) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Attributes) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("schemas: cannot scan %T into Attributes", src)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*a = m
+	return nil
+}
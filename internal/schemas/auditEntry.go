@@ -0,0 +1,59 @@
+package schemas
+
+import (
+	"time"
+)
+
+// AuditEntry records one mutation against the API. Rows are only ever
+// inserted; each one carries the hash of its predecessor so that editing or
+// removing a row breaks the chain.
+type AuditEntry struct {
+	ID        uint   `gorm:"primarykey"`
+	TenantID  string `gorm:"size:64;not null;default:default;index"`
+	Actor     string `gorm:"size:255;index"`
+	IP        string `gorm:"size:64"`
+	RequestID string `gorm:"size:64;index"`
+	Method    string `gorm:"size:8;index"`
+	Route     string `gorm:"size:255"`
+	Path      string `gorm:"size:2048"`
+	Status    int    `gorm:"index"`
+	LatencyMs int64
+	PrevHash  string    `gorm:"size:64"`
+	Hash      string    `gorm:"size:64;uniqueIndex"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// AuditChainHead holds the hash of the newest audit entry. Locking this single
+// row serialises appends across replicas.
+type AuditChainHead struct {
+	ID   uint   `gorm:"primarykey"`
+	Hash string `gorm:"size:64"`
+}
+
+type AuditEntryResponse struct {
+	ID        uint      `json:"id"`
+	TenantID  string    `json:"tenantId"`
+	Actor     string    `json:"actor"`
+	IP        string    `json:"ip"`
+	RequestID string    `json:"requestId"`
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Path      string    `json:"path"`
+	Status    int       `json:"status"`
+	LatencyMs int64     `json:"latencyMs"`
+	PrevHash  string    `json:"prevHash"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Pagination struct {
+	Page     int   `json:"page"`
+	PageSize int   `json:"pageSize"`
+	Total    int64 `json:"total"`
+}
+
+type AuditVerifyResponse struct {
+	Valid    bool `json:"valid"`
+	Checked  int  `json:"checked"`
+	BrokenAt uint `json:"brokenAt,omitempty"`
+}
@@ -0,0 +1,23 @@
+package schemas
+
+import "time"
+
+// Brand is a manufacturer or label products can be linked to. Linked
+// products keep its name in their own brand column, so filters and facets
+// work the same for linked and free text brands.
+type Brand struct {
+	ID        uint   `gorm:"primarykey"`
+	TenantID  string `gorm:"size:64;not null;default:default;index;uniqueIndex:idx_brands_name"`
+	Name      string `gorm:"size:64;not null;uniqueIndex:idx_brands_name"`
+	Website   string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type BrandResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Website   string    `json:"website,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,40 @@
+package schemas
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event about a product, as published to the broker and
+// sent to webhooks. ID is the same everywhere the event goes, so consumers
+// can drop the duplicates at-least-once delivery brings.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	TenantID   string          `json:"tenantId,omitempty"`
+	ProductID  uint            `json:"productId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// OutboxEvent is an event written in the same transaction as the change it
+// describes, waiting for the relay to publish it. Payload is the encoded
+// Event, published byte for byte.
+type OutboxEvent struct {
+	ID        uint   `gorm:"primarykey"`
+	TenantID  string `gorm:"size:64;not null;default:default;index"`
+	EventID   string `gorm:"size:64;not null;uniqueIndex"`
+	Type      string `gorm:"size:32;not null"`
+	ProductID uint   `gorm:"not null;index"`
+	Payload   string `gorm:"type:text;not null"`
+	Attempts  int    `gorm:"not null;default:0"`
+	LastError string `gorm:"size:1024"`
+	// set while a relay is publishing the event
+	ClaimedUntil *time.Time
+	// set after a failure, when the event may be tried again
+	NextAttemptAt *time.Time
+	PublishedAt   *time.Time `gorm:"index"`
+	// set when the event was given up after too many failures
+	FailedAt  *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,24 @@
+package schemas
+
+import (
+	"time"
+)
+
+// IdempotencyKey remembers the response given to a request carrying an
+// Idempotency-Key header so retries get the same answer. Status 0 means the
+// first request is still being processed, until LockedUntil passes.
+type IdempotencyKey struct {
+	ID       uint   `gorm:"primarykey"`
+	TenantID string `gorm:"size:64;not null;default:default;uniqueIndex:idx_idempotency_tenant_key"`
+	// sha256 of client, route and the key sent by the client
+	KeyHash     string `gorm:"size:64;not null;uniqueIndex:idx_idempotency_tenant_key"`
+	RequestHash string `gorm:"size:64;not null"`
+	Status      int
+	ContentType string `gorm:"size:255"`
+	Body        []byte `gorm:"type:mediumblob"`
+	// prazo do processamento em andamento; depois dele a chave pode ser retomada
+	LockedUntil *time.Time
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
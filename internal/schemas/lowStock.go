@@ -0,0 +1,16 @@
+package schemas
+
+// LowStockProductResponse is a line of the low stock report: a product at or
+// below its reorder point, what is already on order for it and how much more
+// to order to reach its reorder quantity.
+type LowStockProductResponse struct {
+	ID              uint   `json:"id"`
+	SKU             string `json:"sku,omitempty"`
+	Name            string `json:"name"`
+	Quantity        int32  `json:"quantity"`
+	ReorderPoint    int32  `json:"reorderPoint"`
+	ReorderQuantity int32  `json:"reorderQuantity"`
+	// units still outstanding on sent or partially received purchase orders
+	OnOrder        int64 `json:"onOrder"`
+	SuggestedOrder int64 `json:"suggestedOrder"`
+}
@@ -6,21 +6,92 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	ProductStatusActive   = "active"
+	ProductStatusDraft    = "draft"
+	ProductStatusArchived = "archived"
+)
+
+func IsProductStatus(s string) bool {
+	return s == ProductStatusActive || s == ProductStatusDraft || s == ProductStatusArchived
+}
+
 type Product struct {
 	gorm.Model
-	Name        string
+	TenantID    string  `gorm:"size:64;not null;default:default;index;uniqueIndex:idx_products_tenant_sku"`
+	SKU         *string `gorm:"size:64;uniqueIndex:idx_products_tenant_sku"`
+	Name        string  `gorm:"index:idx_products_search,class:FULLTEXT"`
 	Price       int64
 	Quantity    int32
-	Description string
+	Description string     `gorm:"index:idx_products_search,class:FULLTEXT"`
+	Category    string     `gorm:"size:64;index"`
+	Brand       string     `gorm:"size:64;index"`
+	BrandID     *uint      `gorm:"index"`
+	Status      string     `gorm:"size:16;not null;default:active;index"`
+	Attributes  Attributes `gorm:"type:json"`
+	// nil disables low stock alerts for the product
+	ReorderPoint    *int32
+	ReorderQuantity int32 `gorm:"not null;default:0"`
 }
 
 type ProductResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Price       int64     `json:"price"`
-	Quantity    int32     `json:"quantity"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
-	DeletedAt   time.Time `json:"deletedAt,omitempty"`
+	ID          uint   `json:"id"`
+	SKU         string `json:"sku,omitempty"`
+	Name        string `json:"name"`
+	Price       int64  `json:"price"`
+	Quantity    int32  `json:"quantity"`
+	Description string `json:"description"`
+	Category    string `json:"category,omitempty"`
+	Brand       string `json:"brand,omitempty"`
+	BrandID     *uint  `json:"brandId,omitempty"`
+	Status      string `json:"status"`
+	// custom attributes defined for the category, by key
+	Attributes map[string]any `json:"attributes,omitempty"`
+	// stock at or below which the product is low; absent means never
+	ReorderPoint    *int32 `json:"reorderPoint,omitempty"`
+	ReorderQuantity int32  `json:"reorderQuantity,omitempty"`
+	// images in display order; not part of revisions
+	Media []MediaResponse `json:"media,omitempty"`
+	// purchasing data, only for callers allowed to see costs: the
+	// suppliers, the lowest cost among them and price minus that cost
+	Suppliers []ProductSupplierResponse `json:"suppliers,omitempty"`
+	Cost      *int64                    `json:"cost,omitempty"`
+	Margin    *int64                    `json:"margin,omitempty"`
+	CreatedAt time.Time                 `json:"createdAt"`
+	UpdatedAt time.Time                 `json:"updatedAt"`
+	DeletedAt time.Time                 `json:"deletedAt,omitempty"`
+}
+
+type ProductSearchHit struct {
+	Product    ProductResponse  `json:"product"`
+	Score      float64          `json:"score"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+// SearchHighlights are HTML-escaped, with the matched words in <mark>.
+type SearchHighlights struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type ProductSuggestion struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// HTML-escaped, with the matched words in <mark>
+	Highlight string `json:"highlight"`
+}
+
+// Facet counts the products per value of a field, under the filters applied
+// to every other field.
+type Facet struct {
+	Field  string       `json:"field"`
+	Values []FacetValue `json:"values"`
+}
+
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+	// bounds of a price bucket; From is inclusive, To exclusive
+	From *int64 `json:"from,omitempty"`
+	To   *int64 `json:"to,omitempty"`
 }
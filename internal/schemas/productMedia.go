@@ -0,0 +1,99 @@
+package schemas
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ProductMedia is an image of a product. The original and its resized
+// variants live in the media storage under Key and each variant's key.
+type ProductMedia struct {
+	ID          uint   `gorm:"primarykey"`
+	TenantID    string `gorm:"size:64;not null;default:default;index"`
+	ProductID   uint   `gorm:"not null;index:idx_product_media_order,priority:1"`
+	Position    int    `gorm:"not null;index:idx_product_media_order,priority:2"`
+	Key         string `gorm:"size:255;not null"`
+	ContentType string `gorm:"size:32;not null"`
+	Size        int64
+	Width       int
+	Height      int
+	Alt         string        `gorm:"size:255"`
+	Variants    MediaVariants `gorm:"type:json"`
+	CreatedAt   time.Time
+}
+
+func (ProductMedia) TableName() string {
+	return "product_media"
+}
+
+// Keys lists the storage keys of the original and every variant.
+func (m ProductMedia) Keys() []string {
+	keys := []string{m.Key}
+	for _, v := range m.Variants {
+		keys = append(keys, v.Key)
+	}
+	return keys
+}
+
+type MediaVariant struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+	Key         string `json:"key"`
+}
+
+// MediaVariants is stored as a JSON column.
+type MediaVariants []MediaVariant
+
+func (v MediaVariants) Value() (driver.Value, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (v *MediaVariants) Scan(src any) error {
+	var b []byte
+	switch s := src.(type) {
+	case nil:
+		*v = nil
+		return nil
+	case []byte:
+		b = s
+	case string:
+		b = []byte(s)
+	default:
+		return fmt.Errorf("schemas: cannot scan %T into MediaVariants", src)
+	}
+	return json.Unmarshal(b, v)
+}
+
+type MediaResponse struct {
+	ID          uint   `json:"id"`
+	Position    int    `json:"position"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+	Alt         string `json:"alt,omitempty"`
+	// resized renditions, a JPEG or PNG per width
+	Variants  []MediaVariantResponse `json:"variants"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type MediaVariantResponse struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"contentType"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
@@ -0,0 +1,49 @@
+package schemas
+
+import (
+	"time"
+)
+
+const (
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionDelete   = "delete"
+	RevisionActionRollback = "rollback"
+	RevisionActionReceive  = "receive"
+)
+
+// ProductRevision is an immutable snapshot of a product taken after every
+// mutation, used to answer "who changed what and when".
+type ProductRevision struct {
+	ID        uint   `gorm:"primarykey"`
+	TenantID  string `gorm:"size:64;not null;default:default;index"`
+	ProductID uint   `gorm:"not null;uniqueIndex:idx_product_revision"`
+	Revision  uint   `gorm:"not null;uniqueIndex:idx_product_revision"`
+	Action    string `gorm:"size:16;not null"`
+	Actor     string `gorm:"size:255;not null"`
+	Snapshot  string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+}
+
+type ProductRevisionResponse struct {
+	ID        uint            `json:"id"`
+	ProductID uint            `json:"productId"`
+	Revision  uint            `json:"revision"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	Snapshot  ProductResponse `json:"snapshot"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+type FieldDiff struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type ProductRevisionDiffResponse struct {
+	ProductID uint        `json:"productId"`
+	From      uint        `json:"from"`
+	To        uint        `json:"to"`
+	Changes   []FieldDiff `json:"changes"`
+}
@@ -0,0 +1,76 @@
+package schemas
+
+import "time"
+
+const (
+	PurchaseOrderDraft             = "draft"
+	PurchaseOrderSent              = "sent"
+	PurchaseOrderPartiallyReceived = "partially_received"
+	PurchaseOrderReceived          = "received"
+	PurchaseOrderCancelled         = "cancelled"
+)
+
+func IsPurchaseOrderStatus(s string) bool {
+	switch s {
+	case PurchaseOrderDraft, PurchaseOrderSent, PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled:
+		return true
+	}
+	return false
+}
+
+// PurchaseOrder is an order of products to a supplier. Drafts can be edited;
+// once sent, goods are received against its lines until every line is
+// complete or the order is cancelled.
+type PurchaseOrder struct {
+	ID         uint   `gorm:"primarykey"`
+	TenantID   string `gorm:"size:64;not null;default:default;index"`
+	SupplierID uint   `gorm:"not null;index"`
+	Status     string `gorm:"size:24;not null;default:draft;index"`
+	Notes      string `gorm:"size:1024"`
+	SentAt     *time.Time
+	ReceivedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// PurchaseOrderLine is a product ordered, how many units and at which unit
+// cost, and how many of them have arrived so far.
+type PurchaseOrderLine struct {
+	ID              uint   `gorm:"primarykey"`
+	TenantID        string `gorm:"size:64;not null;default:default;index"`
+	PurchaseOrderID uint   `gorm:"not null;index"`
+	ProductID       uint   `gorm:"not null;index"`
+	Quantity        int32  `gorm:"not null"`
+	Received        int32  `gorm:"not null;default:0"`
+	UnitCost        int64  `gorm:"not null"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (l PurchaseOrderLine) Outstanding() int32 {
+	return l.Quantity - l.Received
+}
+
+type PurchaseOrderResponse struct {
+	ID           uint                        `json:"id"`
+	SupplierID   uint                        `json:"supplierId"`
+	SupplierName string                      `json:"supplierName,omitempty"`
+	Status       string                      `json:"status"`
+	Notes        string                      `json:"notes,omitempty"`
+	Lines        []PurchaseOrderLineResponse `json:"lines"`
+	// sum of quantity × unit cost, only for callers allowed to see costs
+	Total      *int64     `json:"total,omitempty"`
+	SentAt     *time.Time `json:"sentAt,omitempty"`
+	ReceivedAt *time.Time `json:"receivedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+type PurchaseOrderLineResponse struct {
+	ID        uint  `json:"id"`
+	ProductID uint  `json:"productId"`
+	Quantity  int32 `json:"quantity"`
+	Received  int32 `json:"received"`
+	// only for callers allowed to see costs
+	UnitCost *int64 `json:"unitCost,omitempty"`
+}
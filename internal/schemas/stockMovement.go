@@ -0,0 +1,35 @@
+package schemas
+
+import "time"
+
+const (
+	StockMovementReceipt    = "receipt"
+	StockMovementAdjustment = "adjustment"
+	StockMovementRollback   = "rollback"
+)
+
+// StockMovement is an entry of a product's stock history: how much the
+// quantity changed, what it became and why. Receipts point at the purchase
+// order the goods came from.
+type StockMovement struct {
+	ID              uint   `gorm:"primarykey"`
+	TenantID        string `gorm:"size:64;not null;default:default;index"`
+	ProductID       uint   `gorm:"not null;index"`
+	Delta           int32  `gorm:"not null"`
+	Quantity        int32  `gorm:"not null"`
+	Reason          string `gorm:"size:16;not null"`
+	PurchaseOrderID *uint  `gorm:"index"`
+	Actor           string `gorm:"size:255;not null"`
+	CreatedAt       time.Time
+}
+
+type StockMovementResponse struct {
+	ID              uint      `json:"id"`
+	ProductID       uint      `json:"productId"`
+	Delta           int32     `json:"delta"`
+	Quantity        int32     `json:"quantity"`
+	Reason          string    `json:"reason"`
+	PurchaseOrderID *uint     `json:"purchaseOrderId,omitempty"`
+	Actor           string    `json:"actor"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
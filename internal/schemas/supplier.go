@@ -0,0 +1,49 @@
+package schemas
+
+import "time"
+
+// Supplier is a company products are bought from.
+type Supplier struct {
+	ID          uint   `gorm:"primarykey"`
+	TenantID    string `gorm:"size:64;not null;default:default;index;uniqueIndex:idx_suppliers_name"`
+	Name        string `gorm:"size:128;not null;uniqueIndex:idx_suppliers_name"`
+	ContactName string `gorm:"size:128"`
+	Email       string `gorm:"size:255"`
+	Phone       string `gorm:"size:32"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type SupplierResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	ContactName string    `json:"contactName,omitempty"`
+	Email       string    `json:"email,omitempty"`
+	Phone       string    `json:"phone,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// ProductSupplier is what a supplier charges for a product: its own code
+// for the item, the cost (in the unit of the product price) and how many
+// days a delivery takes.
+type ProductSupplier struct {
+	ID           uint   `gorm:"primarykey"`
+	TenantID     string `gorm:"size:64;not null;default:default;index"`
+	ProductID    uint   `gorm:"not null;uniqueIndex:idx_product_suppliers_pair"`
+	SupplierID   uint   `gorm:"not null;uniqueIndex:idx_product_suppliers_pair;index"`
+	SupplierSKU  string `gorm:"size:64"`
+	CostPrice    int64  `gorm:"not null"`
+	LeadTimeDays int    `gorm:"not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type ProductSupplierResponse struct {
+	SupplierID   uint      `json:"supplierId"`
+	SupplierName string    `json:"supplierName"`
+	SupplierSKU  string    `json:"supplierSku,omitempty"`
+	CostPrice    int64     `json:"costPrice"`
+	LeadTimeDays int       `json:"leadTimeDays"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,112 @@
+package schemas
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+	EventStockChanged   = "stock.changed"
+)
+
+var WebhookEvents = []string{EventProductCreated, EventProductUpdated, EventProductDeleted, EventStockChanged}
+
+func IsWebhookEvent(event string) bool {
+	return slices.Contains(WebhookEvents, event)
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookSubscription sends the events it lists to URL, signed with Secret.
+// Unlike API keys the secret is stored as is, since signing needs it; it is
+// only shown on creation.
+type WebhookSubscription struct {
+	ID          uint   `gorm:"primarykey"`
+	TenantID    string `gorm:"size:64;not null;default:default;index"`
+	URL         string `gorm:"size:2048;not null"`
+	Description string `gorm:"size:255"`
+	// comma separated event types
+	Events    string `gorm:"size:1024;not null"`
+	Secret    string `gorm:"size:128;not null"`
+	Active    bool   `gorm:"not null"`
+	CreatedBy string `gorm:"size:255"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (s WebhookSubscription) EventList() []string {
+	if s.Events == "" {
+		return []string{}
+	}
+	return strings.Split(s.Events, ",")
+}
+
+func (s WebhookSubscription) Wants(event string) bool {
+	return s.Active && slices.Contains(s.EventList(), event)
+}
+
+// WebhookDelivery is one event on its way to one subscription, and stays
+// as the delivery log once it succeeded or gave up. Every subscription
+// receives the same EventID for an event, so receivers can drop duplicates.
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primarykey"`
+	TenantID       string    `gorm:"size:64;not null;default:default;index"`
+	SubscriptionID uint      `gorm:"not null;index"`
+	EventID        string    `gorm:"size:64;not null;index"`
+	Event          string    `gorm:"size:32;not null"`
+	Payload        string    `gorm:"type:text;not null"`
+	Status         string    `gorm:"size:16;not null;index:idx_webhook_deliveries_due,priority:1"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"index:idx_webhook_deliveries_due,priority:2"`
+	// status answered by the receiver on the last attempt; nil when it
+	// couldn't be reached
+	ResponseCode *int
+	Error        string `gorm:"size:1024"`
+	// delivery this one was manually copied from
+	RedeliveryOf *uint
+	DeliveredAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+type WebhookSubscriptionResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// WebhookSubscriptionSecretResponse is only returned on creation.
+type WebhookSubscriptionSecretResponse struct {
+	WebhookSubscriptionResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint            `json:"id"`
+	SubscriptionID uint            `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	Event          string          `json:"event"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	ResponseCode   *int            `json:"responseCode,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	RedeliveryOf   *uint           `json:"redeliveryOf,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,236 @@
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"gorm.io/gorm"
+)
+
+// CountFacets counts the facets of spec in the database, over the products
+// picked by base (nil for the whole catalog) and the filter. Each facet
+// ignores the filter on its own field.
+func CountFacets(db *gorm.DB, base func(*gorm.DB) *gorm.DB, f Filter, spec FacetSpec) ([]schemas.Facet, error) {
+	scoped := func(field string) *gorm.DB {
+		query := db.Model(&schemas.Product{})
+		if base != nil {
+			query = query.Scopes(base)
+		}
+		return query.Scopes(f.Scope(field))
+	}
+
+	facets := make([]schemas.Facet, 0, len(spec.Fields)+len(spec.Attributes))
+	for _, field := range spec.Fields {
+		query := scoped(field)
+
+		var (
+			values []schemas.FacetValue
+			err    error
+		)
+		switch field {
+		case FacetCategory, FacetBrand, FacetStatus:
+			values, err = countValues(query, field)
+		case FacetPrice:
+			values, err = countPrices(query, spec)
+		case FacetInStock:
+			values, err = countInStock(query)
+		default:
+			err = fmt.Errorf("unknown facet")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error counting facet %s: %v", field, err)
+		}
+		facets = append(facets, schemas.Facet{Field: field, Values: values})
+	}
+	for _, key := range spec.Attributes {
+		field := AttributeFacet(key)
+		values, err := countAttribute(scoped(field), key)
+		if err != nil {
+			return nil, fmt.Errorf("error counting facet %s: %v", field, err)
+		}
+		facets = append(facets, schemas.Facet{Field: field, Values: values})
+	}
+	return facets, nil
+}
+
+// column is one of the facet constants, never request input
+func countValues(query *gorm.DB, column string) ([]schemas.FacetValue, error) {
+	var rows []struct {
+		Value string
+		Count int64
+	}
+	err := query.Select(column + " AS value, COUNT(*) AS count").
+		Where(column + " <> ''").
+		Group(column).
+		Order("count DESC, value").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]schemas.FacetValue, 0, len(rows))
+	for _, r := range rows {
+		values = append(values, schemas.FacetValue{Value: r.Value, Count: r.Count})
+	}
+	return values, nil
+}
+
+// countAttribute counts the values of a custom attribute; products without
+// it are left out.
+func countAttribute(query *gorm.DB, key string) ([]schemas.FacetValue, error) {
+	expr := AttributeExpr(key, false)
+	var rows []struct {
+		Value string
+		Count int64
+	}
+	err := query.Select("? AS value, COUNT(*) AS count", expr).
+		Where("? IS NOT NULL", expr).
+		Group("value").
+		Order("count DESC, value").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]schemas.FacetValue, 0, len(rows))
+	for _, r := range rows {
+		values = append(values, schemas.FacetValue{Value: r.Value, Count: r.Count})
+	}
+	return values, nil
+}
+
+func countPrices(query *gorm.DB, spec FacetSpec) ([]schemas.FacetValue, error) {
+	var (
+		bucket strings.Builder
+		args   []any
+	)
+	bucket.WriteString("CASE")
+	for i, edge := range spec.PriceBuckets {
+		fmt.Fprintf(&bucket, " WHEN price < ? THEN %d", i)
+		args = append(args, edge)
+	}
+	fmt.Fprintf(&bucket, " ELSE %d END", len(spec.PriceBuckets))
+
+	var rows []struct {
+		Bucket int
+		Count  int64
+	}
+	err := query.Select(bucket.String()+" AS bucket, COUNT(*) AS count", args...).
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]int64, len(spec.PriceBuckets)+1)
+	for _, r := range rows {
+		if r.Bucket >= 0 && r.Bucket < len(counts) {
+			counts[r.Bucket] = r.Count
+		}
+	}
+	return priceValues(spec, counts), nil
+}
+
+func countInStock(query *gorm.DB) ([]schemas.FacetValue, error) {
+	var row struct {
+		InStock int64
+		Total   int64
+	}
+	err := query.Select("COALESCE(SUM(CASE WHEN quantity > 0 THEN 1 ELSE 0 END), 0) AS in_stock, COUNT(*) AS total").
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	return inStockValues(row.InStock, row.Total-row.InStock), nil
+}
+
+// countFacets is CountFacets for the products held by the memory index.
+func countFacets(products []facetValues, f Filter, spec FacetSpec) []schemas.Facet {
+	facets := make([]schemas.Facet, 0, len(spec.Fields)+len(spec.Attributes))
+	for _, field := range spec.Fields {
+		var (
+			counts  = map[string]int64{}
+			buckets = make([]int64, len(spec.PriceBuckets)+1)
+			inStock [2]int64
+		)
+		for _, p := range products {
+			if !f.matches(p, field) {
+				continue
+			}
+			switch field {
+			case FacetCategory:
+				counts[p.category]++
+			case FacetBrand:
+				counts[p.brand]++
+			case FacetStatus:
+				counts[p.status]++
+			case FacetPrice:
+				buckets[spec.bucket(p.price)]++
+			case FacetInStock:
+				if p.quantity > 0 {
+					inStock[0]++
+				} else {
+					inStock[1]++
+				}
+			}
+		}
+
+		var values []schemas.FacetValue
+		switch field {
+		case FacetPrice:
+			values = priceValues(spec, buckets)
+		case FacetInStock:
+			values = inStockValues(inStock[0], inStock[1])
+		default:
+			delete(counts, "")
+			values = sortedValues(counts)
+		}
+		facets = append(facets, schemas.Facet{Field: field, Values: values})
+	}
+	for _, key := range spec.Attributes {
+		field := AttributeFacet(key)
+		counts := map[string]int64{}
+		for _, p := range products {
+			if v := p.attributes[key]; v != nil && f.matches(p, field) {
+				counts[attributeText(v)]++
+			}
+		}
+		facets = append(facets, schemas.Facet{Field: field, Values: sortedValues(counts)})
+	}
+	return facets
+}
+
+// sortedValues orders counted values as the database does: most products
+// first, then by value.
+func sortedValues(counts map[string]int64) []schemas.FacetValue {
+	values := make([]schemas.FacetValue, 0, len(counts))
+	for v, c := range counts {
+		values = append(values, schemas.FacetValue{Value: v, Count: c})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	return values
+}
+
+// priceValues lists every bucket, empty ones included, so the sidebar keeps
+// a stable layout.
+func priceValues(spec FacetSpec, counts []int64) []schemas.FacetValue {
+	values := make([]schemas.FacetValue, 0, len(counts))
+	for i, c := range counts {
+		values = append(values, spec.bucketValue(i, c))
+	}
+	return values
+}
+
+func inStockValues(inStock, outOfStock int64) []schemas.FacetValue {
+	return []schemas.FacetValue{
+		{Value: "true", Count: inStock},
+		{Value: "false", Count: outOfStock},
+	}
+}
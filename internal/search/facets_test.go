@@ -0,0 +1,172 @@
+package search
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+func catalogProduct(id uint, name, category, brand string, price int64, quantity int32) schemas.Product {
+	p := product(id, "loja1", name, "")
+	p.Category, p.Brand, p.Status, p.Price, p.Quantity = category, brand, schemas.ProductStatusActive, price, quantity
+	return p
+}
+
+func facet(facets []schemas.Facet, field string) map[string]int64 {
+	for _, f := range facets {
+		if f.Field == field {
+			counts := map[string]int64{}
+			for _, v := range f.Values {
+				counts[v.Value] = v.Count
+			}
+			return counts
+		}
+	}
+	return nil
+}
+
+func TestMemoryIndexFacets(t *testing.T) {
+	idx := NewMemoryIndex()
+	ctx := tenant.WithTenant(context.Background(), "loja1")
+
+	require.NoError(t, idx.Put(ctx, catalogProduct(1, "Teclado mecânico", "perifericos", "Acme", 25000, 3)))
+	require.NoError(t, idx.Put(ctx, catalogProduct(2, "Teclado compacto", "perifericos", "Zeta", 8000, 0)))
+	require.NoError(t, idx.Put(ctx, catalogProduct(3, "Teclado gamer", "games", "Acme", 60000, 1)))
+	require.NoError(t, idx.Put(ctx, catalogProduct(4, "Mouse", "perifericos", "Acme", 4000, 9)))
+
+	spec := FacetSpec{Fields: []string{FacetCategory, FacetBrand, FacetPrice, FacetInStock}, PriceBuckets: []int64{10000, 50000}}
+
+	t.Run("filtra os resultados", func(t *testing.T) {
+		r, err := idx.Search(ctx, Query{Text: "teclado", Filter: Filter{Brands: []string{"Acme"}}})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uint{1, 3}, ids(r))
+		require.EqualValues(t, 2, r.Total)
+	})
+
+	t.Run("cada faceta ignora o próprio filtro", func(t *testing.T) {
+		inStock := true
+		r, err := idx.Search(ctx, Query{
+			Text:   "teclado",
+			Filter: Filter{Brands: []string{"Acme"}, InStock: &inStock},
+			Facets: &spec,
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uint{1, 3}, ids(r))
+
+		require.Equal(t, map[string]int64{"perifericos": 1, "games": 1}, facet(r.Facets, FacetCategory))
+		// Zeta sai pelo filtro de estoque, não pelo de marca
+		require.Equal(t, map[string]int64{"Acme": 2}, facet(r.Facets, FacetBrand))
+		require.Equal(t, map[string]int64{"*-10000": 0, "10000-50000": 1, "50000-*": 1}, facet(r.Facets, FacetPrice))
+		require.Equal(t, map[string]int64{"true": 2, "false": 0}, facet(r.Facets, FacetInStock))
+	})
+
+	t.Run("faixa de preço traz os limites", func(t *testing.T) {
+		r, err := idx.Search(ctx, Query{Text: "teclado", Facets: &spec})
+		require.NoError(t, err)
+		prices := r.Facets[2].Values
+		require.Len(t, prices, 3)
+		require.Nil(t, prices[0].From)
+		require.EqualValues(t, 10000, *prices[0].To)
+		require.EqualValues(t, 50000, *prices[2].From)
+		require.Nil(t, prices[2].To)
+		require.Equal(t, []int64{1, 1, 1}, []int64{prices[0].Count, prices[1].Count, prices[2].Count})
+	})
+}
+
+func TestCountFacets(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+	require.NoError(t, err)
+
+	spec := FacetSpec{Fields: []string{FacetCategory, FacetPrice, FacetInStock}, PriceBuckets: []int64{10000}}
+	f := Filter{Categories: []string{"games"}, MinPrice: ptr(int64(5000))}
+
+	mock.ExpectQuery(`SELECT category AS value, COUNT\(\*\) AS count FROM .products. WHERE category <> '' AND price >= \? AND .products.\..deleted_at. IS NULL GROUP BY .category. ORDER BY count DESC, value`).
+		WithArgs(5000).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("perifericos", 4).AddRow("games", 2))
+	mock.ExpectQuery(`SELECT CASE WHEN price < \? THEN 0 ELSE 1 END AS bucket, COUNT\(\*\) AS count FROM .products. WHERE category IN \(\?\) AND .products.\..deleted_at. IS NULL GROUP BY .bucket.`).
+		WithArgs(10000, "games").
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).AddRow(1, 3))
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(CASE WHEN quantity > 0 THEN 1 ELSE 0 END\), 0\) AS in_stock, COUNT\(\*\) AS total FROM .products. WHERE category IN \(\?\) AND price >= \?`).
+		WithArgs("games", 5000).
+		WillReturnRows(sqlmock.NewRows([]string{"in_stock", "total"}).AddRow(1, 2))
+
+	facets, err := CountFacets(db, nil, f, spec)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{"perifericos": 4, "games": 2}, facet(facets, FacetCategory))
+	require.Equal(t, map[string]int64{"*-10000": 0, "10000-*": 3}, facet(facets, FacetPrice))
+	require.Equal(t, map[string]int64{"true": 1, "false": 1}, facet(facets, FacetInStock))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestNewFacetSpec(t *testing.T) {
+	spec, err := NewFacetSpec([]string{FacetBrand, FacetPrice}, []int64{1000})
+	require.NoError(t, err)
+	require.Equal(t, []string{FacetBrand, FacetPrice}, spec.Fields)
+	require.Equal(t, []int64{1000}, spec.PriceBuckets)
+
+	_, err = NewFacetSpec([]string{"color"}, nil)
+	require.EqualError(t, err, `unknown facet "color", expected one of category, brand, status, price, inStock`)
+}
+
+func TestAttributeFacets(t *testing.T) {
+	spec := FacetSpec{Attributes: []string{"color"}}
+	f := Filter{Attributes: []AttributeFilter{{Key: "color", Values: []string{"inox"}}, {Key: "voltage", Numeric: true, Min: ptr(200.0)}}}
+
+	t.Run("conta os valores em memória sem o filtro do próprio atributo", func(t *testing.T) {
+		idx := NewMemoryIndex()
+		ctx := tenant.WithTenant(context.Background(), "loja1")
+		for i, attrs := range []schemas.Attributes{
+			{"color": "inox", "voltage": 220.0},
+			{"color": "branco", "voltage": 220.0},
+			{"color": "branco", "voltage": 127.0},
+			{"voltage": 220.0},
+		} {
+			p := catalogProduct(uint(i+1), "Geladeira", "eletro", "Acme", 300000, 1)
+			p.Attributes = attrs
+			require.NoError(t, idx.Put(ctx, p))
+		}
+
+		r, err := idx.Search(ctx, Query{Text: "geladeira", Filter: f, Facets: &spec})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []uint{1}, ids(r))
+		require.Equal(t, "attr.color", r.Facets[0].Field)
+		// a de 127 V sai pelo filtro de voltagem; a sem cor não conta
+		require.Equal(t, []schemas.FacetValue{{Value: "branco", Count: 1}, {Value: "inox", Count: 1}}, r.Facets[0].Values)
+	})
+
+	t.Run("conta os valores no banco", func(t *testing.T) {
+		sqlDB, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+		db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+		require.NoError(t, err)
+
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) AS value, COUNT(*) AS count FROM `+"`products`"+` WHERE JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) IS NOT NULL AND CAST(JSON_EXTRACT(attributes, ?) AS DOUBLE) >= ?`)).
+			WithArgs(`$."color"`, `$."color"`, `$."voltage"`, 200.0).
+			WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("branco", 1).AddRow("inox", 1))
+
+		facets, err := CountFacets(db, nil, f, spec)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int64{"branco": 1, "inox": 1}, facet(facets, "attr.color"))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,231 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	FacetCategory = "category"
+	FacetBrand    = "brand"
+	FacetStatus   = "status"
+	FacetPrice    = "price"
+	FacetInStock  = "inStock"
+)
+
+// FacetFields are the product fields facets can be counted on.
+var FacetFields = []string{FacetCategory, FacetBrand, FacetStatus, FacetPrice, FacetInStock}
+
+// AttributeFacet names the facet of a custom attribute, attr.<key> as in the
+// sort parameter.
+func AttributeFacet(key string) string {
+	return "attr." + key
+}
+
+// Filter narrows listings and searches. Values of one field are alternatives
+// (category a or b); different fields must all hold.
+type Filter struct {
+	Categories []string
+	Brands     []string
+	Statuses   []string
+	// MinPrice is inclusive and MaxPrice exclusive, like the price buckets
+	MinPrice *int64
+	MaxPrice *int64
+	InStock  *bool
+	// Attributes must all match
+	Attributes []AttributeFilter
+}
+
+// AttributeFilter matches a custom attribute: one of Values for text, enum
+// and boolean attributes, or the range from Min to Max (both inclusive, nil
+// for an open end) for numeric ones.
+type AttributeFilter struct {
+	Key      string
+	Numeric  bool
+	Values   []string
+	Min, Max *float64
+}
+
+// AttributeExpr is the SQL for an attribute of products, cast to a number
+// for numeric attributes so it compares and sorts as one. Products without
+// the attribute get NULL.
+func AttributeExpr(key string, numeric bool) clause.Expr {
+	path := `$."` + key + `"`
+	if numeric {
+		return clause.Expr{SQL: "CAST(JSON_EXTRACT(attributes, ?) AS DOUBLE)", Vars: []any{path}}
+	}
+	return clause.Expr{SQL: "JSON_UNQUOTE(JSON_EXTRACT(attributes, ?))", Vars: []any{path}}
+}
+
+// Scope applies the filter to a products query. The field of the facet
+// being counted is left out, so its other values still get counts.
+func (f Filter) Scope(except string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(f.Categories) > 0 && except != FacetCategory {
+			db = db.Where("category IN ?", f.Categories)
+		}
+		if len(f.Brands) > 0 && except != FacetBrand {
+			db = db.Where("brand IN ?", f.Brands)
+		}
+		if len(f.Statuses) > 0 && except != FacetStatus {
+			db = db.Where("status IN ?", f.Statuses)
+		}
+		if except != FacetPrice {
+			if f.MinPrice != nil {
+				db = db.Where("price >= ?", *f.MinPrice)
+			}
+			if f.MaxPrice != nil {
+				db = db.Where("price < ?", *f.MaxPrice)
+			}
+		}
+		if f.InStock != nil && except != FacetInStock {
+			if *f.InStock {
+				db = db.Where("quantity > 0")
+			} else {
+				db = db.Where("quantity <= 0")
+			}
+		}
+		for _, a := range f.Attributes {
+			if except == AttributeFacet(a.Key) {
+				continue
+			}
+			expr := AttributeExpr(a.Key, a.Numeric)
+			if len(a.Values) > 0 {
+				db = db.Where("? IN ?", expr, a.Values)
+			}
+			if a.Min != nil {
+				db = db.Where("? >= ?", expr, *a.Min)
+			}
+			if a.Max != nil {
+				db = db.Where("? <= ?", expr, *a.Max)
+			}
+		}
+		return db
+	}
+}
+
+// matches is Scope for the in-memory index.
+func (f Filter) matches(p facetValues, except string) bool {
+	if len(f.Categories) > 0 && except != FacetCategory && !slices.Contains(f.Categories, p.category) {
+		return false
+	}
+	if len(f.Brands) > 0 && except != FacetBrand && !slices.Contains(f.Brands, p.brand) {
+		return false
+	}
+	if len(f.Statuses) > 0 && except != FacetStatus && !slices.Contains(f.Statuses, p.status) {
+		return false
+	}
+	if except != FacetPrice {
+		if f.MinPrice != nil && p.price < *f.MinPrice {
+			return false
+		}
+		if f.MaxPrice != nil && p.price >= *f.MaxPrice {
+			return false
+		}
+	}
+	if f.InStock != nil && except != FacetInStock && *f.InStock != (p.quantity > 0) {
+		return false
+	}
+	for _, a := range f.Attributes {
+		if except != AttributeFacet(a.Key) && !a.matches(p.attributes[a.Key]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a AttributeFilter) matches(v any) bool {
+	if v == nil {
+		return false
+	}
+	if len(a.Values) > 0 && !slices.Contains(a.Values, attributeText(v)) {
+		return false
+	}
+	if a.Min != nil || a.Max != nil {
+		n, ok := v.(float64)
+		if !ok || (a.Min != nil && n < *a.Min) || (a.Max != nil && n > *a.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+// attributeText renders a value as JSON_UNQUOTE does in MySQL.
+func attributeText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// facetValues are the fields of a product that filters and facets look at.
+type facetValues struct {
+	category, brand, status string
+	price                   int64
+	quantity                int32
+	attributes              schemas.Attributes
+}
+
+func facetValuesOf(p schemas.Product) facetValues {
+	return facetValues{
+		category: p.Category, brand: p.Brand, status: p.Status,
+		price: p.Price, quantity: p.Quantity, attributes: p.Attributes,
+	}
+}
+
+// FacetSpec says which facets to count. PriceBuckets are the bucket edges,
+// in ascending order: [5000, 10000] gives below 5000, 5000 to 10000 and
+// 10000 or more. Attributes are keys of custom attributes counted by value,
+// each as the facet AttributeFacet(key).
+type FacetSpec struct {
+	Fields       []string
+	PriceBuckets []int64
+	Attributes   []string
+}
+
+// NewFacetSpec checks that every field is one of FacetFields.
+func NewFacetSpec(fields []string, priceBuckets []int64) (FacetSpec, error) {
+	for _, f := range fields {
+		if !slices.Contains(FacetFields, f) {
+			return FacetSpec{}, fmt.Errorf("unknown facet %q, expected one of %s", f, strings.Join(FacetFields, ", "))
+		}
+	}
+	return FacetSpec{Fields: fields, PriceBuckets: priceBuckets}, nil
+}
+
+// bucket returns the index of the price bucket holding price.
+func (s FacetSpec) bucket(price int64) int {
+	i := 0
+	for i < len(s.PriceBuckets) && price >= s.PriceBuckets[i] {
+		i++
+	}
+	return i
+}
+
+// bucketValue describes bucket i, with open ends left nil.
+func (s FacetSpec) bucketValue(i int, count int64) schemas.FacetValue {
+	v := schemas.FacetValue{Count: count}
+	from, to := "*", "*"
+	if i > 0 {
+		v.From = &s.PriceBuckets[i-1]
+		from = fmt.Sprint(*v.From)
+	}
+	if i < len(s.PriceBuckets) {
+		v.To = &s.PriceBuckets[i]
+		to = fmt.Sprint(*v.To)
+	}
+	v.Value = from + "-" + to
+	return v
+}
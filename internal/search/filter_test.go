@@ -0,0 +1,49 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func TestFilterAttributes(t *testing.T) {
+	voltage := Filter{Attributes: []AttributeFilter{{Key: "voltage", Numeric: true, Min: ptr(110.0), Max: ptr(220.0)}}}
+	color := Filter{Attributes: []AttributeFilter{{Key: "color", Values: []string{"azul", "preto"}}}}
+	wireless := Filter{Attributes: []AttributeFilter{{Key: "wireless", Values: []string{"true"}}}}
+
+	t.Run("filtra em memória pelo tipo do atributo", func(t *testing.T) {
+		p := facetValues{attributes: schemas.Attributes{"voltage": 127.0, "color": "azul", "wireless": true}}
+		require.True(t, voltage.matches(p, ""))
+		require.True(t, color.matches(p, ""))
+		require.True(t, wireless.matches(p, ""))
+
+		p = facetValues{attributes: schemas.Attributes{"voltage": 380.0, "color": "branco", "wireless": false}}
+		require.False(t, voltage.matches(p, ""))
+		require.False(t, color.matches(p, ""))
+		require.False(t, wireless.matches(p, ""))
+
+		require.False(t, color.matches(facetValues{}, ""), "produto sem o atributo")
+	})
+
+	t.Run("consulta o JSON no MySQL, convertendo números", func(t *testing.T) {
+		sqlDB, _, err := sqlmock.New()
+		require.NoError(t, err)
+		defer sqlDB.Close()
+		db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+			&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent), DryRun: true})
+		require.NoError(t, err)
+
+		f := Filter{Attributes: append(voltage.Attributes, color.Attributes...)}
+		stmt := db.Model(&schemas.Product{}).Scopes(f.Scope("")).Find(&[]schemas.Product{}).Statement
+		require.Contains(t, stmt.SQL.String(),
+			"CAST(JSON_EXTRACT(attributes, ?) AS DOUBLE) >= ? AND CAST(JSON_EXTRACT(attributes, ?) AS DOUBLE) <= ? AND "+
+				"JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) IN (?,?)")
+		require.Equal(t, []any{`$."voltage"`, 110.0, `$."voltage"`, 220.0, `$."color"`, "azul", "preto"}, stmt.Vars)
+	})
+}
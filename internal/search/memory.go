@@ -0,0 +1,272 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+// BM25 parameters; the name weighs more than the description
+const (
+	k1           = 1.2
+	b            = 0.75
+	nameWeight   = 2.0
+	prefixWeight = 0.5
+)
+
+type posting struct {
+	name, description int
+}
+
+type document struct {
+	terms                   []string
+	nameLen, descriptionLen int
+	values                  facetValues
+}
+
+// corpus is the inverted index of one tenant.
+type corpus struct {
+	docs     map[uint]document
+	postings map[string]map[uint]posting
+	// somas usadas na média de tamanho dos campos
+	nameLen, descriptionLen int
+}
+
+// MemoryIndex is an inverted index kept in process, for deployments where
+// the database has no full-text search. It is built by Load on startup;
+// Put and Remove only carry the writes of this replica, and Reload brings
+// in the rest from the database.
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	tenants map[string]*corpus
+	// non-nil while Reload runs
+	pending []change
+}
+
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{tenants: map[string]*corpus{}}
+}
+
+// Load indexes every product in the database, across all tenants.
+func (i *MemoryIndex) Load(ctx context.Context, db *gorm.DB) error {
+	return eachProduct(ctx, db, func(batch []schemas.Product) {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		for _, p := range batch {
+			i.put(p)
+		}
+	})
+}
+
+// Reload rebuilds the index from the database and swaps it in, replaying
+// the changes made while it ran.
+func (i *MemoryIndex) Reload(ctx context.Context, db *gorm.DB) error {
+	i.mu.Lock()
+	i.pending = []change{}
+	i.mu.Unlock()
+
+	fresh := NewMemoryIndex()
+	err := fresh.Load(ctx, db)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	pending := i.pending
+	i.pending = nil
+	if err != nil {
+		return err
+	}
+
+	for _, c := range pending {
+		if c.removed {
+			fresh.remove(c.product)
+		} else {
+			fresh.put(c.product)
+		}
+	}
+	i.tenants = fresh.tenants
+	return nil
+}
+
+func (i *MemoryIndex) Put(_ context.Context, p schemas.Product) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.pending != nil {
+		i.pending = append(i.pending, change{product: p})
+	}
+	i.put(p)
+	return nil
+}
+
+func (i *MemoryIndex) Remove(_ context.Context, p schemas.Product) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.pending != nil {
+		i.pending = append(i.pending, change{product: p, removed: true})
+	}
+	i.remove(p)
+	return nil
+}
+
+func (i *MemoryIndex) remove(p schemas.Product) {
+	if c, ok := i.tenants[p.TenantID]; ok {
+		c.remove(p.ID)
+	}
+}
+
+func (i *MemoryIndex) put(p schemas.Product) {
+	c, ok := i.tenants[p.TenantID]
+	if !ok {
+		c = &corpus{docs: map[uint]document{}, postings: map[string]map[uint]posting{}}
+		i.tenants[p.TenantID] = c
+	}
+	c.remove(p.ID)
+
+	name, description := Tokenize(p.Name), Tokenize(p.Description)
+	counts := map[string]posting{}
+	for _, t := range name {
+		count := counts[t]
+		count.name++
+		counts[t] = count
+	}
+	for _, t := range description {
+		count := counts[t]
+		count.description++
+		counts[t] = count
+	}
+
+	doc := document{nameLen: len(name), descriptionLen: len(description), values: facetValuesOf(p)}
+	for term, count := range counts {
+		if c.postings[term] == nil {
+			c.postings[term] = map[uint]posting{}
+		}
+		c.postings[term][p.ID] = count
+		doc.terms = append(doc.terms, term)
+	}
+	c.docs[p.ID] = doc
+	c.nameLen += doc.nameLen
+	c.descriptionLen += doc.descriptionLen
+}
+
+func (c *corpus) remove(id uint) {
+	doc, ok := c.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(c.postings[term], id)
+		if len(c.postings[term]) == 0 {
+			delete(c.postings, term)
+		}
+	}
+	delete(c.docs, id)
+	c.nameLen -= doc.nameLen
+	c.descriptionLen -= doc.descriptionLen
+}
+
+// Search returns the products matching every term of the query and the
+// filter, ranked by BM25 over name and description.
+func (i *MemoryIndex) Search(ctx context.Context, q Query) (Result, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return Result{}, tenant.ErrMissingTenant
+	}
+	terms := Terms(q.Text)
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	c, ok := i.tenants[id]
+	if !ok || len(terms) == 0 {
+		return Result{}, nil
+	}
+
+	var scores map[uint]float64
+	for n, term := range terms {
+		termScores := c.score(term)
+		if n == 0 {
+			scores = termScores
+			continue
+		}
+		for doc := range scores {
+			if s, ok := termScores[doc]; ok {
+				scores[doc] += s
+			} else {
+				delete(scores, doc)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		if q.Filter.matches(c.docs[doc].values, "") {
+			hits = append(hits, Hit{ID: doc, Score: score})
+		}
+	}
+	sort.Slice(hits, func(x, y int) bool {
+		if hits[x].Score != hits[y].Score {
+			return hits[x].Score > hits[y].Score
+		}
+		return hits[x].ID < hits[y].ID
+	})
+
+	result := Result{Total: int64(len(hits))}
+	from := min(q.Offset, len(hits))
+	to := len(hits)
+	if q.Limit > 0 {
+		to = min(from+q.Limit, len(hits))
+	}
+	result.Hits = hits[from:to]
+
+	if q.Facets != nil {
+		matched := make([]facetValues, 0, len(scores))
+		for doc := range scores {
+			matched = append(matched, c.docs[doc].values)
+		}
+		result.Facets = countFacets(matched, q.Filter, *q.Facets)
+	}
+	return result, nil
+}
+
+// score rates each document containing term, exactly or as a prefix. A
+// document matching several expansions of a prefix keeps the best one.
+func (c *corpus) score(term string) map[uint]float64 {
+	n := float64(len(c.docs))
+	avgName := math.Max(float64(c.nameLen)/n, 1)
+	avgDescription := math.Max(float64(c.descriptionLen)/n, 1)
+
+	scores := map[uint]float64{}
+	for token, docs := range c.postings {
+		if !matchTerm(token, term) {
+			continue
+		}
+		weight := 1.0
+		if token != term {
+			weight = prefixWeight
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+		for id, p := range docs {
+			doc := c.docs[id]
+			tf := nameWeight*bm25(p.name, doc.nameLen, avgName) +
+				bm25(p.description, doc.descriptionLen, avgDescription)
+			if s := weight * idf * tf; s > scores[id] {
+				scores[id] = s
+			}
+		}
+	}
+	return scores
+}
+
+func bm25(tf, length int, avgLength float64) float64 {
+	if tf == 0 {
+		return 0
+	}
+	f := float64(tf)
+	return f * (k1 + 1) / (f + k1*(1-b+b*float64(length)/avgLength))
+}
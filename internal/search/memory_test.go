@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+func product(id uint, tenantID, name, description string) schemas.Product {
+	return schemas.Product{Model: gorm.Model{ID: id}, TenantID: tenantID, Name: name, Description: description}
+}
+
+func ids(r Result) []uint {
+	out := make([]uint, 0, len(r.Hits))
+	for _, h := range r.Hits {
+		out = append(out, h.ID)
+	}
+	return out
+}
+
+func TestMemoryIndex(t *testing.T) {
+	idx := NewMemoryIndex()
+	ctx := tenant.WithTenant(context.Background(), "loja1")
+
+	require.NoError(t, idx.Put(ctx, product(1, "loja1", "Teclado mecânico", "Switches azuis, layout ABNT2")))
+	require.NoError(t, idx.Put(ctx, product(2, "loja1", "Mouse sem fio", "Acompanha teclado numérico")))
+	require.NoError(t, idx.Put(ctx, product(3, "loja1", "Monitor 4K", "Painel IPS")))
+	require.NoError(t, idx.Put(ctx, product(4, "loja2", "Teclado compacto", "60%")))
+
+	t.Run("nome pesa mais que a descrição", func(t *testing.T) {
+		r, err := idx.Search(ctx, Query{Text: "teclado"})
+		require.NoError(t, err)
+		require.Equal(t, []uint{1, 2}, ids(r))
+		require.EqualValues(t, 2, r.Total)
+	})
+
+	t.Run("todas as palavras precisam casar, inclusive por prefixo e sem acento", func(t *testing.T) {
+		r, err := idx.Search(ctx, Query{Text: "TECLA mecanico"})
+		require.NoError(t, err)
+		require.Equal(t, []uint{1}, ids(r))
+	})
+
+	t.Run("só enxerga o tenant do contexto", func(t *testing.T) {
+		r, err := idx.Search(tenant.WithTenant(context.Background(), "loja2"), Query{Text: "teclado"})
+		require.NoError(t, err)
+		require.Equal(t, []uint{4}, ids(r))
+
+		_, err = idx.Search(context.Background(), Query{Text: "teclado"})
+		require.ErrorIs(t, err, tenant.ErrMissingTenant)
+	})
+
+	t.Run("pagina os resultados", func(t *testing.T) {
+		r, err := idx.Search(ctx, Query{Text: "teclado", Offset: 1, Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []uint{2}, ids(r))
+		require.EqualValues(t, 2, r.Total)
+	})
+
+	t.Run("atualiza e remove produtos", func(t *testing.T) {
+		require.NoError(t, idx.Put(ctx, product(2, "loja1", "Mouse sem fio", "Bateria recarregável")))
+		require.NoError(t, idx.Remove(ctx, product(1, "loja1", "", "")))
+
+		r, err := idx.Search(ctx, Query{Text: "teclado"})
+		require.NoError(t, err)
+		require.Empty(t, r.Hits)
+
+		r, err = idx.Search(ctx, Query{Text: "bateria"})
+		require.NoError(t, err)
+		require.Equal(t, []uint{2}, ids(r))
+	})
+}
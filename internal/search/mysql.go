@@ -0,0 +1,77 @@
+package search
+
+import (
+	"context"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"gorm.io/gorm"
+)
+
+const matchProducts = "MATCH(name, description) AGAINST (? IN BOOLEAN MODE)"
+
+// MySQLIndex searches the FULLTEXT index on products (name, description),
+// which MySQL keeps up to date on its own. Accents are ignored by the
+// column collation (utf8mb4_0900_ai_ci), not by the index.
+type MySQLIndex struct {
+	db *gorm.DB
+}
+
+func NewMySQLIndex(db *gorm.DB) *MySQLIndex {
+	return &MySQLIndex{db: db}
+}
+
+func (*MySQLIndex) Put(context.Context, schemas.Product) error {
+	return nil
+}
+
+func (*MySQLIndex) Remove(context.Context, schemas.Product) error {
+	return nil
+}
+
+// Search requires every term, each also matching as a prefix, like the
+// memory index. The tenant and soft delete scopes come from GORM.
+func (i *MySQLIndex) Search(ctx context.Context, q Query) (Result, error) {
+	against := booleanQuery(Terms(q.Text))
+	if against == "" {
+		return Result{}, nil
+	}
+	match := func(db *gorm.DB) *gorm.DB {
+		return db.Where(matchProducts, against)
+	}
+
+	var result Result
+	query := i.db.WithContext(ctx).Model(&schemas.Product{}).Scopes(match, q.Filter.Scope(""))
+	if err := query.Count(&result.Total).Error; err != nil {
+		return Result{}, err
+	}
+
+	if result.Total > 0 {
+		err := query.Select("id, "+matchProducts+" AS score", against).
+			Order("score DESC, id").
+			Offset(q.Offset).Limit(q.Limit).
+			Scan(&result.Hits).Error
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	if q.Facets != nil {
+		facets, err := CountFacets(i.db.WithContext(ctx), match, q.Filter, *q.Facets)
+		if err != nil {
+			return Result{}, err
+		}
+		result.Facets = facets
+	}
+	return result, nil
+}
+
+// booleanQuery writes terms as "+cafe* +moido*". Terms only hold letters
+// and digits, so they never carry boolean mode operators.
+func booleanQuery(terms []string) string {
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		parts = append(parts, "+"+t+"*")
+	}
+	return strings.Join(parts, " ")
+}
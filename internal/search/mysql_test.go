@@ -0,0 +1,45 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+)
+
+func TestMySQLIndex(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+	require.NoError(t, err)
+	idx := NewMySQLIndex(db)
+
+	t.Run("exige todas as palavras como prefixo e ordena pela relevância", func(t *testing.T) {
+		mock.ExpectQuery(`(?s)SELECT count\(\*\) FROM .products. WHERE MATCH\(name, description\) AGAINST \(\? IN BOOLEAN MODE\) AND .products.\..deleted_at. IS NULL`).
+			WithArgs("+cafe* +moido*").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+		mock.ExpectQuery(`(?s)SELECT id, MATCH\(name, description\) AGAINST \(\? IN BOOLEAN MODE\) AS score FROM .products. WHERE .* ORDER BY score DESC, id LIMIT \? OFFSET \?`).
+			WithArgs("+cafe* +moido*", "+cafe* +moido*", 2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "score"}).AddRow(7, 1.5).AddRow(3, 0.8))
+
+		r, err := idx.Search(context.Background(), Query{Text: "Café de moído", Offset: 1, Limit: 2})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, r.Total)
+		require.Equal(t, []Hit{{ID: 7, Score: 1.5}, {ID: 3, Score: 0.8}}, r.Hits)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("consulta sem palavras buscáveis não vai ao banco", func(t *testing.T) {
+		r, err := idx.Search(context.Background(), Query{Text: "de a ?"})
+		require.NoError(t, err)
+		require.Empty(t, r.Hits)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,84 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type Query struct {
+	Text   string
+	Filter Filter
+	// Facets asks for facet counts over the products matching Text
+	Facets *FacetSpec
+	Offset int
+	Limit  int
+}
+
+type Hit struct {
+	ID    uint
+	Score float64
+}
+
+type Result struct {
+	// Hits holds the requested page, best match first
+	Hits   []Hit
+	Total  int64
+	Facets []schemas.Facet
+}
+
+// Index finds products by the words of their name and description. Like
+// database queries, searches are scoped to the tenant in the context.
+type Index interface {
+	Search(ctx context.Context, q Query) (Result, error)
+	// Put and Remove keep the index in step with committed changes; indexes
+	// maintained by the database itself ignore them.
+	Put(ctx context.Context, p schemas.Product) error
+	Remove(ctx context.Context, p schemas.Product) error
+}
+
+// eachProduct reads every product of every tenant in batches, for the
+// in-process indexes to start from.
+func eachProduct(ctx context.Context, db *gorm.DB, fn func([]schemas.Product)) error {
+	var batch []schemas.Product
+	return db.WithContext(tenant.WithoutScope(ctx)).FindInBatches(&batch, 500, func(*gorm.DB, int) error {
+		fn(batch)
+		return nil
+	}).Error
+}
+
+// change is a Put or Remove that reached an in-process index while it was
+// being rebuilt, replayed on the new copy so it is not lost.
+type change struct {
+	product schemas.Product
+	removed bool
+}
+
+// Reloader is an in-process index that can be rebuilt from the database.
+type Reloader interface {
+	Reload(ctx context.Context, db *gorm.DB) error
+}
+
+// Refresh rebuilds the in-process indexes from db every interval until ctx
+// is cancelled. Each replica only updates them with its own writes, so this
+// is how it picks up the products written through the other replicas.
+func Refresh(ctx context.Context, db *gorm.DB, interval time.Duration, onError func(error), indexes ...Reloader) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, index := range indexes {
+				if err := index.Reload(ctx, db); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}
+}
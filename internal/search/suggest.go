@@ -0,0 +1,483 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+const (
+	// quanto a popularidade pesa sobre a qualidade do casamento
+	popularityWeight = 0.1
+	// bônus para o nome que começa pela palavra buscada
+	leadingWordBonus = 1.25
+)
+
+type Suggestion struct {
+	ID   uint
+	Name string
+	// Highlight is the HTML-escaped name with the matched words in <mark>
+	Highlight string
+	Score     float64
+}
+
+// occurrence is a word of a product name: the product slot and whether the
+// word is the first of the name.
+type occurrence struct {
+	slot    int32
+	leading bool
+}
+
+type trieEdge struct {
+	r    rune
+	node *trieNode
+}
+
+// trieNode keeps its children and occurrences in slices: walking them is
+// what a suggestion costs, and slices are much faster to range over than maps.
+type trieNode struct {
+	children []trieEdge
+	words    []occurrence
+}
+
+type named struct {
+	id    uint
+	name  string
+	terms []string
+	views atomic.Int64
+}
+
+// names is the suggestion index of one tenant. Products live in slots, so a
+// query can score them in a plain slice.
+type names struct {
+	root  trieNode
+	slots []*named
+	free  []int32
+	byID  map[uint]int32
+}
+
+// Suggester completes product names as they are typed. The words of every
+// name sit in a trie per tenant, which is walked with a bounded edit
+// distance so typos still find the product. Popularity is how often a
+// product was opened on this replica since it started.
+//
+// The trie lives in process: Put and Remove only carry the writes of this
+// replica, and Reload brings in the rest from the database.
+type Suggester struct {
+	mu      sync.RWMutex
+	tenants map[string]*names
+	// non-nil while Reload runs
+	pending []change
+}
+
+func NewSuggester() *Suggester {
+	return &Suggester{tenants: map[string]*names{}}
+}
+
+// Load indexes every product in the database, across all tenants.
+func (s *Suggester) Load(ctx context.Context, db *gorm.DB) error {
+	return eachProduct(ctx, db, func(batch []schemas.Product) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, p := range batch {
+			s.put(p)
+		}
+	})
+}
+
+// Reload rebuilds the index from the database and swaps it in. The view
+// counts are carried over, and the changes made while it ran are replayed.
+func (s *Suggester) Reload(ctx context.Context, db *gorm.DB) error {
+	s.mu.Lock()
+	s.pending = []change{}
+	s.mu.Unlock()
+
+	fresh := NewSuggester()
+	err := fresh.Load(ctx, db)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := s.pending
+	s.pending = nil
+	if err != nil {
+		return err
+	}
+
+	for _, c := range pending {
+		if c.removed {
+			fresh.remove(c.product)
+		} else {
+			fresh.put(c.product)
+		}
+	}
+	for id, n := range fresh.tenants {
+		old, ok := s.tenants[id]
+		if !ok {
+			continue
+		}
+		for pid, slot := range n.byID {
+			if was, ok := old.byID[pid]; ok {
+				n.slots[slot].views.Store(old.slots[was].views.Load())
+			}
+		}
+	}
+	s.tenants = fresh.tenants
+	return nil
+}
+
+func (s *Suggester) Put(_ context.Context, p schemas.Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending != nil {
+		s.pending = append(s.pending, change{product: p})
+	}
+	s.put(p)
+	return nil
+}
+
+func (s *Suggester) Remove(_ context.Context, p schemas.Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending != nil {
+		s.pending = append(s.pending, change{product: p, removed: true})
+	}
+	s.remove(p)
+	return nil
+}
+
+// Viewed counts a visit to the product for the popularity ranking.
+func (s *Suggester) Viewed(p schemas.Product) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if n, ok := s.tenants[p.TenantID]; ok {
+		if slot, ok := n.byID[p.ID]; ok {
+			n.slots[slot].views.Add(1)
+		}
+	}
+}
+
+func (s *Suggester) put(p schemas.Product) {
+	n, ok := s.tenants[p.TenantID]
+	if !ok {
+		n = &names{byID: map[uint]int32{}}
+		s.tenants[p.TenantID] = n
+	}
+
+	e := &named{id: p.ID, name: p.Name, terms: Terms(p.Name)}
+	if slot, ok := n.byID[p.ID]; ok {
+		e.views.Store(n.slots[slot].views.Load())
+		n.remove(p.ID)
+	}
+
+	var slot int32
+	if len(n.free) > 0 {
+		slot = n.free[len(n.free)-1]
+		n.free = n.free[:len(n.free)-1]
+		n.slots[slot] = e
+	} else {
+		slot = int32(len(n.slots))
+		n.slots = append(n.slots, e)
+	}
+	n.byID[p.ID] = slot
+
+	for i, t := range e.terms {
+		n.root.insert([]rune(t), occurrence{slot: slot, leading: i == 0})
+	}
+}
+
+func (s *Suggester) remove(p schemas.Product) {
+	if n, ok := s.tenants[p.TenantID]; ok {
+		n.remove(p.ID)
+	}
+}
+
+func (n *names) remove(id uint) {
+	slot, ok := n.byID[id]
+	if !ok {
+		return
+	}
+	for _, t := range n.slots[slot].terms {
+		n.root.delete([]rune(t), slot)
+	}
+	n.slots[slot] = nil
+	n.free = append(n.free, slot)
+	delete(n.byID, id)
+}
+
+func (n *trieNode) child(r rune) (int, bool) {
+	i := sort.Search(len(n.children), func(i int) bool { return n.children[i].r >= r })
+	return i, i < len(n.children) && n.children[i].r == r
+}
+
+func (n *trieNode) insert(term []rune, o occurrence) {
+	for _, r := range term {
+		i, ok := n.child(r)
+		if !ok {
+			n.children = append(n.children, trieEdge{})
+			copy(n.children[i+1:], n.children[i:])
+			n.children[i] = trieEdge{r: r, node: &trieNode{}}
+		}
+		n = n.children[i].node
+	}
+	n.words = append(n.words, o)
+}
+
+// delete drops slot from term and prunes the branches left empty.
+func (n *trieNode) delete(term []rune, slot int32) bool {
+	if len(term) == 0 {
+		for i, o := range n.words {
+			if o.slot == slot {
+				n.words = append(n.words[:i], n.words[i+1:]...)
+				break
+			}
+		}
+	} else if i, ok := n.child(term[0]); ok && n.children[i].node.delete(term[1:], slot) {
+		n.children = append(n.children[:i], n.children[i+1:]...)
+	}
+	return len(n.words) == 0 && len(n.children) == 0
+}
+
+// maxEdits grows with the word: short words get no typo allowance, or
+// almost anything would match. Typos are never taken on the first letter.
+func maxEdits(word []rune) int {
+	switch {
+	case len(word) < 3:
+		return 0
+	case len(word) < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Suggest completes q: the last word as a prefix, the earlier ones as whole
+// words, all within a few typos. Every word has to match; products are
+// ranked by how well they match and then by popularity.
+func (s *Suggester) Suggest(ctx context.Context, q string, limit int) ([]Suggestion, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return nil, tenant.ErrMissingTenant
+	}
+	words := suggestWords(q)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n, ok := s.tenants[id]
+	if !ok || len(words) == 0 {
+		return nil, nil
+	}
+
+	total := make([]float64, len(n.slots))
+	// quantas palavras da consulta o produto já casou
+	matched := make([]int, len(n.slots))
+	var candidates []int32
+
+	for i, w := range words {
+		best := make([]float64, len(n.slots))
+		candidates = candidates[:0]
+		n.root.match(w, maxEdits(w), i == len(words)-1, func(node *trieNode, edits int) {
+			score := 1 / float64(1+edits)
+			for _, o := range node.words {
+				if matched[o.slot] != i {
+					continue
+				}
+				s := score
+				if o.leading && i == 0 {
+					s *= leadingWordBonus
+				}
+				if best[o.slot] == 0 {
+					candidates = append(candidates, o.slot)
+				}
+				best[o.slot] = max(best[o.slot], s)
+			}
+		})
+		for _, slot := range candidates {
+			total[slot] += best[slot]
+			matched[slot]++
+		}
+	}
+
+	top := make([]Suggestion, 0, limit+1)
+	for _, slot := range candidates {
+		e := n.slots[slot]
+		score := total[slot] * (1 + popularityWeight*math.Log1p(float64(e.views.Load())))
+		if limit > 0 && len(top) == limit && !ranksBefore(score, e.id, top[len(top)-1]) {
+			continue
+		}
+		at := sort.Search(len(top), func(i int) bool { return ranksBefore(score, e.id, top[i]) })
+		top = append(top, Suggestion{})
+		copy(top[at+1:], top[at:])
+		top[at] = Suggestion{ID: e.id, Name: e.name, Score: score}
+		if limit > 0 && len(top) > limit {
+			top = top[:limit]
+		}
+	}
+
+	for i := range top {
+		top[i].Highlight = Highlight(top[i].Name, matchedTerms(Terms(top[i].Name), words), 0)
+	}
+	return top, nil
+}
+
+func ranksBefore(score float64, id uint, s Suggestion) bool {
+	if score != s.Score {
+		return score > s.Score
+	}
+	return id < s.ID
+}
+
+// matchedTerms picks the words of a name that answered the query, for the
+// highlight.
+func matchedTerms(terms []string, words [][]rune) []string {
+	var out []string
+	for _, t := range terms {
+		term := []rune(t)
+		for i, w := range words {
+			if len(w) > 0 && term[0] == w[0] && editDistance(w, term, i == len(words)-1) <= maxEdits(w) {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// suggestWords is Terms for text still being typed: the last word is kept
+// even when it is a single letter or looks like a stopword ("de" may become
+// "desktop").
+func suggestWords(q string) [][]rune {
+	runes := []rune(q)
+	spans := words(runes)
+
+	var out [][]rune
+	seen := map[string]bool{}
+	for i, w := range spans {
+		word := Fold(string(runes[w.start:w.end]))
+		last := i == len(spans)-1
+		if !last && (w.end-w.start < 2 || stopwords[word] || seen[word]) {
+			continue
+		}
+		seen[word] = true
+		out = append(out, []rune(word))
+	}
+	return out
+}
+
+// nextRow advances the Damerau-Levenshtein table (optimal string alignment)
+// of w by one rune of the other word, given the two previous rows.
+func nextRow(w []rune, r, prevRune rune, prev2, prev, row []int) (lowest int) {
+	row[0] = prev[0] + 1
+	lowest = row[0]
+	for i := 1; i <= len(w); i++ {
+		cost := 1
+		if w[i-1] == r {
+			cost = 0
+		}
+		row[i] = min(prev[i]+1, row[i-1]+1, prev[i-1]+cost)
+		if prev2 != nil && i > 1 && w[i-1] == prevRune && w[i-2] == r {
+			row[i] = min(row[i], prev2[i-2]+1)
+		}
+		lowest = min(lowest, row[i])
+	}
+	return lowest
+}
+
+// editDistance is the distance from w to term or, with prefix set, to the
+// closest beginning of term.
+func editDistance(w, term []rune, prefix bool) int {
+	prev2, prev, row := []int(nil), make([]int, len(w)+1), make([]int, len(w)+1)
+	for i := range prev {
+		prev[i] = i
+	}
+	best := prev[len(w)]
+	var prevRune rune
+	for _, r := range term {
+		nextRow(w, r, prevRune, prev2, prev, row)
+		best = min(best, row[len(w)])
+		if prev2 == nil {
+			prev2 = make([]int, len(w)+1)
+		}
+		prev2, prev, row = prev, row, prev2
+		prevRune = r
+	}
+	if prefix {
+		return best
+	}
+	return prev[len(w)]
+}
+
+// match visits the words starting with the first letter of w and within
+// maxEdits of it. With prefix set w only has to be close to the beginning
+// of the word. Branches are cut as soon as no
+// extension can get back under maxEdits.
+func (n *trieNode) match(w []rune, maxEdits int, prefix bool, visit func(node *trieNode, edits int)) {
+	row := make([]int, len(w)+1)
+	for i := range row {
+		row[i] = i
+	}
+	best := math.MaxInt
+	if prefix {
+		best = row[len(w)]
+	}
+	// uma linha da tabela por nível da trie, reaproveitada entre irmãos
+	rows := make([][]int, 1, 32)
+	rows[0] = row
+	if i, ok := n.child(w[0]); ok {
+		n.children[i].node.walk(w[0], 0, w, rows, maxEdits, prefix, best, visit)
+	}
+}
+
+func (n *trieNode) walk(r, prevRune rune, w []rune, rows [][]int, maxEdits int, prefix bool, best int, visit func(*trieNode, int)) {
+	depth := len(rows)
+	var prev2 []int
+	if depth > 1 {
+		prev2 = rows[depth-2]
+	}
+	if cap(rows) > depth {
+		rows = rows[:depth+1]
+	} else {
+		rows = append(rows, nil)
+	}
+	if rows[depth] == nil {
+		rows[depth] = make([]int, len(w)+1)
+	}
+	row := rows[depth]
+	lowest := nextRow(w, r, prevRune, prev2, rows[depth-1], row)
+
+	edits := row[len(w)]
+	if prefix {
+		best = min(best, edits)
+		edits = best
+	}
+	if len(n.words) > 0 && edits <= maxEdits {
+		visit(n, edits)
+	}
+
+	if lowest > maxEdits {
+		// the prefix already matched: the rest of the branch completes it
+		if prefix && best <= maxEdits {
+			n.each(func(node *trieNode) { visit(node, best) })
+		}
+		return
+	}
+	for _, e := range n.children {
+		e.node.walk(e.r, r, w, rows, maxEdits, prefix, best, visit)
+	}
+}
+
+// each visits the nodes below n that end a word, not n itself.
+func (n *trieNode) each(visit func(*trieNode)) {
+	for _, e := range n.children {
+		if len(e.node.words) > 0 {
+			visit(e.node)
+		}
+		e.node.each(visit)
+	}
+}
@@ -0,0 +1,154 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+func suggested(s []Suggestion) []string {
+	out := make([]string, 0, len(s))
+	for _, x := range s {
+		out = append(out, x.Name)
+	}
+	return out
+}
+
+func TestSuggester(t *testing.T) {
+	s := NewSuggester()
+	ctx := tenant.WithTenant(context.Background(), "loja1")
+
+	for _, p := range []struct {
+		id   uint
+		name string
+	}{
+		{1, "Teclado mecânico"},
+		{2, "Teclado sem fio"},
+		{3, "Mouse gamer"},
+		{4, "Suporte para teclado"},
+		{5, "Cadeira de escritório"},
+	} {
+		require.NoError(t, s.Put(ctx, product(p.id, "loja1", p.name, "")))
+	}
+	require.NoError(t, s.Put(ctx, product(6, "loja2", "Teclado compacto", "")))
+
+	t.Run("completa a última palavra pelo prefixo", func(t *testing.T) {
+		got, err := s.Suggest(ctx, "tec", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Teclado mecânico", "Teclado sem fio", "Suporte para teclado"}, suggested(got))
+		require.Equal(t, "<mark>Teclado</mark> mecânico", got[0].Highlight)
+	})
+
+	t.Run("tolera erros de digitação", func(t *testing.T) {
+		for _, q := range []string{"tecaldo", "tceldo", "mosue", "cadiera esc"} {
+			got, err := s.Suggest(ctx, q, 10)
+			require.NoError(t, err)
+			require.NotEmpty(t, got, q)
+		}
+
+		got, err := s.Suggest(ctx, "teclado mecanicp", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Teclado mecânico"}, suggested(got))
+	})
+
+	t.Run("palavras curtas não aceitam erro", func(t *testing.T) {
+		got, err := s.Suggest(ctx, "mx", 10)
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+
+	t.Run("popularidade desempata", func(t *testing.T) {
+		for range 20 {
+			s.Viewed(product(2, "loja1", "", ""))
+		}
+		got, err := s.Suggest(ctx, "teclado", 2)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Teclado sem fio", "Teclado mecânico"}, suggested(got))
+	})
+
+	t.Run("segue alterações e remoções", func(t *testing.T) {
+		require.NoError(t, s.Put(ctx, product(3, "loja1", "Mousepad", "")))
+		require.NoError(t, s.Remove(ctx, product(5, "loja1", "", "")))
+
+		got, err := s.Suggest(ctx, "mousep", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Mousepad"}, suggested(got))
+
+		got, err = s.Suggest(ctx, "cadeira", 10)
+		require.NoError(t, err)
+		require.Empty(t, got)
+	})
+
+	t.Run("só enxerga o tenant do contexto", func(t *testing.T) {
+		got, err := s.Suggest(tenant.WithTenant(context.Background(), "loja2"), "tec", 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"Teclado compacto"}, suggested(got))
+
+		_, err = s.Suggest(context.Background(), "tec", 10)
+		require.ErrorIs(t, err, tenant.ErrMissingTenant)
+	})
+}
+
+func TestSuggesterReload(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer sqlDB.Close()
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+	require.NoError(t, err)
+
+	s := NewSuggester()
+	ctx := tenant.WithTenant(context.Background(), "loja1")
+	require.NoError(t, s.Put(ctx, product(1, "loja1", "Teclado mecânico", "")))
+	require.NoError(t, s.Put(ctx, product(2, "loja1", "Mouse gamer", "")))
+	s.Viewed(product(1, "loja1", "Teclado mecânico", ""))
+
+	// outra réplica criou o 7 e apagou o 2
+	mock.ExpectQuery("SELECT \\* FROM `products`").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "tenant_id", "name"}).
+			AddRow(1, "loja1", "Teclado mecânico").
+			AddRow(7, "loja1", "Teclado gamer"))
+
+	require.NoError(t, s.Reload(context.Background(), db))
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	got, err := s.Suggest(ctx, "tec", 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Teclado mecânico", "Teclado gamer"}, suggested(got))
+	// as visualizações do produto que já estava no índice continuam valendo
+	require.Greater(t, got[0].Score, got[1].Score)
+
+	got, err = s.Suggest(ctx, "mouse", 10)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+// go test ./internal/search -bench Suggest; deve ficar abaixo de 10ms por busca
+func BenchmarkSuggest(b *testing.B) {
+	adjectives := []string{"mecânico", "sem fio", "gamer", "compacto", "ergonômico", "profissional", "portátil", "USB-C"}
+	items := []string{"teclado", "mouse", "monitor", "cadeira", "headset", "webcam", "notebook", "suporte", "cabo", "carregador"}
+
+	s := NewSuggester()
+	ctx := tenant.WithTenant(context.Background(), "loja1")
+	for i := range 100_000 {
+		name := fmt.Sprintf("%s %s modelo%d", items[i%len(items)], adjectives[(i/len(items))%len(adjectives)], i)
+		_ = s.Put(ctx, product(uint(i+1), "loja1", name, ""))
+	}
+
+	for _, q := range []string{"t", "tecl", "tecaldo mec", "modelo4242"} {
+		b.Run(q, func(b *testing.B) {
+			for b.Loop() {
+				_, _ = s.Suggest(ctx, q, 10)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// palavras curtas demais para ajudar na busca; comparadas já sem acento
+var stopwords = map[string]bool{
+	"ao": true, "aos": true, "as": true, "com": true, "da": true, "das": true,
+	"de": true, "do": true, "dos": true, "em": true, "na": true, "nas": true,
+	"no": true, "nos": true, "os": true, "ou": true, "para": true, "pela": true,
+	"pelo": true, "por": true, "que": true, "sem": true, "um": true, "uma": true,
+	"umas": true, "uns": true,
+}
+
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n',
+}
+
+// Fold lowercases s and drops accents, so "Café" and "cafe" index the same.
+func Fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		if plain, ok := accents[r]; ok {
+			return plain
+		}
+		return r
+	}, s)
+}
+
+type span struct {
+	start, end int
+}
+
+// words splits runes into runs of letters and digits.
+func words(runes []rune) []span {
+	var spans []span
+	start := -1
+	for i, r := range runes {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(runes)})
+	}
+	return spans
+}
+
+// Tokenize returns the folded words of s in order, without single letters
+// and stopwords.
+func Tokenize(s string) []string {
+	runes := []rune(s)
+	var tokens []string
+	for _, w := range words(runes) {
+		token := Fold(string(runes[w.start:w.end]))
+		if w.end-w.start < 2 || stopwords[token] {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// Terms returns the distinct tokens of a search query.
+func Terms(q string) []string {
+	seen := map[string]bool{}
+	var terms []string
+	for _, t := range Tokenize(q) {
+		if !seen[t] {
+			seen[t] = true
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// matchTerm reports whether an indexed token answers a query term. Terms
+// also match as prefixes, so "teclado" finds "teclados".
+func matchTerm(token, term string) bool {
+	return strings.HasPrefix(token, term)
+}
+
+// Highlight HTML-escapes text and wraps the words matching terms in <mark>.
+// Text longer than size runes is cut to a window around the first match,
+// with an ellipsis where it was cut; size 0 keeps the whole text.
+func Highlight(text string, terms []string, size int) string {
+	runes := []rune(text)
+	spans := words(runes)
+
+	matched := make([]bool, len(spans))
+	first := -1
+	for i, w := range spans {
+		token := Fold(string(runes[w.start:w.end]))
+		for _, term := range terms {
+			if matchTerm(token, term) {
+				matched[i] = true
+				break
+			}
+		}
+		if matched[i] && first < 0 {
+			first = i
+		}
+	}
+
+	from, to := 0, len(runes)
+	if size > 0 && len(runes) > size {
+		anchor := 0
+		if first >= 0 {
+			anchor = spans[first].start
+		}
+		from = max(0, anchor-size/4)
+		to = min(len(runes), from+size)
+		from = max(0, to-size)
+		// não corta palavras ao meio
+		for _, w := range spans {
+			if w.start < from && from < w.end {
+				from = w.end
+			}
+			if w.start < to && to < w.end {
+				to = w.start
+			}
+		}
+		to = max(to, from)
+		for from < to && !unicode.IsLetter(runes[from]) && !unicode.IsDigit(runes[from]) {
+			from++
+		}
+		for to > from && unicode.IsSpace(runes[to-1]) {
+			to--
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for i, w := range spans {
+		if !matched[i] || w.start < from || w.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:w.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[w.start:w.end])))
+		b.WriteString("</mark>")
+		pos = w.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	require.Equal(t, []string{"cafe", "moido", "acucar", "pao"}, Tokenize("Café moído, com açúcar e PÃO"))
+	require.Equal(t, []string{"cafe", "moido"}, Terms("café CAFE moído"))
+	require.Empty(t, Terms("de a e"))
+}
+
+func TestHighlight(t *testing.T) {
+	terms := Terms("cafe")
+
+	t.Run("marca palavras sem acento e escapa o HTML", func(t *testing.T) {
+		require.Equal(t, "<mark>Café</mark> &amp; <mark>cafeteira</mark>", Highlight("Café & cafeteira", terms, 0))
+	})
+
+	t.Run("recorta em volta do primeiro termo", func(t *testing.T) {
+		text := "Pacote grande com grãos selecionados da serra, torra média e moagem fina, ideal para café coado no filtro de papel ou na prensa francesa, em embalagem com válvula"
+		got := Highlight(text, terms, 40)
+
+		require.Contains(t, got, "<mark>café</mark>")
+		require.True(t, len([]rune(got)) < len([]rune(text)))
+		require.Regexp(t, `^…\p{L}`, got)
+		require.Regexp(t, `\p{L}…$`, got)
+	})
+}
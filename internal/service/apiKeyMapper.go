@@ -0,0 +1,26 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toAPIKeyResponse(k schemas.APIKey) schemas.APIKeyResponse {
+	scopes := []string{}
+	if k.Scopes != "" {
+		scopes = strings.Split(k.Scopes, ",")
+	}
+
+	return schemas.APIKeyResponse{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
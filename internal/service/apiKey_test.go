@@ -0,0 +1,121 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+)
+
+func setupGinAPIKeys() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(auth.RoleAdmin))
+	r.POST("/v1/apikeys", CreateAPIKeyService)
+	r.POST("/v1/apikeys/rotate", RotateAPIKeyService)
+	r.DELETE("/v1/apikeys", RevokeAPIKeyService)
+	return r
+}
+
+func TestAPIKeyHandlers(t *testing.T) {
+	r := setupGinAPIKeys()
+
+	t.Run("retorna 400 quando escopo é desconhecido", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/apikeys", bytesOf(`{"name":"erp","scopes":["product:fly"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "product:fly")
+	})
+
+	t.Run("retorna 403 para escopo que o autor não tem", func(t *testing.T) {
+		r := gin.New()
+		r.Use(func(ctx *gin.Context) {
+			auth.SetPrincipal(ctx, &auth.Principal{Subject: "integrador", Permissions: []auth.Permission{auth.APIKeyManage, auth.ProductRead}})
+			ctx.Next()
+		})
+		r.POST("/v1/apikeys", CreateAPIKeyService)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/apikeys", bytesOf(`{"name":"erp","scopes":["product:read","price:write"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "missing permission: price:write")
+	})
+
+	t.Run("retorna 200 mostrando o segredo uma única vez", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `api_keys`")).WillReturnResult(sqlmock.NewResult(5, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/apikeys", bytesOf(`{"name":"erp","scopes":["product:read","stock:adjust"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body APIKeySecretResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.True(t, strings.HasPrefix(body.Data.Secret, "pk_"+body.Data.Prefix+"_"))
+		require.Equal(t, []string{"product:read", "stock:adjust"}, body.Data.Scopes)
+		require.Equal(t, "tester", body.Data.CreatedBy)
+		require.NotContains(t, w.Body.String(), `"hash"`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 409 ao rotacionar chave revogada", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		now := time.Now()
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*api_keys`).WillReturnRows(sqlmock.NewRows(
+			[]string{"id", "name", "prefix", "hash", "scopes", "revoked_at"}).
+			AddRow(5, "erp", "abc", "hash", "product:read", now))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/apikeys/rotate?id=5", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("retorna 404 ao revogar chave inexistente", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*api_keys`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/apikeys?id=99", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toAttributeDefinitionResponse(d schemas.AttributeDefinition) schemas.AttributeDefinitionResponse {
+	var options []string
+	if d.Options != "" {
+		options = strings.Split(d.Options, ",")
+	}
+
+	return schemas.AttributeDefinitionResponse{
+		ID:        d.ID,
+		Category:  d.Category,
+		Key:       d.Key,
+		Label:     d.Label,
+		Type:      d.Type,
+		Required:  d.Required,
+		Options:   options,
+		Unit:      d.Unit,
+		CreatedAt: d.CreatedAt,
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const maxAttributeText = 255
+
+// categoryAttributes loads the attribute definitions of a category.
+func categoryAttributes(db *gorm.DB, category string) ([]schemas.AttributeDefinition, error) {
+	var defs []schemas.AttributeDefinition
+	err := db.Where("category = ?", category).Order("id").Find(&defs).Error
+	return defs, err
+}
+
+// attributeDefinitions loads the definitions of keys, by key. A key keeps its
+// type and unit in every category, so any of its definitions will do.
+func attributeDefinitions(ctx *gin.Context, keys []string) (map[string]schemas.AttributeDefinition, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var defs []schemas.AttributeDefinition
+	if err := requestDB(ctx).Where(map[string]any{"key": keys}).Find(&defs).Error; err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]schemas.AttributeDefinition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+	return byKey, nil
+}
+
+// facetsFor adds to the configured facets the enum and boolean attributes
+// of the categories the filter picks. Without a category filter there is no
+// sidebar of attributes to fill, so none are counted.
+func facetsFor(ctx *gin.Context, filter search.Filter) (search.FacetSpec, error) {
+	spec := facetSpec
+	if len(filter.Categories) == 0 {
+		return spec, nil
+	}
+	var keys []string
+	err := requestDB(ctx).Model(&schemas.AttributeDefinition{}).
+		Where("category IN ? AND type IN ?", filter.Categories, []string{schemas.AttributeEnum, schemas.AttributeBoolean}).
+		Order("id").Pluck("key", &keys).Error
+	if err != nil {
+		return search.FacetSpec{}, err
+	}
+	for _, key := range keys {
+		if !slices.Contains(spec.Attributes, key) {
+			spec.Attributes = append(spec.Attributes, key)
+		}
+	}
+	return spec, nil
+}
+
+// attributeParams picks the attribute filters out of the query string:
+// attr[voltage]=220, repeated for alternatives, or attr[weight]=1..5 for a
+// numeric range with optional ends.
+func attributeParams(values url.Values) map[string][]string {
+	var params map[string][]string
+	for name, v := range values {
+		key, ok := strings.CutPrefix(name, "attr[")
+		if !ok || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if params == nil {
+			params = map[string][]string{}
+		}
+		params[strings.TrimSuffix(key, "]")] = v
+	}
+	return params
+}
+
+// parseNumberRange reads "220" (exactly), "1..5", "..5" or "1..".
+func parseNumberRange(s string) (min, max *float64, err error) {
+	parse := func(s string) (*float64, error) {
+		if s == "" {
+			return nil, nil
+		}
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("has invalid number %q", s)
+		}
+		return &n, nil
+	}
+
+	from, to, isRange := strings.Cut(s, "..")
+	if !isRange {
+		n, err := parse(s)
+		if err == nil && n == nil {
+			err = fmt.Errorf("is empty")
+		}
+		return n, n, err
+	}
+	if min, err = parse(from); err != nil {
+		return nil, nil, err
+	}
+	if max, err = parse(to); err != nil {
+		return nil, nil, err
+	}
+	if min != nil && max != nil && *min > *max {
+		return nil, nil, fmt.Errorf("has a range ending before it starts")
+	}
+	return min, max, nil
+}
+
+// mergeAttributes applies changes (nil values remove a key) over current,
+// keeping only attributes defined for the category and checking each new
+// value against its definition and the required attributes.
+func mergeAttributes(category string, defs []schemas.AttributeDefinition, current schemas.Attributes, changes map[string]any) (schemas.Attributes, error) {
+	byKey := make(map[string]schemas.AttributeDefinition, len(defs))
+	for _, d := range defs {
+		byKey[d.Key] = d
+	}
+
+	out := schemas.Attributes{}
+	for k, v := range current {
+		if _, ok := byKey[k]; ok {
+			out[k] = v
+		}
+	}
+
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		def, ok := byKey[k]
+		if !ok {
+			return nil, fmt.Errorf("param: attribute %s is not defined for category %q", k, category)
+		}
+		if changes[k] == nil {
+			delete(out, k)
+			continue
+		}
+		v, err := attributeValue(def, changes[k])
+		if err != nil {
+			return nil, err
+		}
+		out[k] = v
+	}
+
+	for _, d := range defs {
+		if _, ok := out[d.Key]; d.Required && !ok {
+			return nil, fmt.Errorf("param: attribute %s is required for category %q", d.Key, category)
+		}
+	}
+
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func attributeValue(def schemas.AttributeDefinition, v any) (any, error) {
+	switch def.Type {
+	case schemas.AttributeString:
+		if s, ok := v.(string); ok && utf8.RuneCountInString(s) <= maxAttributeText {
+			return s, nil
+		}
+		return nil, fmt.Errorf("param: attribute %s must be a string of at most %d characters", def.Key, maxAttributeText)
+	case schemas.AttributeNumber, schemas.AttributeUnit:
+		if n, ok := v.(float64); ok {
+			return n, nil
+		}
+		if def.Type == schemas.AttributeUnit {
+			return nil, fmt.Errorf("param: attribute %s must be a number, in %s", def.Key, def.Unit)
+		}
+		return nil, fmt.Errorf("param: attribute %s must be a number", def.Key)
+	case schemas.AttributeEnum:
+		options := strings.Split(def.Options, ",")
+		if s, ok := v.(string); ok && slices.Contains(options, s) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("param: attribute %s must be one of %s", def.Key, strings.Join(options, ", "))
+	case schemas.AttributeBoolean:
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("param: attribute %s must be true or false", def.Key)
+	}
+	return nil, fmt.Errorf("param: attribute %s has unknown type %s", def.Key, def.Type)
+}
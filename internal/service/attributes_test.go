@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+var applianceAttributes = []schemas.AttributeDefinition{
+	{Category: "eletro", Key: "voltage", Type: schemas.AttributeUnit, Unit: "V", Required: true},
+	{Category: "eletro", Key: "color", Type: schemas.AttributeEnum, Options: "branco,inox"},
+	{Category: "eletro", Key: "inverter", Type: schemas.AttributeBoolean},
+	{Category: "eletro", Key: "model", Type: schemas.AttributeString},
+}
+
+func TestMergeAttributes(t *testing.T) {
+	t.Run("aceita valores do tipo definido", func(t *testing.T) {
+		attrs, err := mergeAttributes("eletro", applianceAttributes, nil,
+			map[string]any{"voltage": 220.0, "color": "inox", "inverter": true, "model": "X-1"})
+		require.NoError(t, err)
+		require.Equal(t, schemas.Attributes{"voltage": 220.0, "color": "inox", "inverter": true, "model": "X-1"}, attrs)
+	})
+
+	t.Run("recusa tipo errado, opção desconhecida e chave não definida", func(t *testing.T) {
+		for changes, want := range map[string]string{
+			`{"voltage": "220"}`:                 "voltage must be a number, in V",
+			`{"voltage": 220, "color": "verde"}`: "color must be one of branco, inox",
+			`{"voltage": 220, "inverter": 1}`:    "inverter must be true or false",
+			`{"voltage": 220, "isbn": "123"}`:    `isbn is not defined for category "eletro"`,
+			`{"color": "inox"}`:                  `voltage is required for category "eletro"`,
+		} {
+			var m map[string]any
+			require.NoError(t, json.Unmarshal([]byte(changes), &m))
+			_, err := mergeAttributes("eletro", applianceAttributes, nil, m)
+			require.ErrorContains(t, err, want, changes)
+		}
+	})
+
+	t.Run("altera só as chaves enviadas e remove com null", func(t *testing.T) {
+		current := schemas.Attributes{"voltage": 127.0, "color": "branco", "isbn": "de outra categoria"}
+		attrs, err := mergeAttributes("eletro", applianceAttributes, current, map[string]any{"voltage": 220.0, "color": nil})
+		require.NoError(t, err)
+		require.Equal(t, schemas.Attributes{"voltage": 220.0}, attrs)
+	})
+}
+
+func TestParseNumberRange(t *testing.T) {
+	for s, want := range map[string][2]*float64{
+		"220":    {ptrTo(220.0), ptrTo(220.0)},
+		"1.5..5": {ptrTo(1.5), ptrTo(5.0)},
+		"..5":    {nil, ptrTo(5.0)},
+		"1..":    {ptrTo(1.0), nil},
+	} {
+		min, max, err := parseNumberRange(s)
+		require.NoError(t, err, s)
+		require.Equal(t, want, [2]*float64{min, max}, s)
+	}
+
+	for _, s := range []string{"", "abc", "5..1", "1..x"} {
+		_, _, err := parseNumberRange(s)
+		require.Error(t, err, s)
+	}
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
+
+func TestAttributeHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(auth.RoleAdmin))
+	r.POST("/v1/attributes", CreateAttributeDefinitionService)
+	r.POST("/v1/product", CreateProductService)
+	r.GET("/v1/products", FindAllProductsService)
+
+	t.Run("recusa redefinir uma chave com outro tipo", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectAttributeKeyLock(mock, "voltage")
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `attribute_definitions` WHERE `attribute_definitions`.`key` = ? AND (type <> ? OR unit <> ?) LIMIT ?")).
+			WithArgs("voltage", "number", "", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "category", "key", "type", "unit"}).AddRow(1, "eletro", "voltage", "unit", "V"))
+		mock.ExpectRollback()
+
+		body := bytesOf(`{"category":"ferramentas","key":"voltage","type":"number"}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/attributes", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), `attribute voltage is already a unit (V) in category \"eletro\"`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("cria a definição com a chave travada", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectAttributeKeyLock(mock, "voltage")
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `attribute_definitions` WHERE `attribute_definitions`.`key` = ? AND (type <> ? OR unit <> ?) LIMIT ?")).
+			WithArgs("voltage", "unit", "V", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `attribute_definitions`")).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		body := bytesOf(`{"category":"ferramentas","key":"voltage","type":"unit","unit":"V"}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/attributes", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("valida os atributos da categoria ao criar o produto", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `attribute_definitions` WHERE category = ?")).
+			WithArgs("eletro").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "category", "key", "type", "unit", "required"}).
+				AddRow(1, "eletro", "voltage", "unit", "V", true))
+
+		body := bytesOf(`{"name":"Micro-ondas","price":59900,"quantity":2,"description":"20 litros","category":"eletro","attributes":{}}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/product", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `attribute voltage is required for category \"eletro\"`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("filtra e ordena pelos atributos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `attribute_definitions` WHERE `attribute_definitions`.`key` IN (?,?)")).
+			WithArgs("color", "voltage").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "category", "key", "type", "unit"}).
+				AddRow(1, "eletro", "voltage", "unit", "V").
+				AddRow(2, "eletro", "color", "enum", ""))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) IN (?) AND "+
+			"`products`.`deleted_at` IS NULL ORDER BY CAST(JSON_EXTRACT(attributes, ?) AS DOUBLE) DESC, id")).
+			WithArgs(`$."color"`, "inox", `$."voltage"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "category", "attributes"}).
+				AddRow(3, "Geladeira", "eletro", `{"voltage": 220, "color": "inox"}`))
+		expectMedia(mock)
+		expectSuppliers(mock)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products?attr[color]=inox&sort=-attr.voltage", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body FindAllProductsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 1)
+		require.Equal(t, map[string]any{"voltage": 220.0, "color": "inox"}, body.Data[0].Attributes)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa filtro por atributo desconhecido", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `attribute_definitions` WHERE `attribute_definitions`.`key` = ?")).
+			WithArgs("weight").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products?attr[weight]=1..5", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "attr[weight] is not a known attribute")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// expectAttributeKeyLock espera a criação da linha da chave, se ainda não
+// existir, e a trava sobre ela.
+func expectAttributeKeyLock(mock sqlmock.Sqlmock, key string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `attribute_keys`")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `attribute_keys` WHERE `attribute_keys`.`key` = ? LIMIT ? FOR UPDATE")).
+		WithArgs(key, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key"}).AddRow(1, key))
+}
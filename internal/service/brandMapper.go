@@ -0,0 +1,15 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toBrandResponse(b schemas.Brand) schemas.BrandResponse {
+	return schemas.BrandResponse{
+		ID:        b.ID,
+		Name:      b.Name,
+		Website:   b.Website,
+		CreatedAt: b.CreatedAt,
+		UpdatedAt: b.UpdatedAt,
+	}
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"gorm.io/gorm"
+)
+
+var errBrandNotFound = errors.New("brand not found")
+
+// linkBrand points the product at a registered brand and copies its name,
+// which is what filters, facets and search look at.
+func linkBrand(db *gorm.DB, p *schemas.Product, id uint) error {
+	var brand schemas.Brand
+	err := db.First(&brand, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errBrandNotFound
+	}
+	if err != nil {
+		return err
+	}
+	p.BrandID, p.Brand = &brand.ID, brand.Name
+	return nil
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+)
+
+func setupGinBrands() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(auth.RoleCatalog))
+	r.POST("/v1/product", CreateProductService)
+	r.POST("/v1/brands", CreateBrandService)
+	r.PUT("/v1/brands", UpdateBrandService)
+	r.DELETE("/v1/brands", DeleteBrandService)
+	return r
+}
+
+func TestBrandHandlers(t *testing.T) {
+	r := setupGinBrands()
+
+	t.Run("retorna 409 para marca com nome repetido", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `brands`")).WillReturnError(gorm.ErrDuplicatedKey)
+		mock.ExpectRollback()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/brands", bytesOf(`{"name":"Logitech"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "a brand with this name already exists")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("produto ligado por brandId recebe o nome da marca", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `brands` WHERE `brands`.`id` = ?")).
+			WithArgs(4, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Logitech"))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `products`")).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Mouse", 9900, 3, "Sem fio",
+				"", "Logitech", 4, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 0).
+			WillReturnResult(sqlmock.NewResult(9, 1))
+		expectRevision(mock)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/product",
+			bytesOf(`{"name":"Mouse","price":9900,"quantity":3,"description":"Sem fio","brandId":4}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"brand":"Logitech","brandId":4`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa brandId de marca inexistente", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `brands` WHERE `brands`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/product",
+			bytesOf(`{"name":"Mouse","price":9900,"quantity":3,"description":"Sem fio","brandId":99}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "brand not found")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("renomear a marca renomeia os produtos ligados e grava uma revisão de cada", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `brands` WHERE `brands`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Logitec"))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `brands`")).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `products` SET `brand`=?,`updated_at`=? WHERE brand_id = ?")+"$").
+			WithArgs("Logitech", sqlmock.AnyArg(), 4).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE brand_id = ? AND `products`.`deleted_at` IS NULL ORDER BY id")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "brand"}).
+				AddRow(9, "Mouse", "Logitech").
+				AddRow(10, "Teclado", "Logitech"))
+		expectRevision(mock)
+		expectRevision(mock)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/brands?id=4", bytesOf(`{"name":"Logitech"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("não apaga marca ligada a produtos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `brands` WHERE `brands`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(4, "Logitech"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `products` WHERE brand_id = ?")).
+			WithArgs(4).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/brands?id=4", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Cancel purchase order
+// @Description Cancel a purchase order that is not fully received. Units already received stay in stock.
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param id query string true "Purchase order identification"
+// @Success 200 {object} PurchaseOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-order/cancel [post]
+func CancelPurchaseOrderService(ctx *gin.Context) {
+	changeOrderStatus(ctx, "cancel-purchase-order", schemas.PurchaseOrderCancelled,
+		schemas.PurchaseOrderDraft, schemas.PurchaseOrderSent, schemas.PurchaseOrderPartiallyReceived)
+}
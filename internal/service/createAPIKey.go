@@ -0,0 +1,72 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Create API key
+// @Description Issue an API key for a machine client. The secret is only returned in this response.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "Request body"
+// @Success 200 {object} APIKeySecretResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /apikeys [post]
+func CreateAPIKeyService(ctx *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if missing, ok := auth.Missing(ctx, req.RequiredPermissions()...); ok {
+		sendError(ctx, http.StatusForbidden, middleware.MissingPermissionMessage(missing))
+		return
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		requestLogger(ctx).Errorf("error generating api key: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error generating api key")
+		return
+	}
+
+	key := schemas.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    strings.Join(req.Scopes, ","),
+		CreatedBy: middleware.Actor(ctx),
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := requestDB(ctx).Create(&key).Error; err != nil {
+		requestLogger(ctx).Errorf("error creating api key: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error creating api key on database")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIKeySecretResponse{
+		Message: "operation from handler: create-api-key successful",
+		Data:    schemas.APIKeySecretResponse{APIKeyResponse: toAPIKeyResponse(key), Secret: plain},
+	})
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// @BasePath /v1
+
+// @Summary Create attribute definition
+// @Description Declare a custom attribute for the products of a category. A key keeps the same type and unit in every category, so filters and sorting mean the same everywhere.
+// @Tags Attributes
+// @Accept json
+// @Produce json
+// @Param request body CreateAttributeDefinitionRequest true "Request body"
+// @Success 200 {object} AttributeDefinitionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /attributes [post]
+func CreateAttributeDefinitionService(ctx *gin.Context) {
+	var req CreateAttributeDefinitionRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	def := schemas.AttributeDefinition{
+		Category: req.Category,
+		Key:      req.Key,
+		Label:    req.Label,
+		Type:     req.Type,
+		Required: req.Required,
+		Options:  strings.Join(req.Options, ","),
+		Unit:     req.Unit,
+	}
+
+	// the key row serialises definitions of the key, so two categories
+	// cannot declare it with different types at the same time
+	var conflict error
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		key := schemas.AttributeKey{Key: req.Key}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(map[string]any{"key": req.Key}).Take(&key).Error; err != nil {
+			return err
+		}
+
+		var other schemas.AttributeDefinition
+		err := tx.Where(map[string]any{"key": req.Key}).
+			Where("type <> ? OR unit <> ?", req.Type, req.Unit).
+			Take(&other).Error
+		if err == nil {
+			conflict = fmt.Errorf("attribute %s is already a %s in category %q", req.Key, describeAttributeType(other), other.Category)
+			return conflict
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		return tx.Create(&def).Error
+	})
+	if err != nil {
+		switch {
+		case conflict != nil:
+			sendError(ctx, http.StatusConflict, conflict.Error())
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			sendError(ctx, http.StatusConflict, "this attribute is already defined for the category")
+		default:
+			requestLogger(ctx).Errorf("error creating attribute definition: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error creating attribute definition on database")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AttributeDefinitionResponse{
+		Message: "operation from handler: create-attribute-definition successful",
+		Data:    toAttributeDefinitionResponse(def),
+	})
+}
+
+func describeAttributeType(d schemas.AttributeDefinition) string {
+	if d.Type == schemas.AttributeUnit {
+		return fmt.Sprintf("unit (%s)", d.Unit)
+	}
+	return d.Type
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Create brand
+// @Description Register a brand products can be linked to through brandId
+// @Tags Brands
+// @Accept json
+// @Produce json
+// @Param request body BrandRequest true "Request body"
+// @Success 200 {object} BrandResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /brands [post]
+func CreateBrandService(ctx *gin.Context) {
+	var req BrandRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	brand := schemas.Brand{Name: req.Name, Website: req.Website}
+	err := requestDB(ctx).Create(&brand).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		sendError(ctx, http.StatusConflict, "a brand with this name already exists")
+		return
+	}
+	if err != nil {
+		requestLogger(ctx).Errorf("error creating brand: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error creating brand on database")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BrandResponse{
+		Message: "operation from handler: create-brand successful",
+		Data:    toBrandResponse(brand),
+	})
+}
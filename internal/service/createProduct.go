@@ -2,11 +2,14 @@
 package service
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
 	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // @BasePath /v1
@@ -16,37 +19,90 @@ import (
 // @Accept json
 // @Produce json
 // @Param request body CreateProductRequest true "Request body"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
 // @Success 200 {object} CreateProductResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /product [post]
 func CreateProductService(ctx *gin.Context) {
 	var req CreateProductRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		logger.Errorf("bind error: %v", err)
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
 		sendError(ctx, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		logger.Errorf("validation error: %v", err)
+		requestLogger(ctx).Errorf("validation error: %v", err)
 		sendError(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	var attributes schemas.Attributes
+	if req.Category != "" {
+		defs, err := categoryAttributes(requestDB(ctx), req.Category)
+		if err != nil {
+			requestLogger(ctx).Errorf("error loading attribute definitions: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error creating product on database")
+			return
+		}
+		if attributes, err = req.ValidateAttributes(defs); err != nil {
+			requestLogger(ctx).Errorf("validation error: %v", err)
+			sendError(ctx, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	product := schemas.Product{
+		SKU:         optionalString(req.SKU),
 		Name:        req.Name,
 		Price:       req.Price,
 		Quantity:    req.Quantity,
 		Description: req.Description,
+		Category:    req.Category,
+		Brand:       req.Brand,
+		Status:      req.Status,
+		Attributes:  attributes,
+
+		ReorderPoint:    req.ReorderPoint,
+		ReorderQuantity: req.ReorderQuantity,
+	}
+	if product.Status == "" {
+		product.Status = schemas.ProductStatusActive
+	}
+	if req.BrandID != nil {
+		if err := linkBrand(requestDB(ctx), &product, *req.BrandID); err != nil {
+			if errors.Is(err, errBrandNotFound) {
+				sendError(ctx, http.StatusBadRequest, err.Error())
+				return
+			}
+			requestLogger(ctx).Errorf("error loading brand: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error creating product on database")
+			return
+		}
 	}
 
-	if err := db.Create(&product).Error; err != nil {
-		logger.Errorf("error creating product: %v", err)
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&product).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, product, schemas.RevisionActionCreate, middleware.Actor(ctx))
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		sendError(ctx, http.StatusConflict, "a product with this sku already exists")
+		return
+	}
+	if err != nil {
+		requestLogger(ctx).Errorf("error creating product: %v", err)
 		sendError(ctx, http.StatusInternalServerError, "error creating product on database")
 		return
 	}
 
+	indexProduct(ctx, product)
+
 	ctx.JSON(http.StatusOK, CreateProductResponse{
 		Message: "operation from handler: create-product successful",
 		Data:    toProductResponse(product),
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// room for the multipart envelope and the alt field around the file
+const multipartOverhead = 64 << 10
+
+// @BasePath /v1
+
+// @Summary Upload product image
+// @Description Upload a JPEG, PNG, GIF or WebP image of a product. The type is read from the content, not the file name. The original is stored as sent, with a resized JPEG (PNG when transparent) variant for each configured width, and the image goes last in the product's media list.
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param file formData file true "Image"
+// @Param alt formData string false "Alternative text"
+// @Success 200 {object} ProductMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 413 {object} ErrorResponse
+// @Failure 415 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/media [post]
+func CreateProductMediaService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	product := schemas.Product{}
+	if err := requestDB(ctx).First(&product, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "product not found")
+		return
+	}
+
+	maxSize := mediaCfg.MaxUploadBytes()
+	tooLarge := fmt.Sprintf("image must have at most %d MB", mediaCfg.MaxUploadMB)
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSize+multipartOverhead)
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			sendError(ctx, http.StatusRequestEntityTooLarge, tooLarge)
+			return
+		}
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("file", "multipart file").Error())
+		return
+	}
+	defer file.Close()
+	if header.Size > maxSize {
+		sendError(ctx, http.StatusRequestEntityTooLarge, tooLarge)
+		return
+	}
+
+	alt := ctx.Request.FormValue("alt")
+	if utf8.RuneCountInString(alt) > 255 {
+		sendError(ctx, http.StatusBadRequest, "param: alt must have at most 255 characters")
+		return
+	}
+
+	// decoding takes up to a few hundred MB, so only a few run at once
+	select {
+	case mediaSlots <- struct{}{}:
+		defer func() { <-mediaSlots }()
+	case <-ctx.Request.Context().Done():
+		sendError(ctx, http.StatusServiceUnavailable, "too many images being processed, try again later")
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		requestLogger(ctx).Errorf("error reading upload: %v", err)
+		sendError(ctx, http.StatusBadRequest, "error reading uploaded file")
+		return
+	}
+	img, err := media.Decode(data)
+	if errors.Is(err, media.ErrUnsupportedType) {
+		sendError(ctx, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// checked again when the image is saved; this only spares the work
+	count, _, err := mediaCount(requestDB(ctx), product.ID)
+	if err != nil {
+		requestLogger(ctx).Errorf("error counting product media: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error storing image")
+		return
+	}
+	if count >= int64(mediaCfg.MaxPerProduct) {
+		sendError(ctx, http.StatusConflict, fmt.Sprintf("product already has %d images, the most allowed", count))
+		return
+	}
+
+	variants, err := img.Variants(mediaCfg.ThumbnailWidths)
+	if err != nil {
+		requestLogger(ctx).Errorf("error resizing image: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error storing image")
+		return
+	}
+
+	prefix, err := mediaKeyPrefix(ctx, product.ID)
+	if err != nil {
+		requestLogger(ctx).Errorf("error naming image: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error storing image")
+		return
+	}
+	m := schemas.ProductMedia{
+		ProductID:   product.ID,
+		Key:         prefix + "/original" + media.Extension(img.ContentType),
+		ContentType: img.ContentType,
+		Size:        int64(len(data)),
+		Width:       img.Width,
+		Height:      img.Height,
+		Alt:         alt,
+	}
+	for _, v := range variants {
+		m.Variants = append(m.Variants, schemas.MediaVariant{
+			Name:        v.Name,
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+			Size:        int64(len(v.Data)),
+			Key:         prefix + "/" + v.Name + media.Extension(v.ContentType),
+		})
+	}
+
+	if err := storeMedia(ctx.Request.Context(), m, data, variants); err != nil {
+		requestLogger(ctx).Errorf("error storing image: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error storing image")
+		return
+	}
+	// the product row serialises uploads, so the limit holds and positions
+	// stay unique
+	var full error
+	err = requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		var locked schemas.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, product.ID).Error; err != nil {
+			return err
+		}
+		count, next, err := mediaCount(tx, product.ID)
+		if err != nil {
+			return err
+		}
+		if count >= int64(mediaCfg.MaxPerProduct) {
+			full = fmt.Errorf("product already has %d images, the most allowed", count)
+			return full
+		}
+		m.Position = next
+		return tx.Create(&m).Error
+	})
+	if err != nil {
+		removeMedia(ctx, m)
+		switch {
+		case full != nil:
+			sendError(ctx, http.StatusConflict, full.Error())
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			sendError(ctx, http.StatusNotFound, "product not found")
+		default:
+			requestLogger(ctx).Errorf("error creating product media: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error storing image")
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ProductMediaResponse{
+		Message: "operation from handler: create-product-media successful",
+		Data:    toMediaResponse(m),
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -98,6 +99,55 @@ func TestCreateProductsHandler(t *testing.T) {
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("retorna 400 quando o SKU passa de 64 caracteres", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"sku":"` + strings.Repeat("A", 65) + `","name":"Teclado","price":299,"quantity":5,"description":"ABNT2"}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/product", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "sku must have at most 64 characters")
+	})
+
+	t.Run("retorna 400 quando o nome tem quebra de linha", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"name":"Teclado\r\nBcc: fora@example.com","price":299,"quantity":5,"description":"ABNT2"}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/product", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "name must not contain control characters")
+	})
+
+	t.Run("retorna 409 quando o SKU já existe no tenant", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		originalDB := db
+		db = gdb
+		defer func() { db = originalDB }()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `products`")).
+			WillReturnError(gorm.ErrDuplicatedKey)
+		mock.ExpectRollback()
+
+		body := bytes.NewBufferString(`{"sku":"TEC-01","name":"Teclado","price":299,"quantity":5,"description":"ABNT2"}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/product", body)
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "sku")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("retorna 200 quando cria com sucesso", func(t *testing.T) {
 		gdb, mock, sqlDB := newMockGorm(t)
 		defer sqlDB.Close()
@@ -109,6 +159,7 @@ func TestCreateProductsHandler(t *testing.T) {
 		mock.ExpectBegin()
 		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `products`")).
 			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectRevision(mock)
 		mock.ExpectCommit()
 
 		body := bytes.NewBufferString(`{"name":"Monitor","price":1299,"quantity":10,"description":"27\" 144Hz"}`)
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Create purchase order
+// @Description Create a draft purchase order to a supplier. Lines without unitCost take the cost registered for the product and the supplier.
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param request body PurchaseOrderRequest true "Request body"
+// @Success 200 {object} PurchaseOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-order [post]
+func CreatePurchaseOrderService(ctx *gin.Context) {
+	var req PurchaseOrderRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lines, ok := resolveOrderLines(ctx, req, "error creating purchase order on database")
+	if !ok {
+		return
+	}
+
+	order := schemas.PurchaseOrder{SupplierID: req.SupplierID, Status: schemas.PurchaseOrderDraft, Notes: req.Notes}
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].PurchaseOrderID = order.ID
+		}
+		return tx.Create(&lines).Error
+	})
+	if err != nil {
+		requestLogger(ctx).Errorf("error creating purchase order: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error creating purchase order on database")
+		return
+	}
+
+	sendPurchaseOrder(ctx, "create-purchase-order", order)
+}
+
+// resolveOrderLines checks the supplier and products of the request and
+// builds the order lines, answering the request itself when it can't.
+func resolveOrderLines(ctx *gin.Context, req PurchaseOrderRequest, failure string) ([]schemas.PurchaseOrderLine, bool) {
+	var supplier schemas.Supplier
+	if err := requestDB(ctx).First(&supplier, req.SupplierID).Error; err != nil {
+		sendError(ctx, http.StatusBadRequest, "supplier not found")
+		return nil, false
+	}
+
+	products, costs, err := loadOrderCatalog(ctx, req)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading order products: %v", err)
+		sendError(ctx, http.StatusInternalServerError, failure)
+		return nil, false
+	}
+	lines, err := req.OrderLines(products, costs)
+	if err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return lines, true
+}
+
+// sendPurchaseOrder answers with the order as it is now, lines included.
+func sendPurchaseOrder(ctx *gin.Context, op string, order schemas.PurchaseOrder) {
+	resp, err := purchaseOrderResponse(ctx, order)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading purchase order lines: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error loading purchase order")
+		return
+	}
+	ctx.JSON(http.StatusOK, PurchaseOrderResponse{
+		Message: fmt.Sprintf("operation from handler: %s successful", op),
+		Data:    resp,
+	})
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Create supplier
+// @Description Register a supplier products can be bought from
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Param request body SupplierRequest true "Request body"
+// @Success 200 {object} SupplierResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /suppliers [post]
+func CreateSupplierService(ctx *gin.Context) {
+	var req SupplierRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	supplier := schemas.Supplier{
+		Name:        req.Name,
+		ContactName: req.ContactName,
+		Email:       req.Email,
+		Phone:       req.Phone,
+	}
+	err := requestDB(ctx).Create(&supplier).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		sendError(ctx, http.StatusConflict, "a supplier with this name already exists")
+		return
+	}
+	if err != nil {
+		requestLogger(ctx).Errorf("error creating supplier: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error creating supplier on database")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SupplierResponse{
+		Message: "operation from handler: create-supplier successful",
+		Data:    toSupplierResponse(supplier),
+	})
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Create webhook
+// @Description Subscribe a URL to product events. Deliveries are signed with a secret that is only returned in this response.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequest true "Request body"
+// @Success 200 {object} WebhookSecretResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /webhooks [post]
+func CreateWebhookService(ctx *gin.Context) {
+	var req WebhookRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.CheckTarget(webhookCfg.AllowPrivateTargets); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		requestLogger(ctx).Errorf("error generating webhook secret: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error generating webhook secret")
+		return
+	}
+
+	sub := schemas.WebhookSubscription{Secret: secret, CreatedBy: middleware.Actor(ctx)}
+	req.Apply(&sub)
+
+	if err := requestDB(ctx).Create(&sub).Error; err != nil {
+		requestLogger(ctx).Errorf("error creating webhook: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error creating webhook on database")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, WebhookSecretResponse{
+		Message: "operation from handler: create-webhook successful",
+		Data:    schemas.WebhookSubscriptionSecretResponse{WebhookSubscriptionResponse: toWebhookResponse(sub), Secret: secret},
+	})
+}
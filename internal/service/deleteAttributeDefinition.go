@@ -0,0 +1,47 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Delete attribute definition
+// @Description Remove an attribute definition. Products keep their values until their next update, which drops them.
+// @Tags Attributes
+// @Accept json
+// @Produce json
+// @Param id query string true "Attribute definition identification"
+// @Success 200 {object} AttributeDefinitionResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /attributes [delete]
+func DeleteAttributeDefinitionService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var def schemas.AttributeDefinition
+	if err := requestDB(ctx).First(&def, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "attribute definition not found")
+		return
+	}
+
+	if err := requestDB(ctx).Delete(&def).Error; err != nil {
+		requestLogger(ctx).Errorf("error deleting attribute definition: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting attribute definition")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AttributeDefinitionResponse{
+		Message: "operation from handler: delete-attribute-definition successful",
+		Data:    toAttributeDefinitionResponse(def),
+	})
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Delete brand
+// @Description Delete a brand no product is linked to
+// @Tags Brands
+// @Accept json
+// @Produce json
+// @Param id query string true "Brand identification"
+// @Success 200 {object} BrandResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /brands [delete]
+func DeleteBrandService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var brand schemas.Brand
+	if err := requestDB(ctx).First(&brand, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "brand not found")
+		return
+	}
+
+	// produtos na lixeira também contam: ainda apontam para a marca
+	var linked int64
+	if err := requestDB(ctx).Unscoped().Model(&schemas.Product{}).Where("brand_id = ?", brand.ID).
+		Count(&linked).Error; err != nil {
+		requestLogger(ctx).Errorf("error counting brand products: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting brand")
+		return
+	}
+	if linked > 0 {
+		sendError(ctx, http.StatusConflict, "brand is linked to products")
+		return
+	}
+
+	if err := requestDB(ctx).Delete(&brand).Error; err != nil {
+		requestLogger(ctx).Errorf("error deleting brand: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting brand")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BrandResponse{
+		Message: "operation from handler: delete-brand successful",
+		Data:    toBrandResponse(brand),
+	})
+}
@@ -4,8 +4,12 @@ import (
 	"fmt"
 	"net/http"
 
-	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
 )
 
 // @BasePath /v1
@@ -19,6 +23,7 @@ import (
 // @Success 200 {object} DeleteProductResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 504 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /product [delete]
 func DeleteProductService(ctx *gin.Context) {
 	id := ctx.Query("id")
@@ -28,14 +33,35 @@ func DeleteProductService(ctx *gin.Context) {
 	}
 	product := schemas.Product{}
 
-	if err := db.First(&product, id).Error; err != nil {
+	var missing error
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// locked so the revision records the row as it is deleted, not as a
+		// concurrent change left it
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
+			missing = err
+			return err
+		}
+		// the soft deleted row would keep the sku taken forever; the
+		// revision still records it, so a rollback can bring it back
+		if product.SKU != nil {
+			if err := tx.Model(&schemas.Product{}).Where("id = ?", product.ID).Update("sku", nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&product).Error; err != nil {
+			return err
+		}
+		return recordRevision(tx, product, schemas.RevisionActionDelete, middleware.Actor(ctx))
+	})
+	if missing != nil {
 		sendError(ctx, http.StatusNotFound, fmt.Sprintf("product with id: %s not found", id))
 		return
 	}
-
-	if err := db.Delete(&product).Error; err != nil {
+	if err != nil {
+		requestLogger(ctx).Errorf("error deleting product: %v", err)
 		sendError(ctx, http.StatusInternalServerError, fmt.Sprintf("error deleting product with id: %s", id))
 		return
 	}
+	unindexProduct(ctx, product)
 	sendSuccess(ctx, "delete-product", product)
 }
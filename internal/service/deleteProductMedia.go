@@ -0,0 +1,54 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Delete product image
+// @Description Remove an image of a product, with its files and variants
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param mediaId query string true "Image identification"
+// @Success 200 {object} ProductMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/media [delete]
+func DeleteProductMediaService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	mediaID := ctx.Query("mediaId")
+	if mediaID == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("mediaId", "queryParameter").Error())
+		return
+	}
+
+	var m schemas.ProductMedia
+	if err := requestDB(ctx).Where("product_id = ?", id).First(&m, mediaID).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "image not found")
+		return
+	}
+
+	if err := requestDB(ctx).Delete(&m).Error; err != nil {
+		requestLogger(ctx).Errorf("error deleting product media: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting image")
+		return
+	}
+	removeMedia(ctx, m)
+
+	ctx.JSON(http.StatusOK, ProductMediaResponse{
+		Message: "operation from handler: delete-product-media successful",
+		Data:    toMediaResponse(m),
+	})
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Delete product supplier
+// @Description Unlink a supplier from a product
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param supplierId query string true "Supplier identification"
+// @Success 200 {object} ProductSupplierResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/suppliers [delete]
+func DeleteProductSupplierService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	supplierID := ctx.Query("supplierId")
+	if supplierID == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("supplierId", "queryParameter").Error())
+		return
+	}
+
+	var link schemas.ProductSupplier
+	if err := requestDB(ctx).Where("product_id = ? AND supplier_id = ?", id, supplierID).Take(&link).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "product supplier not found")
+		return
+	}
+
+	if err := requestDB(ctx).Delete(&link).Error; err != nil {
+		requestLogger(ctx).Errorf("error deleting product supplier: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting product supplier")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ProductSupplierResponse{
+		Message: "operation from handler: delete-product-supplier successful",
+		Data:    toProductSupplierResponse(link, ""),
+	})
+}
@@ -1,12 +1,14 @@
 package service
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"regexp"
 	"testing"
 	"time"
 
@@ -72,7 +74,9 @@ func TestDeleteProductHandler(t *testing.T) {
 		// SELECT ultra-tolerante (case-insensitive + dotall)
 		selectRegex := `(?is)SELECT.*FROM.*products.*WHERE.*id`
 		// força "não encontrado"
+		mock.ExpectBegin()
 		mock.ExpectQuery(selectRegex).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
 
 		u := url.URL{Path: "/v1/product"}
 		q := u.Query()
@@ -102,9 +106,9 @@ func TestDeleteProductHandler(t *testing.T) {
 		now := time.Now()
 		row := sqlmock.NewRows(cols).AddRow(42, "Mouse", 199, 3, "sem fio", now, now, nil)
 
+		mock.ExpectBegin()
 		mock.ExpectQuery(selectRegex).WillReturnRows(row)
 
-		mock.ExpectBegin()
 		if useSoftDelete {
 
 			updateRegex := `(?is)UPDATE.*products.*SET.*deleted_at.*WHERE.*id`
@@ -148,9 +152,9 @@ func TestDeleteProductHandler(t *testing.T) {
 		now := time.Now()
 		row := sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 5, "ABNT2", now, now, nil)
 
+		mock.ExpectBegin()
 		mock.ExpectQuery(selectRegex).WillReturnRows(row)
 
-		mock.ExpectBegin()
 		if useSoftDelete {
 			updateRegex := `(?is)UPDATE.*products.*SET.*deleted_at.*WHERE.*id`
 			mock.ExpectExec(updateRegex).
@@ -162,6 +166,7 @@ func TestDeleteProductHandler(t *testing.T) {
 				WithArgs(7).
 				WillReturnResult(sqlmock.NewResult(0, 1))
 		}
+		expectRevision(mock)
 		mock.ExpectCommit()
 
 		u := url.URL{Path: "/v1/product"}
@@ -194,4 +199,46 @@ func TestDeleteProductHandler(t *testing.T) {
 
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("libera o sku do produto apagado para um novo produto", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGormDelete(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		cols := []string{"id", "sku", "name", "price", "quantity", "created_at", "updated_at", "deleted_at"}
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE `products`.`id` = ? AND `products`.`deleted_at` IS NULL ORDER BY `products`.`id` LIMIT ? FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(7, "TEC-01", "Teclado", 299, 5, now, now, nil))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `products` SET `sku`=?,`updated_at`=? WHERE id = ? AND `products`.`deleted_at` IS NULL")).
+			WithArgs(nil, sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*deleted_at.*WHERE.*id`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/product?id=7", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `products`")).
+			WillReturnResult(sqlmock.NewResult(8, 1))
+		expectRevision(mock)
+		mock.ExpectCommit()
+
+		create := setupGin()
+		body := bytes.NewBufferString(`{"sku":"TEC-01","name":"Teclado novo","price":399,"quantity":2,"description":"ABNT2"}`)
+		req = httptest.NewRequest(http.MethodPost, "/v1/product", body)
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		create.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"sku":"TEC-01"`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
 }
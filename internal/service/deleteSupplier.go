@@ -0,0 +1,72 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Delete supplier
+// @Description Delete a supplier no product is bought from and no purchase order references
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Param id query string true "Supplier identification"
+// @Success 200 {object} SupplierResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /suppliers [delete]
+func DeleteSupplierService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var supplier schemas.Supplier
+	if err := requestDB(ctx).First(&supplier, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "supplier not found")
+		return
+	}
+
+	var linked int64
+	if err := requestDB(ctx).Model(&schemas.ProductSupplier{}).Where("supplier_id = ?", supplier.ID).
+		Count(&linked).Error; err != nil {
+		requestLogger(ctx).Errorf("error counting supplier products: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting supplier")
+		return
+	}
+	if linked > 0 {
+		sendError(ctx, http.StatusConflict, "supplier is linked to products")
+		return
+	}
+
+	var orders int64
+	if err := requestDB(ctx).Model(&schemas.PurchaseOrder{}).Where("supplier_id = ?", supplier.ID).
+		Count(&orders).Error; err != nil {
+		requestLogger(ctx).Errorf("error counting supplier purchase orders: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting supplier")
+		return
+	}
+	if orders > 0 {
+		sendError(ctx, http.StatusConflict, "supplier has purchase orders")
+		return
+	}
+
+	if err := requestDB(ctx).Delete(&supplier).Error; err != nil {
+		requestLogger(ctx).Errorf("error deleting supplier: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting supplier")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SupplierResponse{
+		Message: "operation from handler: delete-supplier successful",
+		Data:    toSupplierResponse(supplier),
+	})
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Delete webhook
+// @Description Delete a webhook together with its delivery log. Pending deliveries are dropped.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id query string true "Webhook identification"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /webhooks [delete]
+func DeleteWebhookService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var sub schemas.WebhookSubscription
+	if err := requestDB(ctx).First(&sub, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "webhook not found")
+		return
+	}
+
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", sub.ID).Delete(&schemas.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&sub).Error
+	})
+	if err != nil {
+		requestLogger(ctx).Errorf("error deleting webhook: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error deleting webhook")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, WebhookResponse{
+		Message: "operation from handler: delete-webhook successful",
+		Data:    toWebhookResponse(sub),
+	})
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Diff product revisions
+// @Description Field-level differences between two revisions of a product
+// @Tags Revisions
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param from query int true "Base revision"
+// @Param to query int true "Target revision"
+// @Success 200 {object} DiffProductRevisionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/revisions/diff [get]
+func DiffProductRevisionsService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var q struct {
+		From uint `form:"from" binding:"required"`
+		To   uint `form:"to" binding:"required"`
+	}
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("from/to", "queryParameter").Error())
+		return
+	}
+
+	var from, to schemas.ProductRevision
+	if err := requestDB(ctx).Where("product_id = ? AND revision = ?", id, q.From).First(&from).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "revision not found")
+		return
+	}
+	if err := requestDB(ctx).Where("product_id = ? AND revision = ?", id, q.To).First(&to).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "revision not found")
+		return
+	}
+
+	changes, err := diffRevisions(from, to)
+	if err != nil {
+		requestLogger(ctx).Errorf("%v", err)
+		sendError(ctx, http.StatusInternalServerError, "error decoding product revision")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DiffProductRevisionsResponse{
+		Message: "operation from handler: diff-product-revisions successful",
+		Data: schemas.ProductRevisionDiffResponse{
+			ProductID: from.ProductID,
+			From:      from.Revision,
+			To:        to.Revision,
+			Changes:   changes,
+		},
+	})
+}
@@ -0,0 +1,21 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/outbox"
+	"github.com/alissonmunhoz/go-crud-products/internal/webhook"
+	"gorm.io/gorm"
+)
+
+// publishEvent records a product event in the transaction that made the
+// change: in the outbox for the broker, and as deliveries for the webhooks
+// that listen to it. Nothing is sent until the transaction commits.
+func publishEvent(tx *gorm.DB, eventType string, productID uint, data any) error {
+	event, err := outbox.NewEvent(tx.Statement.Context, eventType, productID, data)
+	if err != nil {
+		return err
+	}
+	if err := outbox.Record(tx, event); err != nil {
+		return err
+	}
+	return webhook.Enqueue(tx, event)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+// expectOutbox espera a gravação de um evento no outbox
+func expectOutbox(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox_events`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
+// capture aceita qualquer valor e guarda o último recebido
+type capture struct{ value driver.Value }
+
+func (c *capture) Match(v driver.Value) bool {
+	c.value = v
+	return true
+}
+
+func TestPublishEvent(t *testing.T) {
+	gdb, mock, sqlDB := newMockGorm(t)
+	defer sqlDB.Close()
+
+	var outboxID, outboxPayload, deliveryID, deliveryPayload capture
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `outbox_events`")).
+		WithArgs(sqlmock.AnyArg(), &outboxID, schemas.EventStockChanged, 7, &outboxPayload, 0, "", nil, nil, nil, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectWebhooks(mock, []driver.Value{2, "https://erp.example.com/hook", "stock.changed", "whsec_b", true})
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_deliveries`")).
+		WithArgs(sqlmock.AnyArg(), 2, &deliveryID, schemas.EventStockChanged, &deliveryPayload,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	ctx := tenant.WithTenant(context.Background(), "loja1")
+	err := gdb.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return publishEvent(tx, schemas.EventStockChanged, 7, map[string]int{"quantity": 3})
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// o broker e o webhook recebem o mesmo evento, com o mesmo id
+	require.Equal(t, outboxID.value, deliveryID.value)
+	require.Equal(t, outboxPayload.value, deliveryPayload.value)
+
+	var event schemas.Event
+	require.NoError(t, json.Unmarshal([]byte(outboxPayload.value.(string)), &event))
+	require.Equal(t, outboxID.value, event.ID)
+	require.Equal(t, "loja1", event.TenantID)
+	require.Equal(t, uint(7), event.ProductID)
+	require.JSONEq(t, `{"quantity":3}`, string(event.Data))
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find all API keys
+// @Description List API keys, including revoked and expired ones. Secrets are never returned.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} FindAllAPIKeysResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /apikeys [get]
+func FindAllAPIKeysService(ctx *gin.Context) {
+	var keys []schemas.APIKey
+	if err := requestDB(ctx).Order("id").Find(&keys).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing api keys: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing api keys")
+		return
+	}
+
+	resp := make([]schemas.APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, toAPIKeyResponse(k))
+	}
+
+	ctx.JSON(http.StatusOK, FindAllAPIKeysResponse{
+		Message: "operation from handler: list-api-keys successful",
+		Data:    resp,
+	})
+}
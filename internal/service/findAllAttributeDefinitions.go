@@ -0,0 +1,44 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find all attribute definitions
+// @Description List the custom attribute definitions, optionally of a single category
+// @Tags Attributes
+// @Accept json
+// @Produce json
+// @Param category query string false "Category"
+// @Success 200 {object} FindAllAttributeDefinitionsResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /attributes [get]
+func FindAllAttributeDefinitionsService(ctx *gin.Context) {
+	query := requestDB(ctx).Order("category, id")
+	if category := ctx.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+
+	var defs []schemas.AttributeDefinition
+	if err := query.Find(&defs).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing attribute definitions: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing attribute definitions")
+		return
+	}
+
+	resp := make([]schemas.AttributeDefinitionResponse, 0, len(defs))
+	for _, d := range defs {
+		resp = append(resp, toAttributeDefinitionResponse(d))
+	}
+
+	ctx.JSON(http.StatusOK, FindAllAttributeDefinitionsResponse{
+		Message: "operation from handler: list-attribute-definitions successful",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find all brands
+// @Description List the registered brands by name
+// @Tags Brands
+// @Accept json
+// @Produce json
+// @Success 200 {object} FindAllBrandsResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /brands [get]
+func FindAllBrandsService(ctx *gin.Context) {
+	var brands []schemas.Brand
+	if err := requestDB(ctx).Order("name").Find(&brands).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing brands: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing brands")
+		return
+	}
+
+	resp := make([]schemas.BrandResponse, 0, len(brands))
+	for _, b := range brands {
+		resp = append(resp, toBrandResponse(b))
+	}
+
+	ctx.JSON(http.StatusOK, FindAllBrandsResponse{
+		Message: "operation from handler: list-brands successful",
+		Data:    resp,
+	})
+}
@@ -5,32 +5,93 @@ import (
 	"net/http"
 
 	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
 	"github.com/gin-gonic/gin"
 )
 
 // @BasePath /v1
 // @Summary Find All products
-// @Description Find all products
+// @Description Find all products, optionally filtered. With facets=true the response also counts the products per category, brand, status, price range and stock, each facet ignoring its own filter.
 // @Tags Products
 // @Accept json
 // @Produce json
+// @Param category query []string false "Category (repeat for any of several)" collectionFormat(multi)
+// @Param brand query []string false "Brand (repeat for any of several)" collectionFormat(multi)
+// @Param status query []string false "Status: active, draft or archived" collectionFormat(multi)
+// @Param minPrice query int false "Lowest price (inclusive)"
+// @Param maxPrice query int false "Highest price (exclusive)"
+// @Param inStock query bool false "Only products with (true) or without (false) stock"
+// @Param attr[key] query string false "Custom attribute: a value (repeat for any of several) or, for numbers, a range such as 1..5, ..5 or 1.."
+// @Param sort query string false "name, price, quantity, createdAt or attr.<key>, with a leading - for descending order"
+// @Param facets query bool false "Include facet counts; with a category filter, also for its enum and boolean attributes (attr.<key>)"
 // @Success 200 {object} FindAllProductsResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /products [get]
 func FindAllProductsService(ctx *gin.Context) {
+	var q FindAllProductsQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	q.Attributes = attributeParams(ctx.Request.URL.Query())
+	if err := q.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defs, err := attributeDefinitions(ctx, q.AttributeKeys())
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading attribute definitions: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing products")
+		return
+	}
+	filter := q.Filter()
+	if filter.Attributes, err = q.AttributeFilters(defs); err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	order, err := q.Order(defs)
+	if err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := requestDB(ctx).Scopes(filter.Scope(""))
+	if order != nil {
+		query = query.Order(*order)
+	}
 	var products []schemas.Product
-	if err := db.Find(&products).Error; err != nil {
+	if err := query.Find(&products).Error; err != nil {
 		sendError(ctx, http.StatusInternalServerError, "error listing products")
 		return
 	}
 
-	resp := make([]schemas.ProductResponse, 0, len(products))
-	for _, p := range products {
-		resp = append(resp, toProductResponse(p))
+	var facets []schemas.Facet
+	if q.Facets {
+		spec, err := facetsFor(ctx, filter)
+		if err == nil {
+			facets, err = search.CountFacets(requestDB(ctx), nil, filter, spec)
+		}
+		if err != nil {
+			requestLogger(ctx).Errorf("error counting facets: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error listing products")
+			return
+		}
+	}
+
+	resp, err := productResponses(ctx, products)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading product details: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing products")
+		return
 	}
 
 	ctx.JSON(http.StatusOK, FindAllProductsResponse{
 		Message: "operation from handler: list-products successful",
 		Data:    resp,
+		Facets:  facets,
 	})
 }
@@ -17,6 +17,7 @@ import (
 	glogger "gorm.io/gorm/logger"
 
 	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
 )
 
 func init() { logger = config.GetLogger("test") }
@@ -84,6 +85,7 @@ func TestFindAllProductsHandler(t *testing.T) {
 			AddRow(2, "Teclado", 299, 5, "ABNT2", now, now, nil)
 
 		mock.ExpectQuery(selectRegex).WillReturnRows(rows)
+		expectMedia(mock)
 
 		req := httptest.NewRequest(http.MethodGet, "/v1/products", nil)
 		w := httptest.NewRecorder()
@@ -119,4 +121,53 @@ func TestFindAllProductsHandler(t *testing.T) {
 
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("filtra e conta facetas", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGormFindAll(t)
+		defer sqlDB.Close()
+		orig, origSpec := db, facetSpec
+		db, facetSpec = gdb, search.FacetSpec{Fields: []string{search.FacetBrand}}
+		defer func() { db, facetSpec = orig, origSpec }()
+
+		mock.ExpectQuery(`SELECT \* FROM .products. WHERE category IN \(\?,\?\) AND price < \?`).
+			WithArgs("games", "perifericos", 10000).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "category", "brand", "status"}).
+				AddRow(1, "Mouse", "perifericos", "Acme", "active"))
+		mock.ExpectQuery("SELECT `key` FROM `attribute_definitions` WHERE category IN \\(\\?,\\?\\) AND type IN \\(\\?,\\?\\) ORDER BY id").
+			WithArgs("games", "perifericos", "enum", "boolean").
+			WillReturnRows(sqlmock.NewRows([]string{"key"}).AddRow("wireless"))
+		mock.ExpectQuery(`SELECT brand AS value, COUNT\(\*\) AS count FROM .products. WHERE brand <> '' AND category IN \(\?,\?\) AND price < \?`).
+			WithArgs("games", "perifericos", 10000).
+			WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("Acme", 1))
+		mock.ExpectQuery(`SELECT JSON_UNQUOTE\(JSON_EXTRACT\(attributes, \?\)\) AS value, COUNT\(\*\) AS count FROM .products.`).
+			WithArgs(`$."wireless"`, `$."wireless"`, "games", "perifericos", 10000).
+			WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).AddRow("true", 1))
+		expectMedia(mock)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products?category=games&category=perifericos&maxPrice=10000&facets=true", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body FindAllProductsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 1)
+		require.Equal(t, "perifericos", body.Data[0].Category)
+		require.Equal(t, "Acme", body.Data[0].Brand)
+		require.Len(t, body.Facets, 2)
+		require.Equal(t, "brand", body.Facets[0].Field)
+		require.EqualValues(t, 1, body.Facets[0].Values[0].Count)
+		require.Equal(t, "attr.wireless", body.Facets[1].Field)
+		require.Equal(t, "true", body.Facets[1].Values[0].Value)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 400 para faixa de preço invertida", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/products?minPrice=500&maxPrice=100", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "minPrice must be lower than maxPrice")
+	})
 }
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find all purchase orders
+// @Description List the purchase orders, newest first, optionally of a status or supplier
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param status query string false "draft, sent, partially_received, received or cancelled"
+// @Param supplierId query string false "Supplier identification"
+// @Success 200 {object} FindAllPurchaseOrdersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-orders [get]
+func FindAllPurchaseOrdersService(ctx *gin.Context) {
+	query := requestDB(ctx).Order("id DESC")
+	if status := ctx.Query("status"); status != "" {
+		if !schemas.IsPurchaseOrderStatus(status) {
+			sendError(ctx, http.StatusBadRequest, "status must be draft, sent, partially_received, received or cancelled")
+			return
+		}
+		query = query.Where("status = ?", status)
+	}
+	if supplierID := ctx.Query("supplierId"); supplierID != "" {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+
+	var orders []schemas.PurchaseOrder
+	if err := query.Find(&orders).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing purchase orders: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing purchase orders")
+		return
+	}
+
+	resp, err := purchaseOrderResponses(ctx, orders)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading purchase order lines: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing purchase orders")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, FindAllPurchaseOrdersResponse{
+		Message: "operation from handler: list-purchase-orders successful",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find all suppliers
+// @Description List the registered suppliers by name
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Success 200 {object} FindAllSuppliersResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /suppliers [get]
+func FindAllSuppliersService(ctx *gin.Context) {
+	var suppliers []schemas.Supplier
+	if err := requestDB(ctx).Order("name").Find(&suppliers).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing suppliers: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing suppliers")
+		return
+	}
+
+	resp := make([]schemas.SupplierResponse, 0, len(suppliers))
+	for _, s := range suppliers {
+		resp = append(resp, toSupplierResponse(s))
+	}
+
+	ctx.JSON(http.StatusOK, FindAllSuppliersResponse{
+		Message: "operation from handler: list-suppliers successful",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find all webhooks
+// @Description List the webhook subscriptions, including paused ones. Secrets are never returned.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} FindAllWebhooksResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /webhooks [get]
+func FindAllWebhooksService(ctx *gin.Context) {
+	var subs []schemas.WebhookSubscription
+	if err := requestDB(ctx).Order("id").Find(&subs).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing webhooks: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing webhooks")
+		return
+	}
+
+	resp := make([]schemas.WebhookSubscriptionResponse, 0, len(subs))
+	for _, s := range subs {
+		resp = append(resp, toWebhookResponse(s))
+	}
+
+	ctx.JSON(http.StatusOK, FindAllWebhooksResponse{
+		Message: "operation from handler: list-webhooks successful",
+		Data:    resp,
+	})
+}
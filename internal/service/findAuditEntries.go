@@ -0,0 +1,102 @@
+package service
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find audit entries
+// @Description Filtered, paginated view of the audit trail, newest first
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Param actor query string false "Actor"
+// @Param method query string false "HTTP method"
+// @Param status query int false "Response status code"
+// @Param requestId query string false "Request ID"
+// @Param from query string false "Lower bound (RFC3339)"
+// @Param to query string false "Upper bound (RFC3339)"
+// @Param page query int false "Page (starts at 1)"
+// @Param pageSize query int false "Page size (max 200)"
+// @Success 200 {object} FindAuditEntriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /audit [get]
+func FindAuditEntriesService(ctx *gin.Context) {
+	var q FindAuditEntriesQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	q.Normalize()
+
+	query := requestDB(ctx).Model(&schemas.AuditEntry{})
+	if q.Actor != "" {
+		query = query.Where("actor = ?", q.Actor)
+	}
+	if q.Method != "" {
+		query = query.Where("method = ?", strings.ToUpper(q.Method))
+	}
+	if q.Status != 0 {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.RequestID != "" {
+		query = query.Where("request_id = ?", q.RequestID)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("created_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		query = query.Where("created_at <= ?", q.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		requestLogger(ctx).Errorf("error counting audit entries: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing audit entries")
+		return
+	}
+
+	var entries []schemas.AuditEntry
+	if err := query.Order("id DESC").Offset(q.Offset()).Limit(q.PageSize).Find(&entries).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing audit entries: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing audit entries")
+		return
+	}
+
+	resp := make([]schemas.AuditEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, toAuditEntryResponse(e))
+	}
+
+	ctx.JSON(http.StatusOK, FindAuditEntriesResponse{
+		Message:    "operation from handler: list-audit-entries successful",
+		Data:       resp,
+		Pagination: schemas.Pagination{Page: q.Page, PageSize: q.PageSize, Total: total},
+	})
+}
+
+func toAuditEntryResponse(e schemas.AuditEntry) schemas.AuditEntryResponse {
+	return schemas.AuditEntryResponse{
+		ID:        e.ID,
+		TenantID:  e.TenantID,
+		Actor:     e.Actor,
+		IP:        e.IP,
+		RequestID: e.RequestID,
+		Method:    e.Method,
+		Route:     e.Route,
+		Path:      e.Path,
+		Status:    e.Status,
+		LatencyMs: e.LatencyMs,
+		PrevHash:  e.PrevHash,
+		Hash:      e.Hash,
+		CreatedAt: e.CreatedAt,
+	}
+}
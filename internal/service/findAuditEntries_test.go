@@ -0,0 +1,83 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func setupGinAudit() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/audit", FindAuditEntriesService)
+	return r
+}
+
+func TestFindAuditEntriesHandler(t *testing.T) {
+	r := setupGinAudit()
+
+	t.Run("retorna 400 quando data é inválida", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/audit?from=ontem", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "invalid query parameters")
+	})
+
+	t.Run("retorna 500 quando DB falha", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT count.*FROM.*audit_entries`).WillReturnError(errors.New("db down"))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/audit", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 200 filtrando e paginando", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT count.*FROM.*audit_entries.*WHERE actor = \? AND method = \?`).
+			WithArgs("maria", "DELETE").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+
+		cols := []string{"id", "actor", "ip", "request_id", "method", "route", "path", "status", "latency_ms", "prev_hash", "hash", "created_at"}
+		mock.ExpectQuery(`(?is)SELECT \* FROM .audit_entries.*ORDER BY id DESC LIMIT \? OFFSET \?`).
+			WithArgs("maria", "DELETE", 10, 10).
+			WillReturnRows(sqlmock.NewRows(cols).
+				AddRow(1, "maria", "10.0.0.1", "req-1", "DELETE", "/v1/product", "/v1/product?id=7", 200, 3, "", "abc", time.Now()))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/audit?actor=maria&method=delete&page=2&pageSize=10", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body FindAuditEntriesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 1)
+		require.Equal(t, "req-1", body.Data[0].RequestID)
+		require.Equal(t, int64(11), body.Pagination.Total)
+		require.Equal(t, 2, body.Pagination.Page)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
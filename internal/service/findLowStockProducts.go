@@ -0,0 +1,81 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find low stock products
+// @Description List the products at or below their reorder point, furthest below first, with the units already on order from sent purchase orders and a suggested order quantity
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Success 200 {object} FindLowStockProductsResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/low-stock [get]
+func FindLowStockProductsService(ctx *gin.Context) {
+	var products []schemas.Product
+	err := requestDB(ctx).
+		Where("reorder_point IS NOT NULL AND quantity <= reorder_point").
+		Order("quantity - reorder_point, id").
+		Find(&products).Error
+	if err != nil {
+		requestLogger(ctx).Errorf("error listing low stock products: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing low stock products")
+		return
+	}
+
+	onOrder, err := productsOnOrder(ctx, products)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading products on order: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing low stock products")
+		return
+	}
+
+	resp := make([]schemas.LowStockProductResponse, 0, len(products))
+	for _, p := range products {
+		resp = append(resp, toLowStockProductResponse(p, onOrder[p.ID]))
+	}
+
+	ctx.JSON(http.StatusOK, FindLowStockProductsResponse{
+		Message: "operation from handler: list-low-stock-products successful",
+		Data:    resp,
+	})
+}
+
+// productsOnOrder sums, per product, the units not yet received on purchase
+// orders the supplier already has.
+func productsOnOrder(ctx *gin.Context, products []schemas.Product) (map[uint]int64, error) {
+	onOrder := map[uint]int64{}
+	if len(products) == 0 {
+		return onOrder, nil
+	}
+	ids := make([]uint, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	var rows []struct {
+		ProductID uint
+		OnOrder   int64
+	}
+	err := requestDB(ctx).Model(&schemas.PurchaseOrderLine{}).
+		Select("purchase_order_lines.product_id, SUM(purchase_order_lines.quantity - purchase_order_lines.received) AS on_order").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_lines.purchase_order_id").
+		Where("purchase_orders.status IN ? AND purchase_order_lines.product_id IN ?",
+			[]string{schemas.PurchaseOrderSent, schemas.PurchaseOrderPartiallyReceived}, ids).
+		Group("purchase_order_lines.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		onOrder[r.ProductID] = r.OnOrder
+	}
+	return onOrder, nil
+}
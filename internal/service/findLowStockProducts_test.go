@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func setupGinLowStock() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(auth.RoleViewer))
+	r.GET("/v1/products/low-stock", FindLowStockProductsService)
+	return r
+}
+
+func TestFindLowStockProducts(t *testing.T) {
+	r := setupGinLowStock()
+
+	t.Run("lista abaixo do ponto descontando o que já foi pedido", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE (reorder_point IS NOT NULL AND quantity <= reorder_point)")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "sku", "name", "quantity", "reorder_point", "reorder_quantity"}).
+				AddRow(3, "MS-01", "Mouse", 0, 5, 50).
+				AddRow(7, nil, "Teclado", 4, 5, 20))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT purchase_order_lines.product_id, SUM(purchase_order_lines.quantity - purchase_order_lines.received) AS on_order FROM `purchase_order_lines` JOIN purchase_orders")).
+			WithArgs(schemas.PurchaseOrderSent, schemas.PurchaseOrderPartiallyReceived, 3, 7).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "on_order"}).
+				AddRow(3, 30).
+				AddRow(7, 25))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/low-stock", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body FindLowStockProductsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, []schemas.LowStockProductResponse{
+			{ID: 3, SKU: "MS-01", Name: "Mouse", Quantity: 0, ReorderPoint: 5, ReorderQuantity: 50, OnOrder: 30, SuggestedOrder: 20},
+			{ID: 7, Name: "Teclado", Quantity: 4, ReorderPoint: 5, ReorderQuantity: 20, OnOrder: 25, SuggestedOrder: 0},
+		}, body.Data)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("sem produtos baixos não consulta pedidos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/low-stock", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"message":"operation from handler: list-low-stock-products successful","data":[]}`, w.Body.String())
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
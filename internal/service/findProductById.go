@@ -18,6 +18,7 @@ import (
 // @Success 200 {object} FindProductResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /product [get]
 func FindProductService(ctx *gin.Context) {
 	id := ctx.Query("id")
@@ -26,10 +27,20 @@ func FindProductService(ctx *gin.Context) {
 		return
 	}
 	product := schemas.Product{}
-	if err := db.First(&product, id).Error; err != nil {
+	if err := requestDB(ctx).First(&product, id).Error; err != nil {
 		sendError(ctx, http.StatusNotFound, "product not found")
 		return
 	}
 
-	sendSuccess(ctx, "show-product", product)
+	resp, err := productResponses(ctx, []schemas.Product{product})
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading product details: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error finding product")
+		return
+	}
+
+	if suggester != nil {
+		suggester.Viewed(product)
+	}
+	sendSuccess(ctx, "show-product", resp[0])
 }
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary List product images
+// @Description List the images of a product in display order, with the URLs of their variants
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Success 200 {object} FindProductMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/media [get]
+func FindProductMediaService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	product := schemas.Product{}
+	if err := requestDB(ctx).First(&product, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "product not found")
+		return
+	}
+
+	byProduct, err := loadMedia(ctx, product.ID)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading product media: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing images")
+		return
+	}
+
+	resp := toMediaResponses(byProduct[product.ID])
+	if resp == nil {
+		resp = []schemas.MediaResponse{}
+	}
+	ctx.JSON(http.StatusOK, FindProductMediaResponse{
+		Message: "operation from handler: list-product-media successful",
+		Data:    resp,
+	})
+}
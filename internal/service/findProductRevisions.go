@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find product revisions
+// @Description List every stored revision of a product, oldest first
+// @Tags Revisions
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Success 200 {object} FindProductRevisionsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/revisions [get]
+func FindProductRevisionsService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var revisions []schemas.ProductRevision
+	if err := requestDB(ctx).Where("product_id = ?", id).Order("revision").Find(&revisions).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing revisions: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing product revisions")
+		return
+	}
+
+	resp := make([]schemas.ProductRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		rev, err := toProductRevisionResponse(r)
+		if err != nil {
+			requestLogger(ctx).Errorf("%v", err)
+			sendError(ctx, http.StatusInternalServerError, "error decoding product revision")
+			return
+		}
+		resp = append(resp, rev)
+	}
+
+	ctx.JSON(http.StatusOK, FindProductRevisionsResponse{
+		Message: "operation from handler: list-product-revisions successful",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary List product suppliers
+// @Description List the suppliers of a product with their codes, costs and lead times, cheapest first
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Success 200 {object} FindProductSuppliersResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/suppliers [get]
+func FindProductSuppliersService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	product := schemas.Product{}
+	if err := requestDB(ctx).First(&product, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "product not found")
+		return
+	}
+
+	byProduct, err := loadSuppliers(ctx, product.ID)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading product suppliers: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing product suppliers")
+		return
+	}
+
+	resp := byProduct[product.ID]
+	if resp == nil {
+		resp = []schemas.ProductSupplierResponse{}
+	}
+	ctx.JSON(http.StatusOK, FindProductSuppliersResponse{
+		Message: "operation from handler: list-product-suppliers successful",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find purchase order
+// @Description Show a purchase order with its lines and how much of each has been received
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param id query string true "Purchase order identification"
+// @Success 200 {object} PurchaseOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-order [get]
+func FindPurchaseOrderService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var order schemas.PurchaseOrder
+	if err := requestDB(ctx).First(&order, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "purchase order not found")
+		return
+	}
+
+	sendPurchaseOrder(ctx, "show-purchase-order", order)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find product stock history
+// @Description List the changes to the stock of a product, oldest first: goods received from purchase orders, manual adjustments and rollbacks
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Success 200 {object} FindStockMovementsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/stock [get]
+func FindStockMovementsService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var movements []schemas.StockMovement
+	if err := requestDB(ctx).Where("product_id = ?", id).Order("id").Find(&movements).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing stock movements: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing stock history")
+		return
+	}
+
+	resp := make([]schemas.StockMovementResponse, 0, len(movements))
+	for _, m := range movements {
+		resp = append(resp, toStockMovementResponse(m))
+	}
+
+	ctx.JSON(http.StatusOK, FindStockMovementsResponse{
+		Message: "operation from handler: list-stock-movements successful",
+		Data:    resp,
+	})
+}
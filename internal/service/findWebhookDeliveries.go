@@ -0,0 +1,72 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Find webhook deliveries
+// @Description Paginated delivery log of a webhook, newest first, with the attempts made and the status code the receiver last answered
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id query string true "Webhook identification"
+// @Param status query string false "pending, succeeded or failed"
+// @Param event query string false "Event type"
+// @Param page query int false "Page (starts at 1)"
+// @Param pageSize query int false "Page size (max 200)"
+// @Success 200 {object} FindWebhookDeliveriesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /webhooks/deliveries [get]
+func FindWebhookDeliveriesService(ctx *gin.Context) {
+	var q FindWebhookDeliveriesQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	if q.ID == 0 {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	q.Normalize()
+
+	query := requestDB(ctx).Model(&schemas.WebhookDelivery{}).Where("subscription_id = ?", q.ID)
+	if q.Status != "" {
+		query = query.Where("status = ?", q.Status)
+	}
+	if q.Event != "" {
+		query = query.Where("event = ?", q.Event)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		requestLogger(ctx).Errorf("error counting webhook deliveries: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing webhook deliveries")
+		return
+	}
+
+	var deliveries []schemas.WebhookDelivery
+	if err := query.Order("id DESC").Offset(q.Offset()).Limit(q.PageSize).Find(&deliveries).Error; err != nil {
+		requestLogger(ctx).Errorf("error listing webhook deliveries: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error listing webhook deliveries")
+		return
+	}
+
+	resp := make([]schemas.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, toWebhookDeliveryResponse(d))
+	}
+
+	ctx.JSON(http.StatusOK, FindWebhookDeliveriesResponse{
+		Message:    "operation from handler: list-webhook-deliveries successful",
+		Data:       resp,
+		Pagination: schemas.Pagination{Page: q.Page, PageSize: q.PageSize, Total: total},
+	})
+}
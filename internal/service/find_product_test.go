@@ -90,6 +90,7 @@ func TestFindProductService(t *testing.T) {
 		row := sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 5, "ABNT2", now, now, nil)
 
 		mock.ExpectQuery(selectRegex).WillReturnRows(row)
+		expectMedia(mock)
 
 		req := httptest.NewRequest(http.MethodGet, "/v1/product?id=7", nil)
 		w := httptest.NewRecorder()
@@ -2,15 +2,94 @@ package service
 
 import (
 	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/alissonmunhoz/go-crud-products/internal/stockalert"
+	"github.com/alissonmunhoz/go-crud-products/internal/tracing"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
 	"gorm.io/gorm"
 )
 
 var (
-	logger *config.Logger
-	db     *gorm.DB
+	logger      *config.Logger
+	db          *gorm.DB
+	searchIndex search.Index
+	suggester   *search.Suggester
+	facetSpec   search.FacetSpec
+	mediaStore  media.Storage
+	mediaCfg    config.MediaConfig
+	mediaSlots  chan struct{}
+	stockAlerts *stockalert.Evaluator
+	webhookCfg  config.WebhooksConfig
 )
 
-func InitializeHandler() {
+func InitializeHandler(index search.Index, names *search.Suggester, facets search.FacetSpec, store media.Storage, alerts *stockalert.Evaluator) {
 	logger = config.GetLogger("handler")
 	db = config.GetMySQL()
+	searchIndex = index
+	suggester = names
+	facetSpec = facets
+	mediaStore = store
+	mediaCfg = config.GetMedia()
+	mediaSlots = make(chan struct{}, mediaCfg.MaxConcurrent)
+	stockAlerts = alerts
+	webhookCfg = config.GetWebhooks()
+}
+
+// requestDB binds the shared connection to the request context, so tenant
+// scoping and cancellation follow the request.
+func requestDB(ctx *gin.Context) *gorm.DB {
+	return db.WithContext(ctx.Request.Context())
+}
+
+// requestLogger carries the request fields (request ID, route, user, tenant)
+// and trace id into handler logs.
+func requestLogger(ctx *gin.Context) *config.Logger {
+	return logger.WithContext(ctx.Request.Context())
+}
+
+// bindJSON decodes the request body inside its own span, so slow or invalid
+// payloads show up apart from the database work in a trace.
+func bindJSON(ctx *gin.Context, obj any) error {
+	_, span := tracing.Tracer().Start(ctx.Request.Context(), "bind request body")
+	defer span.End()
+
+	err := ctx.ShouldBindJSON(obj)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "invalid request body")
+	}
+	return err
+}
+
+// indexProduct and unindexProduct keep the search index and the name
+// suggestions in step with a committed change. The change already
+// succeeded, so failures are only logged; either may be unset when the
+// handlers run without InitializeHandler.
+func indexProduct(ctx *gin.Context, p schemas.Product) {
+	if searchIndex != nil {
+		if err := searchIndex.Put(ctx.Request.Context(), p); err != nil {
+			requestLogger(ctx).Errorf("error indexing product %d: %v", p.ID, err)
+		}
+	}
+	if suggester != nil {
+		if err := suggester.Put(ctx.Request.Context(), p); err != nil {
+			requestLogger(ctx).Errorf("error indexing product %d for suggestions: %v", p.ID, err)
+		}
+	}
+}
+
+func unindexProduct(ctx *gin.Context, p schemas.Product) {
+	if searchIndex != nil {
+		if err := searchIndex.Remove(ctx.Request.Context(), p); err != nil {
+			requestLogger(ctx).Errorf("error removing product %d from the search index: %v", p.ID, err)
+		}
+	}
+	if suggester != nil {
+		if err := suggester.Remove(ctx.Request.Context(), p); err != nil {
+			requestLogger(ctx).Errorf("error removing product %d from suggestions: %v", p.ID, err)
+		}
+	}
 }
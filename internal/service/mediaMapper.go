@@ -0,0 +1,42 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toMediaResponse(m schemas.ProductMedia) schemas.MediaResponse {
+	variants := make([]schemas.MediaVariantResponse, 0, len(m.Variants))
+	for _, v := range m.Variants {
+		variants = append(variants, schemas.MediaVariantResponse{
+			Name:        v.Name,
+			URL:         mediaStore.URL(v.Key),
+			ContentType: v.ContentType,
+			Width:       v.Width,
+			Height:      v.Height,
+		})
+	}
+
+	return schemas.MediaResponse{
+		ID:          m.ID,
+		Position:    m.Position,
+		URL:         mediaStore.URL(m.Key),
+		ContentType: m.ContentType,
+		Width:       m.Width,
+		Height:      m.Height,
+		Size:        m.Size,
+		Alt:         m.Alt,
+		Variants:    variants,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+func toMediaResponses(list []schemas.ProductMedia) []schemas.MediaResponse {
+	if len(list) == 0 {
+		return nil
+	}
+	resp := make([]schemas.MediaResponse, 0, len(list))
+	for _, m := range list {
+		resp = append(resp, toMediaResponse(m))
+	}
+	return resp
+}
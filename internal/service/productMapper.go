@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
@@ -21,13 +22,21 @@ func toProductResponse(p schemas.Product) schemas.ProductResponse {
 	}
 
 	return schemas.ProductResponse{
-		ID:          p.ID,
-		Name:        p.Name,
-		Price:       p.Price,
-		Quantity:    p.Quantity,
-		Description: p.Description,
-		CreatedAt:   p.CreatedAt,
-		UpdatedAt:   p.UpdatedAt,
+		ID:              p.ID,
+		SKU:             derefString(p.SKU),
+		Name:            p.Name,
+		Price:           p.Price,
+		Quantity:        p.Quantity,
+		Description:     p.Description,
+		Category:        p.Category,
+		Brand:           p.Brand,
+		BrandID:         p.BrandID,
+		Status:          p.Status,
+		Attributes:      p.Attributes,
+		ReorderPoint:    p.ReorderPoint,
+		ReorderQuantity: p.ReorderQuantity,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
 		DeletedAt: func() time.Time {
 			if del != nil {
 				return *del
@@ -36,3 +45,88 @@ func toProductResponse(p schemas.Product) schemas.ProductResponse {
 		}(),
 	}
 }
+
+// applyProductSnapshot copies the business fields of a stored revision back
+// onto a product, leaving identity and timestamps untouched.
+func applyProductSnapshot(p *schemas.Product, s schemas.ProductResponse) {
+	p.SKU = optionalString(s.SKU)
+	p.Name = s.Name
+	p.Price = s.Price
+	p.Quantity = s.Quantity
+	p.Description = s.Description
+	p.Category = s.Category
+	p.Brand = s.Brand
+	p.BrandID = s.BrandID
+	p.Attributes = s.Attributes
+	p.ReorderPoint = s.ReorderPoint
+	p.ReorderQuantity = s.ReorderQuantity
+	// revisões anteriores ao status não o guardam
+	p.Status = s.Status
+	if p.Status == "" {
+		p.Status = schemas.ProductStatusActive
+	}
+}
+
+// productResponses maps products to responses carrying their images and,
+// for callers allowed to see them, their suppliers and margin.
+func productResponses(ctx *gin.Context, products []schemas.Product) ([]schemas.ProductResponse, error) {
+	ids := make([]uint, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	byProduct, err := loadMedia(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	var suppliers map[uint][]schemas.ProductSupplierResponse
+	if canSeeCosts(ctx) {
+		if suppliers, err = loadSuppliers(ctx, ids...); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := make([]schemas.ProductResponse, 0, len(products))
+	for _, p := range products {
+		r := toProductResponse(p)
+		r.Media = toMediaResponses(byProduct[p.ID])
+		if suppliers != nil {
+			applyCosts(&r, suppliers[p.ID])
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// optionalString maps "" to NULL, so products without SKU don't collide on
+// the (tenant, sku) unique index.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func toLowStockProductResponse(p schemas.Product, onOrder int64) schemas.LowStockProductResponse {
+	resp := schemas.LowStockProductResponse{
+		ID:              p.ID,
+		Name:            p.Name,
+		Quantity:        p.Quantity,
+		ReorderQuantity: p.ReorderQuantity,
+		OnOrder:         onOrder,
+		SuggestedOrder:  max(int64(p.ReorderQuantity)-onOrder, 0),
+	}
+	if p.SKU != nil {
+		resp.SKU = *p.SKU
+	}
+	if p.ReorderPoint != nil {
+		resp.ReorderPoint = *p.ReorderPoint
+	}
+	return resp
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// loadMedia fetches the images of the products, in display order, by
+// product.
+func loadMedia(ctx *gin.Context, ids ...uint) (map[uint][]schemas.ProductMedia, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var rows []schemas.ProductMedia
+	err := requestDB(ctx).Where("product_id IN ?", ids).Order("product_id, position, id").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	byProduct := make(map[uint][]schemas.ProductMedia, len(ids))
+	for _, m := range rows {
+		byProduct[m.ProductID] = append(byProduct[m.ProductID], m)
+	}
+	return byProduct, nil
+}
+
+// mediaKeyPrefix is where the files of a new image go. Keys start with the
+// tenant, which is checked when the API serves local storage, and the
+// random part keeps them unguessable.
+func mediaKeyPrefix(ctx *gin.Context, productID uint) (string, error) {
+	t, ok := tenant.FromContext(ctx.Request.Context())
+	if !ok {
+		t = tenant.Default
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%d/%s", t, productID, hex.EncodeToString(b)), nil
+}
+
+// mediaCount returns how many images the product has and the position
+// after the last one.
+func mediaCount(db *gorm.DB, productID uint) (count int64, next int, err error) {
+	var existing struct {
+		Count int64
+		Next  int
+	}
+	err = db.Model(&schemas.ProductMedia{}).
+		Select("COUNT(*) AS count, COALESCE(MAX(position) + 1, 0) AS next").
+		Where("product_id = ?", productID).
+		Scan(&existing).Error
+	return existing.Count, existing.Next, err
+}
+
+// storeMedia uploads the original and its variants under the keys already
+// set on m. On failure whatever was uploaded is removed again.
+func storeMedia(ctx context.Context, m schemas.ProductMedia, original []byte, variants []media.Variant) error {
+	type file struct {
+		key, contentType string
+		data             []byte
+	}
+	files := []file{{m.Key, m.ContentType, original}}
+	for i, v := range variants {
+		files = append(files, file{m.Variants[i].Key, v.ContentType, v.Data})
+	}
+
+	for i, f := range files {
+		if err := mediaStore.Put(ctx, f.key, bytes.NewReader(f.data), int64(len(f.data)), f.contentType); err != nil {
+			for _, done := range files[:i] {
+				_ = mediaStore.Delete(ctx, done.key)
+			}
+			return fmt.Errorf("error storing %s: %v", f.key, err)
+		}
+	}
+	return nil
+}
+
+// removeMedia deletes the files of m. The row is already gone, so failures
+// only leave orphan files behind and are logged.
+func removeMedia(ctx *gin.Context, m schemas.ProductMedia) {
+	for _, key := range m.Keys() {
+		if err := mediaStore.Delete(ctx.Request.Context(), key); err != nil {
+			requestLogger(ctx).Errorf("error deleting media file %s: %v", key, err)
+		}
+	}
+}
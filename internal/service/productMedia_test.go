@@ -0,0 +1,334 @@
+package service
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+)
+
+// expectMedia answers the query that loads the images of listed products.
+func expectMedia(mock sqlmock.Sqlmock, rows ...[]driver.Value) {
+	result := sqlmock.NewRows([]string{"id", "product_id", "position", "key", "content_type", "width", "height", "variants"})
+	for _, r := range rows {
+		result.AddRow(r...)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_media` WHERE product_id IN")).WillReturnRows(result)
+}
+
+func setupGinMedia(t *testing.T) (*gin.Engine, string) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/v1/product/media", CreateProductMediaService)
+	r.GET("/v1/product/media", FindProductMediaService)
+	r.PUT("/v1/product/media/order", ReorderProductMediaService)
+	r.DELETE("/v1/product/media", DeleteProductMediaService)
+	r.GET("/v1/product", FindProductService)
+	r.GET("/media/*key", ServeProductMediaService)
+
+	dir := t.TempDir()
+	store, err := media.NewLocalStorage(dir, "/media")
+	require.NoError(t, err)
+	origStore, origCfg, origSlots := mediaStore, mediaCfg, mediaSlots
+	mediaStore = store
+	mediaCfg = config.MediaConfig{MaxUploadMB: 1, ThumbnailWidths: []int{32, 64}, MaxPerProduct: 3, MaxConcurrent: 1}
+	mediaSlots = make(chan struct{}, mediaCfg.MaxConcurrent)
+	t.Cleanup(func() { mediaStore, mediaCfg, mediaSlots = origStore, origCfg, origSlots })
+	return r, dir
+}
+
+func uploadRequest(t *testing.T, url string, file []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "foto.jpg")
+	require.NoError(t, err)
+	_, err = part.Write(file)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteField("alt", "Caneca azul"))
+	require.NoError(t, w.Close())
+
+	req := httptest.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func pngOf(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func expectProduct(mock sqlmock.Sqlmock, id int) {
+	mock.ExpectQuery(`(?is)SELECT \* FROM .products. WHERE .products.\..id. = \?`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Caneca"))
+}
+
+// expectMediaCount answers the count of images and the next position of a
+// product.
+func expectMediaCount(mock sqlmock.Sqlmock, count, next int) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) AS count, COALESCE(MAX(position) + 1, 0) AS next FROM `product_media` WHERE product_id = ?")).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "next"}).AddRow(count, next))
+}
+
+// expectMediaSave expects the product row to be locked and the images
+// counted again in the transaction that saves the new one.
+func expectMediaSave(mock sqlmock.Sqlmock, count, next int) {
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `products` WHERE `products`.`id` = ?") + ".*FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	expectMediaCount(mock, count, next)
+}
+
+func TestProductMediaHandlers(t *testing.T) {
+	t.Run("envia imagem, gera variantes e grava no storage", func(t *testing.T) {
+		r, dir := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectProduct(mock, 7)
+		expectMediaCount(mock, 1, 4)
+		expectMediaSave(mock, 1, 4)
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `product_media`")).
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		mock.ExpectCommit()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/v1/product/media?id=7", pngOf(t, 48, 24)))
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body ProductMediaResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, uint(11), body.Data.ID)
+		require.Equal(t, 4, body.Data.Position)
+		require.Equal(t, "image/png", body.Data.ContentType)
+		require.Equal(t, "Caneca azul", body.Data.Alt)
+		require.Regexp(t, `^/media/default/7/[0-9a-f]{32}/original\.png$`, body.Data.URL)
+
+		// 64 é maior que a original e vira a própria largura de 48; sem
+		// transparência as miniaturas saem em JPEG
+		var names []string
+		for _, v := range body.Data.Variants {
+			names = append(names, v.Name+" "+v.ContentType)
+			_, err := os.Stat(filepath.Join(dir, strings.TrimPrefix(v.URL, "/media/")))
+			require.NoError(t, err, v.URL)
+		}
+		require.Equal(t, []string{"w32 image/jpeg", "w48 image/jpeg"}, names)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa tipo não suportado e arquivo grande demais", func(t *testing.T) {
+		r, _ := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectProduct(mock, 7)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/v1/product/media?id=7", []byte("GIF? não, um texto qualquer")))
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		expectProduct(mock, 7)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/v1/product/media?id=7", make([]byte, 2<<20)))
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "at most 1 MB")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa quando o produto já tem o máximo de imagens", func(t *testing.T) {
+		r, dir := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectProduct(mock, 7)
+		expectMediaCount(mock, 3, 3)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/v1/product/media?id=7", pngOf(t, 10, 10)))
+		require.Equal(t, http.StatusConflict, w.Code)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries, "nada vai para o storage")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa e apaga os arquivos quando um envio concorrente ocupou a última vaga", func(t *testing.T) {
+		r, dir := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectProduct(mock, 7)
+		expectMediaCount(mock, 2, 2)
+		expectMediaSave(mock, 3, 3)
+		mock.ExpectRollback()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, uploadRequest(t, "/v1/product/media?id=7", pngOf(t, 10, 10)))
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "already has 3 images")
+
+		var files []string
+		require.NoError(t, filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				files = append(files, path)
+			}
+			return err
+		}))
+		require.Empty(t, files, "os arquivos enviados são apagados")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("serve só arquivos do tenant de quem pede", func(t *testing.T) {
+		r, dir := setupGinMedia(t)
+		for _, key := range []string{"default/7/a/original.png", "loja2/7/b/original.png"} {
+			require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, key)), 0o755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, key), pngOf(t, 2, 2), 0o644))
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/media/default/7/a/original.png", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Header().Get("Cache-Control"), "private")
+
+		for _, path := range []string{"/media/loja2/7/b/original.png", "/media/default/7/a", "/media/default/7/../../loja2/7/b/original.png"} {
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+	})
+
+	t.Run("reordena exigindo todas as imagens do produto", func(t *testing.T) {
+		r, _ := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectProduct(mock, 7)
+		expectMedia(mock,
+			[]driver.Value{1, 7, 0, "default/7/a/original.png", "image/png", 10, 10, nil},
+			[]driver.Value{2, 7, 1, "default/7/b/original.png", "image/png", 10, 10, nil})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/v1/product/media/order?id=7", bytesOf(`{"mediaIds":[2]}`)))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "must list all 2 images")
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/v1/product/media/order?id=7", bytesOf(`{"mediaIds":[2,2]}`)))
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "more than once")
+
+		expectProduct(mock, 7)
+		expectMedia(mock,
+			[]driver.Value{1, 7, 0, "default/7/a/original.png", "image/png", 10, 10, nil},
+			[]driver.Value{2, 7, 1, "default/7/b/original.png", "image/png", 10, 10, nil})
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `product_media` SET `position`=? WHERE id = ? AND product_id = ?")).
+			WithArgs(0, 2, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `product_media` SET `position`=? WHERE id = ? AND product_id = ?")).
+			WithArgs(1, 1, 7).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/v1/product/media/order?id=7", bytesOf(`{"mediaIds":[2,1]}`)))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body FindProductMediaResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, uint(2), body.Data[0].ID)
+		require.Equal(t, 0, body.Data[0].Position)
+		require.Equal(t, "/media/default/7/b/original.png", body.Data[0].URL)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("apaga a imagem e seus arquivos", func(t *testing.T) {
+		r, dir := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		for _, key := range []string{"default/7/a/original.png", "default/7/a/w32.webp"} {
+			require.NoError(t, mediaStore.Put(t.Context(), key, strings.NewReader("x"), 1, "image/png"))
+		}
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_media` WHERE product_id = ? AND `product_media`.`id` = ?")).
+			WithArgs("7", "1", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "key", "variants"}).
+				AddRow(1, 7, "default/7/a/original.png", `[{"name":"w32","contentType":"image/webp","key":"default/7/a/w32.webp"}]`))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `product_media` WHERE `product_media`.`id` = ?")).
+			WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/v1/product/media?id=7&mediaId=1", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		entries, err := os.ReadDir(filepath.Join(dir, "default", "7", "a"))
+		require.NoError(t, err)
+		require.Empty(t, entries)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("inclui as imagens em ordem no produto", func(t *testing.T) {
+		r, _ := setupGinMedia(t)
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		expectProduct(mock, 7)
+		expectMedia(mock,
+			[]driver.Value{2, 7, 0, "default/7/b/original.jpg", "image/jpeg", 800, 600,
+				`[{"name":"w160","contentType":"image/webp","width":160,"height":120,"key":"default/7/b/w160.webp"}]`},
+			[]driver.Value{1, 7, 1, "default/7/a/original.png", "image/png", 10, 10, nil})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/product?id=7", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var body FindProductResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data.Media, 2)
+		require.Equal(t, "/media/default/7/b/original.jpg", body.Data.Media[0].URL)
+		require.Equal(t, "/media/default/7/b/w160.webp", body.Data.Media[0].Variants[0].URL)
+		require.Equal(t, uint(1), body.Data.Media[1].ID)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
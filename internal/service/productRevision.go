@@ -0,0 +1,137 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// productEvents maps revision actions to the event they publish
+var productEvents = map[string]string{
+	schemas.RevisionActionCreate:   schemas.EventProductCreated,
+	schemas.RevisionActionUpdate:   schemas.EventProductUpdated,
+	schemas.RevisionActionRollback: schemas.EventProductUpdated,
+	schemas.RevisionActionReceive:  schemas.EventProductUpdated,
+	schemas.RevisionActionDelete:   schemas.EventProductDeleted,
+}
+
+// fields that change on every save and carry no business meaning in a diff
+var revisionDiffIgnored = map[string]bool{
+	"id":        true,
+	"createdAt": true,
+	"updatedAt": true,
+}
+
+func recordRevision(tx *gorm.DB, p schemas.Product, action, actor string) error {
+	snapshot, err := json.Marshal(toProductResponse(p))
+	if err != nil {
+		return fmt.Errorf("error encoding product snapshot: %v", err)
+	}
+
+	// revisions are numbered from the last one, so concurrent writers of the
+	// same product are serialised on its row until the transaction ends
+	var locked uint
+	if err := tx.Unscoped().Model(&schemas.Product{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", p.ID).
+		Select("id").
+		Scan(&locked).Error; err != nil {
+		return fmt.Errorf("error locking product: %v", err)
+	}
+
+	var last uint
+	if err := tx.Model(&schemas.ProductRevision{}).
+		Where("product_id = ?", p.ID).
+		Select("COALESCE(MAX(revision), 0)").
+		Scan(&last).Error; err != nil {
+		return fmt.Errorf("error reading last revision: %v", err)
+	}
+
+	revision := schemas.ProductRevision{
+		ProductID: p.ID,
+		Revision:  last + 1,
+		Action:    action,
+		Actor:     actor,
+		Snapshot:  string(snapshot),
+	}
+
+	if err := tx.Create(&revision).Error; err != nil {
+		return fmt.Errorf("error saving product revision: %v", err)
+	}
+
+	return publishEvent(tx, productEvents[action], p.ID, json.RawMessage(snapshot))
+}
+
+func toProductRevisionResponse(r schemas.ProductRevision) (schemas.ProductRevisionResponse, error) {
+	var snapshot schemas.ProductResponse
+	if err := json.Unmarshal([]byte(r.Snapshot), &snapshot); err != nil {
+		return schemas.ProductRevisionResponse{}, fmt.Errorf("error decoding revision %d: %v", r.Revision, err)
+	}
+
+	return schemas.ProductRevisionResponse{
+		ID:        r.ID,
+		ProductID: r.ProductID,
+		Revision:  r.Revision,
+		Action:    r.Action,
+		Actor:     r.Actor,
+		Snapshot:  snapshot,
+		CreatedAt: r.CreatedAt,
+	}, nil
+}
+
+// diffRevisions compares two snapshots field by field, so new product
+// columns show up in diffs without touching this function.
+func diffRevisions(from, to schemas.ProductRevision) ([]schemas.FieldDiff, error) {
+	var a, b map[string]interface{}
+	if err := json.Unmarshal([]byte(from.Snapshot), &a); err != nil {
+		return nil, fmt.Errorf("error decoding revision %d: %v", from.Revision, err)
+	}
+	if err := json.Unmarshal([]byte(to.Snapshot), &b); err != nil {
+		return nil, fmt.Errorf("error decoding revision %d: %v", to.Revision, err)
+	}
+	flattenAttributes(a)
+	flattenAttributes(b)
+
+	fields := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		fields[k] = true
+	}
+	for k := range b {
+		fields[k] = true
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		if !revisionDiffIgnored[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make([]schemas.FieldDiff, 0)
+	for _, k := range keys {
+		if !reflect.DeepEqual(a[k], b[k]) {
+			changes = append(changes, schemas.FieldDiff{Field: k, From: a[k], To: b[k]})
+		}
+	}
+
+	return changes, nil
+}
+
+// flattenAttributes lists each custom attribute as a field of its own
+// (attributes.voltage), so a diff names the attribute that changed.
+func flattenAttributes(snapshot map[string]interface{}) {
+	attrs, ok := snapshot["attributes"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	delete(snapshot, "attributes")
+	for k, v := range attrs {
+		snapshot["attributes."+k] = v
+	}
+}
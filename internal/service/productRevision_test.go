@@ -0,0 +1,196 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func setupGinRevisions() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/v1/product/revisions", FindProductRevisionsService)
+	r.GET("/v1/product/revisions/diff", DiffProductRevisionsService)
+	r.POST("/v1/product/rollback", RollbackProductService)
+	return r
+}
+
+// expectRevision registra as queries feitas por recordRevision dentro da transação
+func expectRevision(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `products` WHERE id = ? FOR UPDATE")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`(?is)SELECT.*MAX\(revision\).*FROM.*product_revisions`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `product_revisions`")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectOutbox(mock)
+	expectWebhooks(mock)
+}
+
+// expectStockMovement espera a entrada no histórico de estoque gravada junto
+// com a revisão quando a quantidade muda.
+func expectStockMovement(mock sqlmock.Sqlmock, delta, quantity int32, reason string) {
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `stock_movements`")).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), delta, quantity, reason, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	expectOutbox(mock)
+	expectWebhooks(mock)
+}
+
+var revisionCols = []string{"id", "product_id", "revision", "action", "actor", "snapshot", "created_at"}
+
+func TestProductRevisionsHandlers(t *testing.T) {
+	r := setupGinRevisions()
+	now := time.Now()
+
+	t.Run("retorna 400 quando id não é informado", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/product/revisions", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "id")
+	})
+
+	t.Run("retorna 200 com o histórico de revisões", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		rows := sqlmock.NewRows(revisionCols).
+			AddRow(1, 7, 1, "create", "maria", `{"id":7,"name":"Teclado","price":299}`, now).
+			AddRow(2, 7, 2, "update", "joao", `{"id":7,"name":"Teclado Gamer","price":349}`, now)
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions.*WHERE.*product_id`).WillReturnRows(rows)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/product/revisions?id=7", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body FindProductRevisionsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 2)
+		require.Equal(t, "joao", body.Data[1].Actor)
+		require.Equal(t, "Teclado Gamer", body.Data[1].Snapshot.Name)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 404 no diff quando revisão não existe", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions`).WillReturnRows(sqlmock.NewRows(revisionCols))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/product/revisions/diff?id=7&from=1&to=9", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Contains(t, w.Body.String(), "revision not found")
+	})
+
+	t.Run("retorna 200 com diff por campo", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions`).WillReturnRows(sqlmock.NewRows(revisionCols).
+			AddRow(1, 7, 1, "create", "maria", `{"id":7,"name":"Teclado","price":299,"updatedAt":"a"}`, now))
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions`).WillReturnRows(sqlmock.NewRows(revisionCols).
+			AddRow(2, 7, 2, "update", "joao", `{"id":7,"name":"Teclado","price":349,"updatedAt":"b"}`, now))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/product/revisions/diff?id=7&from=1&to=2", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body DiffProductRevisionsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data.Changes, 1)
+		require.Equal(t, "price", body.Data.Changes[0].Field)
+		require.Equal(t, float64(299), body.Data.Changes[0].From)
+		require.Equal(t, float64(349), body.Data.Changes[0].To)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("diff aponta o atributo que mudou", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions`).WillReturnRows(sqlmock.NewRows(revisionCols).
+			AddRow(1, 7, 1, "create", "maria", `{"id":7,"attributes":{"voltage":127,"color":"inox"}}`, now))
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions`).WillReturnRows(sqlmock.NewRows(revisionCols).
+			AddRow(2, 7, 2, "update", "joao", `{"id":7,"attributes":{"voltage":220,"color":"inox","inverter":true}}`, now))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/product/revisions/diff?id=7&from=1&to=2", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body DiffProductRevisionsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data.Changes, 2)
+		require.Equal(t, "attributes.inverter", body.Data.Changes[0].Field)
+		require.Nil(t, body.Data.Changes[0].From)
+		require.Equal(t, "attributes.voltage", body.Data.Changes[1].Field)
+		require.Equal(t, float64(220), body.Data.Changes[1].To)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 200 e grava nova revisão no rollback", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*product_revisions`).WillReturnRows(sqlmock.NewRows(revisionCols).
+			AddRow(1, 7, 1, "create", "maria", `{"id":7,"name":"Teclado","price":299,"quantity":5,"description":"ABNT2"}`, now))
+
+		productCols := []string{"id", "name", "price", "quantity", "description", "created_at", "updated_at", "deleted_at"}
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE `products`.`id` = ? ORDER BY `products`.`id` LIMIT ? FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows(productCols).AddRow(7, "Teclado Gamer", 349, 6, "ABNT2 RGB", now, now, now))
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*WHERE.*id`).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		expectStockMovement(mock, -1, 5, schemas.StockMovementRollback)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/product/rollback?id=7&revision=1", nil)
+		req.Header.Set(middleware.ActorHeader, "auditor")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body RollbackProductResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "Teclado", body.Data.Name)
+		require.Equal(t, int64(299), body.Data.Price)
+		require.True(t, body.Data.DeletedAt.IsZero())
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toPurchaseOrderResponse(o schemas.PurchaseOrder, supplierName string, lines []schemas.PurchaseOrderLine, showCosts bool) schemas.PurchaseOrderResponse {
+	resp := schemas.PurchaseOrderResponse{
+		ID:           o.ID,
+		SupplierID:   o.SupplierID,
+		SupplierName: supplierName,
+		Status:       o.Status,
+		Notes:        o.Notes,
+		Lines:        make([]schemas.PurchaseOrderLineResponse, 0, len(lines)),
+		SentAt:       o.SentAt,
+		ReceivedAt:   o.ReceivedAt,
+		CreatedAt:    o.CreatedAt,
+		UpdatedAt:    o.UpdatedAt,
+	}
+
+	var total int64
+	for _, l := range lines {
+		line := schemas.PurchaseOrderLineResponse{
+			ID:        l.ID,
+			ProductID: l.ProductID,
+			Quantity:  l.Quantity,
+			Received:  l.Received,
+		}
+		if showCosts {
+			cost := l.UnitCost
+			line.UnitCost = &cost
+		}
+		total += int64(l.Quantity) * l.UnitCost
+		resp.Lines = append(resp.Lines, line)
+	}
+	if showCosts {
+		resp.Total = &total
+	}
+	return resp
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errOrderStatusChanged means another request moved the order on between
+// reading and writing it.
+var errOrderStatusChanged = errors.New("purchase order status changed")
+
+// loadOrderLines fetches the lines of the orders, by order.
+func loadOrderLines(tx *gorm.DB, ids ...uint) (map[uint][]schemas.PurchaseOrderLine, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var lines []schemas.PurchaseOrderLine
+	if err := tx.Where("purchase_order_id IN ?", ids).Order("purchase_order_id, id").Find(&lines).Error; err != nil {
+		return nil, err
+	}
+
+	byOrder := make(map[uint][]schemas.PurchaseOrderLine, len(ids))
+	for _, l := range lines {
+		byOrder[l.PurchaseOrderID] = append(byOrder[l.PurchaseOrderID], l)
+	}
+	return byOrder, nil
+}
+
+// loadOrderCatalog looks up what OrderLines needs for the requested
+// products: which exist, and the costs of those linked to the supplier.
+func loadOrderCatalog(ctx *gin.Context, req PurchaseOrderRequest) (map[uint]bool, map[uint]int64, error) {
+	ids := make([]uint, 0, len(req.Lines))
+	for _, l := range req.Lines {
+		ids = append(ids, l.ProductID)
+	}
+
+	var found []uint
+	if err := requestDB(ctx).Model(&schemas.Product{}).Where("id IN ?", ids).Pluck("id", &found).Error; err != nil {
+		return nil, nil, err
+	}
+	products := make(map[uint]bool, len(found))
+	for _, id := range found {
+		products[id] = true
+	}
+
+	var links []schemas.ProductSupplier
+	if err := requestDB(ctx).Where("supplier_id = ? AND product_id IN ?", req.SupplierID, ids).Find(&links).Error; err != nil {
+		return nil, nil, err
+	}
+	costs := make(map[uint]int64, len(links))
+	for _, l := range links {
+		costs[l.ProductID] = l.CostPrice
+	}
+	return products, costs, nil
+}
+
+// purchaseOrderResponses maps orders to responses with their lines and
+// supplier names. Unit costs and totals only go to callers allowed to see
+// costs.
+func purchaseOrderResponses(ctx *gin.Context, orders []schemas.PurchaseOrder) ([]schemas.PurchaseOrderResponse, error) {
+	ids := make([]uint, 0, len(orders))
+	supplierIDs := make([]uint, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+		supplierIDs = append(supplierIDs, o.SupplierID)
+	}
+	lines, err := loadOrderLines(requestDB(ctx), ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[uint]string{}
+	if len(supplierIDs) > 0 {
+		var suppliers []schemas.Supplier
+		if err := requestDB(ctx).Where("id IN ?", supplierIDs).Find(&suppliers).Error; err != nil {
+			return nil, err
+		}
+		for _, s := range suppliers {
+			names[s.ID] = s.Name
+		}
+	}
+
+	showCosts := canSeeCosts(ctx)
+	resp := make([]schemas.PurchaseOrderResponse, 0, len(orders))
+	for _, o := range orders {
+		resp = append(resp, toPurchaseOrderResponse(o, names[o.SupplierID], lines[o.ID], showCosts))
+	}
+	return resp, nil
+}
+
+// purchaseOrderResponse is purchaseOrderResponses for a single order.
+func purchaseOrderResponse(ctx *gin.Context, order schemas.PurchaseOrder) (schemas.PurchaseOrderResponse, error) {
+	resp, err := purchaseOrderResponses(ctx, []schemas.PurchaseOrder{order})
+	if err != nil {
+		return schemas.PurchaseOrderResponse{}, err
+	}
+	return resp[0], nil
+}
+
+// changeOrderStatus moves the order in the id query param to status, as long
+// as it is in one of from, and answers with the order.
+func changeOrderStatus(ctx *gin.Context, op, status string, from ...string) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var order schemas.PurchaseOrder
+	if err := requestDB(ctx).First(&order, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "purchase order not found")
+		return
+	}
+	conflict := fmt.Sprintf("purchase order is %s; only %s orders can be %s", order.Status, strings.Join(from, " or "), status)
+	if !slices.Contains(from, order.Status) {
+		sendError(ctx, http.StatusConflict, conflict)
+		return
+	}
+
+	changes := map[string]any{"status": status}
+	if status == schemas.PurchaseOrderSent {
+		now := time.Now()
+		order.SentAt = &now
+		changes["sent_at"] = now
+	}
+	res := requestDB(ctx).Model(&order).Where("status IN ?", from).Updates(changes)
+	if res.Error != nil {
+		requestLogger(ctx).Errorf("error changing purchase order status: %v", res.Error)
+		sendError(ctx, http.StatusInternalServerError, "error updating purchase order")
+		return
+	}
+	if res.RowsAffected == 0 {
+		sendError(ctx, http.StatusConflict, conflict)
+		return
+	}
+	order.Status = status
+
+	sendPurchaseOrder(ctx, op, order)
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+var orderLineCols = []string{"id", "purchase_order_id", "product_id", "quantity", "received", "unit_cost"}
+
+func setupGinPurchaseOrders(roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(roles...))
+	r.POST("/v1/purchase-order", CreatePurchaseOrderService)
+	r.POST("/v1/purchase-order/send", SendPurchaseOrderService)
+	r.POST("/v1/purchase-order/receive", ReceivePurchaseOrderService)
+	return r
+}
+
+// expectOrderDetails espera as consultas que montam a resposta do pedido.
+func expectOrderDetails(mock sqlmock.Sqlmock, lines *sqlmock.Rows) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_order_lines` WHERE purchase_order_id IN (?)")).WillReturnRows(lines)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE id IN (?)")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+}
+
+func TestPurchaseOrderHandlers(t *testing.T) {
+	t.Run("cria rascunho com o custo cadastrado do fornecedor", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE `suppliers`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `products` WHERE id IN (?,?)")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_suppliers` WHERE supplier_id = ? AND product_id IN (?,?)")).
+			WithArgs(2, 7, 8).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id", "supplier_id", "cost_price"}).AddRow(7, 2, 18000))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `purchase_orders`")).WillReturnResult(sqlmock.NewResult(5, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `purchase_order_lines`")).
+			WithArgs(sqlmock.AnyArg(), 5, 7, 10, 0, 18000, sqlmock.AnyArg(), sqlmock.AnyArg(),
+				sqlmock.AnyArg(), 5, 8, 4, 0, 2500, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 2))
+		mock.ExpectCommit()
+		expectOrderDetails(mock, sqlmock.NewRows(orderLineCols).
+			AddRow(1, 5, 7, 10, 0, 18000).
+			AddRow(2, 5, 8, 4, 0, 2500))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/purchase-order",
+			bytesOf(`{"supplierId":2,"lines":[{"productId":7,"quantity":10},{"productId":8,"quantity":4,"unitCost":2500}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setupGinPurchaseOrders(auth.RolePurchasing).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body PurchaseOrderResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, schemas.PurchaseOrderDraft, body.Data.Status)
+		require.Equal(t, "Distribuidora Sul", body.Data.SupplierName)
+		require.Equal(t, int64(190000), *body.Data.Total)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("exige custo para produto fora da tabela do fornecedor", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE `suppliers`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `products`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_suppliers`")).
+			WillReturnRows(sqlmock.NewRows([]string{"product_id"}))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/purchase-order",
+			bytesOf(`{"supplierId":2,"lines":[{"productId":8,"quantity":4}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setupGinPurchaseOrders(auth.RolePurchasing).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "unitCost is required for product 8")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recebimento parcial soma ao estoque e grava o histórico", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_orders` WHERE `purchase_orders`.`id` = ? ORDER BY `purchase_orders`.`id` LIMIT ? FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "supplier_id", "status"}).AddRow(5, 2, schemas.PurchaseOrderSent))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_order_lines`")).
+			WillReturnRows(sqlmock.NewRows(orderLineCols).
+				AddRow(1, 5, 7, 10, 0, 18000).
+				AddRow(2, 5, 8, 4, 0, 2500))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `purchase_order_lines` SET `received`=?")).
+			WithArgs(6, sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE `products`.`id` = ? AND `products`.`deleted_at` IS NULL ORDER BY `products`.`id` LIMIT ? FOR UPDATE")).
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "quantity"}).AddRow(7, "Teclado", 3))
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `products` SET `quantity`=?")).
+			WithArgs(9, sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		expectStockMovement(mock, 6, 9, schemas.StockMovementReceipt)
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `purchase_orders` SET `status`=?")).
+			WithArgs(schemas.PurchaseOrderPartiallyReceived, sqlmock.AnyArg(), 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectOrderDetails(mock, sqlmock.NewRows(orderLineCols).
+			AddRow(1, 5, 7, 10, 6, 18000).
+			AddRow(2, 5, 8, 4, 0, 2500))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/purchase-order/receive?id=5",
+			bytesOf(`{"lines":[{"lineId":1,"quantity":6}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setupGinPurchaseOrders(auth.RoleWarehouse).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body PurchaseOrderResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, schemas.PurchaseOrderPartiallyReceived, body.Data.Status)
+		require.Equal(t, int32(6), body.Data.Lines[0].Received)
+		require.Nil(t, body.Data.Lines[0].UnitCost, "estoquista não vê custos")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa receber mais do que falta", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_orders`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "supplier_id", "status"}).AddRow(5, 2, schemas.PurchaseOrderPartiallyReceived))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_order_lines`")).
+			WillReturnRows(sqlmock.NewRows(orderLineCols).AddRow(1, 5, 7, 10, 6, 18000))
+		mock.ExpectRollback()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/purchase-order/receive?id=5",
+			bytesOf(`{"lines":[{"lineId":1,"quantity":5}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		setupGinPurchaseOrders(auth.RoleWarehouse).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "line 1 has only 4 units outstanding")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("não recebe pedido em rascunho", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_orders`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "supplier_id", "status"}).AddRow(5, 2, schemas.PurchaseOrderDraft))
+		mock.ExpectRollback()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/purchase-order/receive?id=5", nil)
+		w := httptest.NewRecorder()
+		setupGinPurchaseOrders(auth.RoleWarehouse).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "purchase order is draft")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("só envia rascunhos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `purchase_orders`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "supplier_id", "status"}).AddRow(5, 2, schemas.PurchaseOrderCancelled))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/purchase-order/send?id=5", nil)
+		w := httptest.NewRecorder()
+		setupGinPurchaseOrders(auth.RolePurchasing).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "only draft orders can be sent")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReceivePurchaseOrderRequest(t *testing.T) {
+	lines := []schemas.PurchaseOrderLine{
+		{ID: 1, Quantity: 10, Received: 10},
+		{ID: 2, Quantity: 4, Received: 1},
+	}
+
+	t.Run("sem linhas recebe tudo o que falta", func(t *testing.T) {
+		receipts, err := (&ReceivePurchaseOrderRequest{}).Receipts(lines)
+		require.NoError(t, err)
+		require.Equal(t, map[uint]int32{2: 3}, receipts)
+	})
+
+	t.Run("recusa linha de outro pedido", func(t *testing.T) {
+		req := &ReceivePurchaseOrderRequest{Lines: []ReceiveLineRequest{{LineID: 9, Quantity: 1}}}
+		_, err := req.Receipts(lines)
+		require.ErrorContains(t, err, "line 9 is not part of this order")
+	})
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	errOrderNotReceivable = errors.New("only sent or partially received purchase orders can be received")
+	errReceiptProductGone = errors.New("no longer exists")
+)
+
+// @BasePath /v1
+
+// @Summary Receive purchase order
+// @Description Receive goods of a sent purchase order. The stock of each product goes up by the units received, with a revision and a stock history entry pointing at the order, all in one transaction. Without lines, everything outstanding is received.
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param id query string true "Purchase order identification"
+// @Param request body ReceivePurchaseOrderRequest false "Lines received"
+// @Success 200 {object} PurchaseOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-order/receive [post]
+func ReceivePurchaseOrderService(ctx *gin.Context) {
+	var req ReceivePurchaseOrderRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := bindJSON(ctx, &req); err != nil {
+			requestLogger(ctx).Errorf("bind error: %v", err)
+			sendError(ctx, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	actor := middleware.Actor(ctx)
+	var (
+		order    schemas.PurchaseOrder
+		invalid  error
+		received []schemas.Product
+	)
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// a trava serializa recebimentos do mesmo pedido
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			return err
+		}
+		if order.Status != schemas.PurchaseOrderSent && order.Status != schemas.PurchaseOrderPartiallyReceived {
+			return errOrderNotReceivable
+		}
+
+		byOrder, err := loadOrderLines(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		lines := byOrder[order.ID]
+		receipts, err := req.Receipts(lines)
+		if err != nil {
+			invalid = err
+			return err
+		}
+
+		complete := true
+		for i := range lines {
+			l := &lines[i]
+			if q := receipts[l.ID]; q > 0 {
+				l.Received += q
+				if err := tx.Model(l).Update("received", l.Received).Error; err != nil {
+					return err
+				}
+				product, err := receiveStock(tx, l.ProductID, q, order.ID, actor)
+				if err != nil {
+					return err
+				}
+				received = append(received, product)
+			}
+			if l.Outstanding() > 0 {
+				complete = false
+			}
+		}
+
+		changes := map[string]any{"status": schemas.PurchaseOrderPartiallyReceived}
+		if complete {
+			now := time.Now()
+			order.ReceivedAt = &now
+			changes = map[string]any{"status": schemas.PurchaseOrderReceived, "received_at": now}
+		}
+		order.Status = changes["status"].(string)
+		return tx.Model(&order).Updates(changes).Error
+	})
+	switch {
+	case invalid != nil:
+		requestLogger(ctx).Errorf("validation error: %v", invalid)
+		sendError(ctx, http.StatusBadRequest, invalid.Error())
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		sendError(ctx, http.StatusNotFound, "purchase order not found")
+		return
+	case errors.Is(err, errOrderNotReceivable):
+		sendError(ctx, http.StatusConflict, fmt.Sprintf("purchase order is %s; %v", order.Status, err))
+		return
+	case errors.Is(err, errReceiptProductGone):
+		sendError(ctx, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		requestLogger(ctx).Errorf("error receiving purchase order: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error receiving purchase order")
+		return
+	}
+
+	for _, p := range received {
+		indexProduct(ctx, p)
+	}
+
+	sendPurchaseOrder(ctx, "receive-purchase-order", order)
+}
+
+// receiveStock adds the units received to the product's stock and records
+// the change in its revisions and stock history.
+func receiveStock(tx *gorm.DB, productID uint, units int32, orderID uint, actor string) (schemas.Product, error) {
+	var product schemas.Product
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, productID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return product, fmt.Errorf("product %d: %w", productID, errReceiptProductGone)
+	}
+	if err != nil {
+		return product, err
+	}
+
+	before := product.Quantity
+	product.Quantity += units
+	if err := tx.Model(&product).Update("quantity", product.Quantity).Error; err != nil {
+		return product, err
+	}
+	if err := recordRevision(tx, product, schemas.RevisionActionReceive, actor); err != nil {
+		return product, err
+	}
+	return product, recordStockMovement(tx, product, before, schemas.StockMovementReceipt, &orderID, actor)
+}
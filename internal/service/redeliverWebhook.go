@@ -0,0 +1,68 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Redeliver webhook
+// @Description Send a delivery again with the same payload and event id. The original stays in the log untouched and the copy starts over with fresh attempts.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id query string true "Delivery identification"
+// @Success 200 {object} WebhookDeliveryResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /webhooks/deliveries/redeliver [post]
+func RedeliverWebhookService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var original schemas.WebhookDelivery
+	if err := requestDB(ctx).First(&original, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "webhook delivery not found")
+		return
+	}
+
+	var sub schemas.WebhookSubscription
+	if err := requestDB(ctx).First(&sub, original.SubscriptionID).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "webhook not found")
+		return
+	}
+	if !sub.Active {
+		sendError(ctx, http.StatusConflict, "webhook is inactive")
+		return
+	}
+
+	delivery := schemas.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		Status:         schemas.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+		RedeliveryOf:   &original.ID,
+	}
+	if err := requestDB(ctx).Create(&delivery).Error; err != nil {
+		requestLogger(ctx).Errorf("error queueing webhook redelivery: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error queueing webhook redelivery")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, WebhookDeliveryResponse{
+		Message: "operation from handler: redeliver-webhook successful",
+		Data:    toWebhookDeliveryResponse(delivery),
+	})
+}
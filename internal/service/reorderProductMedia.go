@@ -0,0 +1,94 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Reorder product images
+// @Description Set the display order of a product's images. mediaIds must list every image of the product exactly once; the first one is the main image.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param request body ReorderProductMediaRequest true "Request body"
+// @Success 200 {object} FindProductMediaResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/media/order [put]
+func ReorderProductMediaService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var req ReorderProductMediaRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product := schemas.Product{}
+	if err := requestDB(ctx).First(&product, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "product not found")
+		return
+	}
+
+	byProduct, err := loadMedia(ctx, product.ID)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading product media: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error reordering images")
+		return
+	}
+	list := byProduct[product.ID]
+	if err := req.ValidateAgainst(list); err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		for pos, mediaID := range req.MediaIDs {
+			err := tx.Model(&schemas.ProductMedia{}).
+				Where("id = ? AND product_id = ?", mediaID, product.ID).
+				Update("position", pos).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		requestLogger(ctx).Errorf("error reordering product media: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error reordering images")
+		return
+	}
+
+	byID := make(map[uint]schemas.ProductMedia, len(list))
+	for _, m := range list {
+		byID[m.ID] = m
+	}
+	ordered := make([]schemas.ProductMedia, 0, len(list))
+	for pos, mediaID := range req.MediaIDs {
+		m := byID[mediaID]
+		m.Position = pos
+		ordered = append(ordered, m)
+	}
+
+	ctx.JSON(http.StatusOK, FindProductMediaResponse{
+		Message: "operation from handler: reorder-product-media successful",
+		Data:    toMediaResponses(ordered),
+	})
+}
@@ -1,16 +1,45 @@
 package service
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/alissonmunhoz/go-crud-products/internal/webhook"
+	"gorm.io/gorm/clause"
+)
 
 func errParamIsRequired(name_, typ string) error {
 	return fmt.Errorf("param: %s (type: %s) is required", name_, typ)
 }
 
 type CreateProductRequest struct {
+	SKU         string `json:"sku"`
 	Name        string `json:"name" binding:"required"`
 	Price       int64  `json:"price" binding:"required"`
 	Quantity    int32  `json:"quantity" binding:"required"`
 	Description string `json:"description" binding:"required"`
+	Category    string `json:"category"`
+	Brand       string `json:"brand"`
+	// marca cadastrada; o nome dela vai para brand
+	BrandID *uint `json:"brandId"`
+	// active (padrão), draft ou archived
+	Status string `json:"status"`
+	// valores dos atributos definidos para a categoria, por chave
+	Attributes map[string]any `json:"attributes"`
+	// estoque em que o produto passa a estar baixo e gera alerta; vazio desliga
+	ReorderPoint *int32 `json:"reorderPoint"`
+	// quanto pedir ao repor
+	ReorderQuantity int32 `json:"reorderQuantity"`
 }
 
 func (r *CreateProductRequest) Validate() error {
@@ -21,6 +50,9 @@ func (r *CreateProductRequest) Validate() error {
 	if r.Name == "" {
 		return errParamIsRequired("name", "string")
 	}
+	if err := validateProductName(r.Name); err != nil {
+		return err
+	}
 
 	if r.Price <= 0 {
 		return errParamIsRequired("price", "number")
@@ -34,21 +66,784 @@ func (r *CreateProductRequest) Validate() error {
 		return errParamIsRequired("description", "string")
 	}
 
+	if err := validateCatalogFields(r.SKU, r.Category, r.Brand, r.Status); err != nil {
+		return err
+	}
+	if err := validateBrandLink(r.Brand, r.BrandID); err != nil {
+		return err
+	}
+	if err := validateReorder(r.ReorderPoint, &r.ReorderQuantity); err != nil {
+		return err
+	}
+
+	if len(r.Attributes) > 0 && r.Category == "" {
+		return fmt.Errorf("param: attributes require a category")
+	}
+
+	return nil
+}
+
+// ValidateAttributes checks the attributes against the definitions of the
+// product category and returns them as stored.
+func (r *CreateProductRequest) ValidateAttributes(defs []schemas.AttributeDefinition) (schemas.Attributes, error) {
+	return mergeAttributes(r.Category, defs, nil, r.Attributes)
+}
+
+func validateBrandLink(brand string, brandID *uint) error {
+	if brandID == nil {
+		return nil
+	}
+	if *brandID == 0 {
+		return fmt.Errorf("param: brandId must be a brand id")
+	}
+	if brand != "" {
+		return fmt.Errorf("param: send brand or brandId, not both")
+	}
+	return nil
+}
+
+func validateReorder(point, quantity *int32) error {
+	if point != nil && *point < 0 {
+		return fmt.Errorf("param: reorderPoint must not be negative")
+	}
+	if quantity != nil && *quantity < 0 {
+		return fmt.Errorf("param: reorderQuantity must not be negative")
+	}
+	return nil
+}
+
+// NullableInt32 tells a JSON null, which clears a field, from a field left
+// out of the request.
+type NullableInt32 struct {
+	Set   bool
+	Value *int32
+}
+
+func (n *NullableInt32) UnmarshalJSON(b []byte) error {
+	n.Set = true
+	if string(b) == "null" {
+		n.Value = nil
+		return nil
+	}
+	var v int32
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	n.Value = &v
+	return nil
+}
+
+// validateProductName keeps names to one line of text; they end up in
+// alert mail headers and logs.
+func validateProductName(name string) error {
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return fmt.Errorf("param: name must not contain control characters")
+	}
+	return nil
+}
+
+func validateCatalogFields(sku, category, brand, status string) error {
+	if len(sku) > 64 {
+		return fmt.Errorf("param: sku must have at most 64 characters")
+	}
+	if len(category) > 64 {
+		return fmt.Errorf("param: category must have at most 64 characters")
+	}
+	if len(brand) > 64 {
+		return fmt.Errorf("param: brand must have at most 64 characters")
+	}
+	if status != "" && !schemas.IsProductStatus(status) {
+		return fmt.Errorf("param: status must be active, draft or archived")
+	}
 	return nil
 }
 
 type UpdateProductRequest struct {
+	SKU         string `json:"sku"`
 	Name        string `json:"name"`
 	Price       int64  `json:"price"`
-	Quantity    int32  `json:"quantity"`
+	Quantity    *int32 `json:"quantity"`
 	Description string `json:"description"`
+	Category    string `json:"category"`
+	Brand       string `json:"brand"`
+	// liga o produto a uma marca cadastrada; brand em texto desfaz a ligação
+	BrandID *uint  `json:"brandId"`
+	Status  string `json:"status"`
+	// só as chaves enviadas mudam; null remove o atributo
+	Attributes map[string]any `json:"attributes"`
+	// null desliga os alertas de estoque baixo do produto
+	ReorderPoint    NullableInt32 `json:"reorderPoint" swaggertype:"integer"`
+	ReorderQuantity *int32        `json:"reorderQuantity"`
 }
 
 func (r *UpdateProductRequest) Validate() error {
+	if r.Quantity != nil && *r.Quantity < 0 {
+		return fmt.Errorf("param: quantity must not be negative")
+	}
+	if err := validateProductName(r.Name); err != nil {
+		return err
+	}
+
+	if err := validateCatalogFields(r.SKU, r.Category, r.Brand, r.Status); err != nil {
+		return err
+	}
+	if err := validateBrandLink(r.Brand, r.BrandID); err != nil {
+		return err
+	}
+	if err := validateReorder(r.ReorderPoint.Value, r.ReorderQuantity); err != nil {
+		return err
+	}
 
-	if r.Name != "" || r.Price > 0 || r.Quantity >= 0 || r.Description != "" {
+	if r.SKU != "" || r.Name != "" || r.Price > 0 || r.Quantity != nil || r.Description != "" ||
+		r.Category != "" || r.Brand != "" || r.BrandID != nil || r.Status != "" || r.Attributes != nil ||
+		r.ReorderPoint.Set || r.ReorderQuantity != nil {
 		return nil
 	}
 
 	return fmt.Errorf("at least one valid field must be provided")
 }
+
+// TouchesAttributes reports whether the attributes have to be checked again:
+// the request changes them or moves the product to another category.
+func (r *UpdateProductRequest) TouchesAttributes() bool {
+	return r.Attributes != nil || r.Category != ""
+}
+
+// ValidateAttributes applies the attribute changes to current, the values the
+// product already has, against the definitions of its (possibly new)
+// category. Values of attributes the category does not define are dropped.
+func (r *UpdateProductRequest) ValidateAttributes(category string, defs []schemas.AttributeDefinition, current schemas.Attributes) (schemas.Attributes, error) {
+	if category == "" && len(r.Attributes) > 0 {
+		return nil, fmt.Errorf("param: attributes require a category")
+	}
+	return mergeAttributes(category, defs, current, r.Attributes)
+}
+
+// RequiredPermissions lists what the caller needs to write the fields present
+// in the request: stock and price are guarded separately from the catalog data.
+func (r *UpdateProductRequest) RequiredPermissions() []auth.Permission {
+	var perms []auth.Permission
+	if r.SKU != "" || r.Name != "" || r.Description != "" || r.Category != "" || r.Brand != "" || r.BrandID != nil ||
+		r.Status != "" || r.Attributes != nil {
+		perms = append(perms, auth.ProductWrite)
+	}
+	if r.Price > 0 {
+		perms = append(perms, auth.PriceWrite)
+	}
+	if r.Quantity != nil || r.ReorderPoint.Set || r.ReorderQuantity != nil {
+		perms = append(perms, auth.StockAdjust)
+	}
+	return perms
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type PaginationQuery struct {
+	Page     int `form:"page"`
+	PageSize int `form:"pageSize"`
+}
+
+func (q *PaginationQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+}
+
+func (q *PaginationQuery) Offset() int {
+	return (q.Page - 1) * q.PageSize
+}
+
+type FindAuditEntriesQuery struct {
+	PaginationQuery
+	Actor     string    `form:"actor"`
+	Method    string    `form:"method"`
+	Status    int       `form:"status"`
+	RequestID string    `form:"requestId"`
+	From      time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To        time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// ProductFilterQuery holds the filters shared by the product listing and
+// search. Repeating a parameter (category=a&category=b) accepts any of the
+// values.
+type ProductFilterQuery struct {
+	Category []string `form:"category"`
+	Brand    []string `form:"brand"`
+	Status   []string `form:"status"`
+	MinPrice *int64   `form:"minPrice"`
+	MaxPrice *int64   `form:"maxPrice"`
+	InStock  *bool    `form:"inStock"`
+	// Facets asks for the facet counts next to the results
+	Facets bool `form:"facets"`
+	// Attributes holds the attr[<key>] parameters, see attributeParams
+	Attributes map[string][]string `form:"-"`
+}
+
+func (q *ProductFilterQuery) Validate() error {
+	if q.MinPrice != nil && q.MaxPrice != nil && *q.MinPrice >= *q.MaxPrice {
+		return fmt.Errorf("param: minPrice must be lower than maxPrice")
+	}
+	return nil
+}
+
+func (q *ProductFilterQuery) hasAttribute(key string) bool {
+	_, ok := q.Attributes[key]
+	return ok
+}
+
+func (q *ProductFilterQuery) AttributeKeys() []string {
+	keys := make([]string, 0, len(q.Attributes))
+	for k := range q.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AttributeFilters parses the attribute parameters by the type of each
+// attribute. defs holds the definitions of the keys, in any category.
+func (q *ProductFilterQuery) AttributeFilters(defs map[string]schemas.AttributeDefinition) ([]search.AttributeFilter, error) {
+	filters := make([]search.AttributeFilter, 0, len(q.Attributes))
+	for _, key := range q.AttributeKeys() {
+		def, ok := defs[key]
+		if !ok {
+			return nil, fmt.Errorf("param: attr[%s] is not a known attribute", key)
+		}
+		values := q.Attributes[key]
+		f := search.AttributeFilter{Key: key, Numeric: def.Numeric()}
+
+		switch {
+		case def.Numeric():
+			if len(values) != 1 {
+				return nil, fmt.Errorf("param: attr[%s] takes a single number or range", key)
+			}
+			min, max, err := parseNumberRange(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("param: attr[%s] %v", key, err)
+			}
+			f.Min, f.Max = min, max
+		case def.Type == schemas.AttributeBoolean:
+			for _, v := range values {
+				if v != "true" && v != "false" {
+					return nil, fmt.Errorf("param: attr[%s] must be true or false", key)
+				}
+			}
+			f.Values = values
+		default:
+			f.Values = values
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+func (q *ProductFilterQuery) Filter() search.Filter {
+	return search.Filter{
+		Categories: q.Category,
+		Brands:     q.Brand,
+		Statuses:   q.Status,
+		MinPrice:   q.MinPrice,
+		MaxPrice:   q.MaxPrice,
+		InStock:    q.InStock,
+	}
+}
+
+// productSortColumns are the product fields the listing can be sorted by.
+var productSortColumns = map[string]string{
+	"name":      "name",
+	"price":     "price",
+	"quantity":  "quantity",
+	"createdAt": "created_at",
+}
+
+// attributeSortPrefix marks a sort by custom attribute, as in attr.voltage
+const attributeSortPrefix = "attr."
+
+type FindAllProductsQuery struct {
+	ProductFilterQuery
+	// name, price, quantity, createdAt or attr.<key>; a leading - sorts descending
+	Sort string `form:"sort"`
+}
+
+// AttributeKeys lists the attributes the query filters or sorts by, whose
+// definitions Order and AttributeFilters need.
+func (q *FindAllProductsQuery) AttributeKeys() []string {
+	keys := q.ProductFilterQuery.AttributeKeys()
+	if key, ok := strings.CutPrefix(strings.TrimPrefix(q.Sort, "-"), attributeSortPrefix); ok && !q.hasAttribute(key) {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Order returns the ORDER BY of the listing, nil when unsorted. Products
+// without the attribute sorted by come first (last when descending).
+func (q *FindAllProductsQuery) Order(defs map[string]schemas.AttributeDefinition) (*clause.OrderBy, error) {
+	if q.Sort == "" {
+		return nil, nil
+	}
+	field, desc := strings.CutPrefix(q.Sort, "-")
+
+	if key, ok := strings.CutPrefix(field, attributeSortPrefix); ok {
+		def, ok := defs[key]
+		if !ok {
+			return nil, fmt.Errorf("param: sort by unknown attribute %q", key)
+		}
+		expr := search.AttributeExpr(key, def.Numeric())
+		if desc {
+			expr.SQL += " DESC"
+		}
+		return &clause.OrderBy{Expression: clause.CommaExpression{Exprs: []clause.Expression{
+			expr, clause.Expr{SQL: "id"},
+		}}}, nil
+	}
+
+	name, ok := productSortColumns[field]
+	if !ok {
+		return nil, fmt.Errorf("param: sort must be name, price, quantity, createdAt or attr.<key>")
+	}
+	return &clause.OrderBy{Columns: []clause.OrderByColumn{
+		{Column: clause.Column{Name: name}, Desc: desc},
+		{Column: clause.Column{Name: "id"}},
+	}}, nil
+}
+
+type SearchProductsQuery struct {
+	PaginationQuery
+	ProductFilterQuery
+	Q string `form:"q"`
+}
+
+func (q *SearchProductsQuery) Validate() error {
+	if len(search.Terms(q.Q)) == 0 {
+		return fmt.Errorf("param: q must contain at least one searchable word")
+	}
+	return q.ProductFilterQuery.Validate()
+}
+
+const (
+	defaultSuggestLimit = 8
+	maxSuggestLimit     = 20
+)
+
+type SuggestProductsQuery struct {
+	Q     string `form:"q"`
+	Limit int    `form:"limit"`
+}
+
+func (q *SuggestProductsQuery) Normalize() {
+	if q.Limit < 1 {
+		q.Limit = defaultSuggestLimit
+	}
+	if q.Limit > maxSuggestLimit {
+		q.Limit = maxSuggestLimit
+	}
+}
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-zA-Z0-9_]{0,63}$`)
+
+type CreateAttributeDefinitionRequest struct {
+	Category string `json:"category" binding:"required"`
+	// letras e dígitos, começando por minúscula (ex.: voltage, isbn)
+	Key   string `json:"key" binding:"required"`
+	Label string `json:"label"`
+	// string, number, enum, boolean ou unit
+	Type     string `json:"type" binding:"required"`
+	Required bool   `json:"required"`
+	// valores aceitos por um enum
+	Options []string `json:"options"`
+	// unidade de medida de um atributo unit (ex.: V, kg)
+	Unit string `json:"unit"`
+}
+
+func (r *CreateAttributeDefinitionRequest) Validate() error {
+	if r.Category == "" {
+		return errParamIsRequired("category", "string")
+	}
+	if len(r.Category) > 64 {
+		return fmt.Errorf("param: category must have at most 64 characters")
+	}
+	if !attributeKeyPattern.MatchString(r.Key) {
+		return fmt.Errorf("param: key must start with a lowercase letter and hold only letters, digits and _ (at most 64)")
+	}
+	if len(r.Label) > 255 {
+		return fmt.Errorf("param: label must have at most 255 characters")
+	}
+	if !schemas.IsAttributeType(r.Type) {
+		return fmt.Errorf("param: type must be string, number, enum, boolean or unit")
+	}
+
+	if r.Type == schemas.AttributeEnum {
+		if len(r.Options) == 0 {
+			return errParamIsRequired("options", "array")
+		}
+		for _, o := range r.Options {
+			if o == "" || strings.Contains(o, ",") {
+				return fmt.Errorf("param: options must not be empty nor contain commas")
+			}
+		}
+		if len(strings.Join(r.Options, ",")) > 1024 {
+			return fmt.Errorf("param: options must have at most 1024 characters in total")
+		}
+	} else if len(r.Options) > 0 {
+		return fmt.Errorf("param: options only apply to enum attributes")
+	}
+
+	if r.Type == schemas.AttributeUnit {
+		if r.Unit == "" {
+			return errParamIsRequired("unit", "string")
+		}
+		if len(r.Unit) > 16 {
+			return fmt.Errorf("param: unit must have at most 16 characters")
+		}
+	} else if r.Unit != "" {
+		return fmt.Errorf("param: unit only applies to unit attributes")
+	}
+
+	return nil
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (r *CreateAPIKeyRequest) Validate() error {
+	if r.Name == "" {
+		return errParamIsRequired("name", "string")
+	}
+
+	if len(r.Scopes) == 0 {
+		return errParamIsRequired("scopes", "array")
+	}
+
+	for _, s := range r.Scopes {
+		if !auth.IsKnownPermission(auth.Permission(s)) {
+			return fmt.Errorf("param: scopes contains unknown permission %q", s)
+		}
+	}
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("param: expiresAt must be in the future")
+	}
+
+	return nil
+}
+
+// RequiredPermissions are the scopes asked for: a caller can't hand out a
+// key that does more than it can.
+func (r *CreateAPIKeyRequest) RequiredPermissions() []auth.Permission {
+	perms := make([]auth.Permission, len(r.Scopes))
+	for i, s := range r.Scopes {
+		perms[i] = auth.Permission(s)
+	}
+	return perms
+}
+
+type ReorderProductMediaRequest struct {
+	// ids de todas as imagens do produto, na ordem de exibição
+	MediaIDs []uint `json:"mediaIds" binding:"required"`
+}
+
+func (r *ReorderProductMediaRequest) Validate() error {
+	if len(r.MediaIDs) == 0 {
+		return errParamIsRequired("mediaIds", "array")
+	}
+	seen := make(map[uint]bool, len(r.MediaIDs))
+	for _, id := range r.MediaIDs {
+		if seen[id] {
+			return fmt.Errorf("param: mediaIds lists image %d more than once", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ValidateAgainst checks that the request lists exactly the images of the
+// product.
+func (r *ReorderProductMediaRequest) ValidateAgainst(list []schemas.ProductMedia) error {
+	if len(r.MediaIDs) != len(list) {
+		return fmt.Errorf("param: mediaIds must list all %d images of the product", len(list))
+	}
+	owned := make(map[uint]bool, len(list))
+	for _, m := range list {
+		owned[m.ID] = true
+	}
+	for _, id := range r.MediaIDs {
+		if !owned[id] {
+			return fmt.Errorf("param: image %d does not belong to the product", id)
+		}
+	}
+	return nil
+}
+
+type BrandRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Website string `json:"website"`
+}
+
+func (r *BrandRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errParamIsRequired("name", "string")
+	}
+	if len(r.Name) > 64 {
+		return fmt.Errorf("param: name must have at most 64 characters")
+	}
+	if len(r.Website) > 255 {
+		return fmt.Errorf("param: website must have at most 255 characters")
+	}
+	return nil
+}
+
+type SupplierRequest struct {
+	Name        string `json:"name" binding:"required"`
+	ContactName string `json:"contactName"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+}
+
+func (r *SupplierRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errParamIsRequired("name", "string")
+	}
+	if len(r.Name) > 128 || len(r.ContactName) > 128 {
+		return fmt.Errorf("param: name and contactName must have at most 128 characters")
+	}
+	if r.Email != "" && (len(r.Email) > 255 || !strings.Contains(r.Email, "@")) {
+		return fmt.Errorf("param: email is not a valid address")
+	}
+	if len(r.Phone) > 32 {
+		return fmt.Errorf("param: phone must have at most 32 characters")
+	}
+	return nil
+}
+
+type ProductSupplierRequest struct {
+	SupplierID uint `json:"supplierId" binding:"required"`
+	// código do item no catálogo do fornecedor
+	SupplierSKU string `json:"supplierSku"`
+	// custo na mesma unidade de price
+	CostPrice    int64 `json:"costPrice" binding:"required"`
+	LeadTimeDays int   `json:"leadTimeDays"`
+}
+
+func (r *ProductSupplierRequest) Validate() error {
+	if r.SupplierID == 0 {
+		return errParamIsRequired("supplierId", "number")
+	}
+	if r.CostPrice <= 0 {
+		return fmt.Errorf("param: costPrice must be positive")
+	}
+	if r.LeadTimeDays < 0 || r.LeadTimeDays > 365 {
+		return fmt.Errorf("param: leadTimeDays must be between 0 and 365")
+	}
+	if len(r.SupplierSKU) > 64 {
+		return fmt.Errorf("param: supplierSku must have at most 64 characters")
+	}
+	return nil
+}
+
+type PurchaseOrderLineRequest struct {
+	ProductID uint  `json:"productId"`
+	Quantity  int32 `json:"quantity"`
+	// custo unitário; vazio usa o custo cadastrado para o fornecedor do pedido
+	UnitCost int64 `json:"unitCost"`
+}
+
+type PurchaseOrderRequest struct {
+	SupplierID uint                       `json:"supplierId" binding:"required"`
+	Notes      string                     `json:"notes"`
+	Lines      []PurchaseOrderLineRequest `json:"lines" binding:"required"`
+}
+
+const maxPurchaseOrderLines = 200
+
+func (r *PurchaseOrderRequest) Validate() error {
+	if r.SupplierID == 0 {
+		return errParamIsRequired("supplierId", "number")
+	}
+	if len(r.Notes) > 1024 {
+		return fmt.Errorf("param: notes must have at most 1024 characters")
+	}
+	if len(r.Lines) == 0 {
+		return errParamIsRequired("lines", "array")
+	}
+	if len(r.Lines) > maxPurchaseOrderLines {
+		return fmt.Errorf("param: an order takes at most %d lines", maxPurchaseOrderLines)
+	}
+
+	seen := make(map[uint]bool, len(r.Lines))
+	for i, l := range r.Lines {
+		if l.ProductID == 0 {
+			return fmt.Errorf("param: lines[%d].productId is required", i)
+		}
+		if seen[l.ProductID] {
+			return fmt.Errorf("param: product %d appears in more than one line", l.ProductID)
+		}
+		seen[l.ProductID] = true
+		if l.Quantity <= 0 {
+			return fmt.Errorf("param: lines[%d].quantity must be positive", i)
+		}
+		if l.UnitCost < 0 {
+			return fmt.Errorf("param: lines[%d].unitCost must not be negative", i)
+		}
+	}
+	return nil
+}
+
+type ReceiveLineRequest struct {
+	LineID   uint  `json:"lineId"`
+	Quantity int32 `json:"quantity"`
+}
+
+type ReceivePurchaseOrderRequest struct {
+	// linhas recebidas; vazio recebe tudo o que falta
+	Lines []ReceiveLineRequest `json:"lines"`
+}
+
+func (r *ReceivePurchaseOrderRequest) Validate() error {
+	seen := make(map[uint]bool, len(r.Lines))
+	for i, l := range r.Lines {
+		if l.LineID == 0 {
+			return fmt.Errorf("param: lines[%d].lineId is required", i)
+		}
+		if seen[l.LineID] {
+			return fmt.Errorf("param: line %d appears more than once", l.LineID)
+		}
+		seen[l.LineID] = true
+		if l.Quantity <= 0 {
+			return fmt.Errorf("param: lines[%d].quantity must be positive", i)
+		}
+	}
+	return nil
+}
+
+// Receipts resolves the request against the lines of the order: how many
+// units arrive for each line. Without lines, everything outstanding does.
+func (r *ReceivePurchaseOrderRequest) Receipts(lines []schemas.PurchaseOrderLine) (map[uint]int32, error) {
+	byID := make(map[uint]schemas.PurchaseOrderLine, len(lines))
+	for _, l := range lines {
+		byID[l.ID] = l
+	}
+
+	receipts := map[uint]int32{}
+	if len(r.Lines) == 0 {
+		for _, l := range lines {
+			if l.Outstanding() > 0 {
+				receipts[l.ID] = l.Outstanding()
+			}
+		}
+		return receipts, nil
+	}
+	for _, rl := range r.Lines {
+		l, ok := byID[rl.LineID]
+		if !ok {
+			return nil, fmt.Errorf("param: line %d is not part of this order", rl.LineID)
+		}
+		if rl.Quantity > l.Outstanding() {
+			return nil, fmt.Errorf("param: line %d has only %d units outstanding", rl.LineID, l.Outstanding())
+		}
+		receipts[rl.LineID] = rl.Quantity
+	}
+	return receipts, nil
+}
+
+// OrderLines turns the requested lines into order lines. products holds the
+// ids that exist and costs what the supplier charges for the products linked
+// to it, used where the request leaves the unit cost out.
+func (r *PurchaseOrderRequest) OrderLines(products map[uint]bool, costs map[uint]int64) ([]schemas.PurchaseOrderLine, error) {
+	lines := make([]schemas.PurchaseOrderLine, 0, len(r.Lines))
+	for _, l := range r.Lines {
+		if !products[l.ProductID] {
+			return nil, fmt.Errorf("param: product %d not found", l.ProductID)
+		}
+		cost := l.UnitCost
+		if cost == 0 {
+			var ok bool
+			if cost, ok = costs[l.ProductID]; !ok {
+				return nil, fmt.Errorf("param: unitCost is required for product %d, which is not linked to the supplier", l.ProductID)
+			}
+		}
+		lines = append(lines, schemas.PurchaseOrderLine{ProductID: l.ProductID, Quantity: l.Quantity, UnitCost: cost})
+	}
+	return lines, nil
+}
+
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" binding:"required"`
+	// padrão true; false pausa o webhook sem perder a inscrição
+	Active *bool `json:"active"`
+}
+
+func (r *WebhookRequest) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("param: url must be an http(s) URL")
+	}
+	if len(r.URL) > 2048 {
+		return fmt.Errorf("param: url must have at most 2048 characters")
+	}
+	if len(r.Description) > 255 {
+		return fmt.Errorf("param: description must have at most 255 characters")
+	}
+	if len(r.Events) == 0 {
+		return errParamIsRequired("events", "array")
+	}
+	for _, e := range r.Events {
+		if !schemas.IsWebhookEvent(e) {
+			return fmt.Errorf("param: unknown event %q, expected one of %s", e, strings.Join(schemas.WebhookEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// CheckTarget refuses URLs that point inside the network, unless private
+// targets are allowed. The dispatcher checks the resolved address again.
+func (r *WebhookRequest) CheckTarget(allowPrivate bool) error {
+	if allowPrivate {
+		return nil
+	}
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("param: url must be an http(s) URL")
+	}
+	if err := webhook.CheckHost(u.Hostname()); err != nil {
+		return fmt.Errorf("param: url must point to a public address")
+	}
+	return nil
+}
+
+// Apply copies the request onto sub, dropping repeated events.
+func (r *WebhookRequest) Apply(sub *schemas.WebhookSubscription) {
+	events := make([]string, 0, len(r.Events))
+	for _, e := range r.Events {
+		if !slices.Contains(events, e) {
+			events = append(events, e)
+		}
+	}
+	sub.URL = r.URL
+	sub.Description = r.Description
+	sub.Events = strings.Join(events, ",")
+	sub.Active = r.Active == nil || *r.Active
+}
+
+type FindWebhookDeliveriesQuery struct {
+	PaginationQuery
+	// id do webhook
+	ID     uint   `form:"id"`
+	Status string `form:"status"`
+	Event  string `form:"event"`
+}
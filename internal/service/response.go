@@ -46,8 +46,135 @@ type FindProductResponse struct {
 type FindAllProductsResponse struct {
 	Message string                    `json:"message"`
 	Data    []schemas.ProductResponse `json:"data"`
+	Facets  []schemas.Facet           `json:"facets,omitempty"`
+}
+type SearchProductsResponse struct {
+	Message    string                     `json:"message"`
+	Data       []schemas.ProductSearchHit `json:"data"`
+	Pagination schemas.Pagination         `json:"pagination"`
+	Facets     []schemas.Facet            `json:"facets,omitempty"`
+}
+type SuggestProductsResponse struct {
+	Message string                      `json:"message"`
+	Data    []schemas.ProductSuggestion `json:"data"`
 }
 type UpdateProductResponse struct {
 	Message string                  `json:"message"`
 	Data    schemas.ProductResponse `json:"data"`
 }
+type FindProductRevisionsResponse struct {
+	Message string                            `json:"message"`
+	Data    []schemas.ProductRevisionResponse `json:"data"`
+}
+type DiffProductRevisionsResponse struct {
+	Message string                              `json:"message"`
+	Data    schemas.ProductRevisionDiffResponse `json:"data"`
+}
+type RollbackProductResponse struct {
+	Message string                  `json:"message"`
+	Data    schemas.ProductResponse `json:"data"`
+}
+type FindAuditEntriesResponse struct {
+	Message    string                       `json:"message"`
+	Data       []schemas.AuditEntryResponse `json:"data"`
+	Pagination schemas.Pagination           `json:"pagination"`
+}
+type VerifyAuditChainResponse struct {
+	Message string                      `json:"message"`
+	Data    schemas.AuditVerifyResponse `json:"data"`
+}
+type APIKeySecretResponse struct {
+	Message string                       `json:"message"`
+	Data    schemas.APIKeySecretResponse `json:"data"`
+}
+type FindAllAPIKeysResponse struct {
+	Message string                   `json:"message"`
+	Data    []schemas.APIKeyResponse `json:"data"`
+}
+type RevokeAPIKeyResponse struct {
+	Message string                 `json:"message"`
+	Data    schemas.APIKeyResponse `json:"data"`
+}
+type AttributeDefinitionResponse struct {
+	Message string                              `json:"message"`
+	Data    schemas.AttributeDefinitionResponse `json:"data"`
+}
+type FindAllAttributeDefinitionsResponse struct {
+	Message string                                `json:"message"`
+	Data    []schemas.AttributeDefinitionResponse `json:"data"`
+}
+type ProductMediaResponse struct {
+	Message string                `json:"message"`
+	Data    schemas.MediaResponse `json:"data"`
+}
+type FindProductMediaResponse struct {
+	Message string                  `json:"message"`
+	Data    []schemas.MediaResponse `json:"data"`
+}
+type BrandResponse struct {
+	Message string                `json:"message"`
+	Data    schemas.BrandResponse `json:"data"`
+}
+type FindAllBrandsResponse struct {
+	Message string                  `json:"message"`
+	Data    []schemas.BrandResponse `json:"data"`
+}
+type SupplierResponse struct {
+	Message string                   `json:"message"`
+	Data    schemas.SupplierResponse `json:"data"`
+}
+type FindAllSuppliersResponse struct {
+	Message string                     `json:"message"`
+	Data    []schemas.SupplierResponse `json:"data"`
+}
+type ProductSupplierResponse struct {
+	Message string                          `json:"message"`
+	Data    schemas.ProductSupplierResponse `json:"data"`
+}
+type FindProductSuppliersResponse struct {
+	Message string                            `json:"message"`
+	Data    []schemas.ProductSupplierResponse `json:"data"`
+}
+type PurchaseOrderResponse struct {
+	Message string                        `json:"message"`
+	Data    schemas.PurchaseOrderResponse `json:"data"`
+}
+type FindAllPurchaseOrdersResponse struct {
+	Message string                          `json:"message"`
+	Data    []schemas.PurchaseOrderResponse `json:"data"`
+}
+type FindLowStockProductsResponse struct {
+	Message string                            `json:"message"`
+	Data    []schemas.LowStockProductResponse `json:"data"`
+}
+
+type FindStockMovementsResponse struct {
+	Message string                          `json:"message"`
+	Data    []schemas.StockMovementResponse `json:"data"`
+}
+
+type WebhookResponse struct {
+	Message string                              `json:"message"`
+	Data    schemas.WebhookSubscriptionResponse `json:"data"`
+}
+
+type WebhookSecretResponse struct {
+	Message string                                    `json:"message"`
+	Data    schemas.WebhookSubscriptionSecretResponse `json:"data"`
+}
+
+type FindAllWebhooksResponse struct {
+	Message string                                `json:"message"`
+	Data    []schemas.WebhookSubscriptionResponse `json:"data"`
+}
+
+type WebhookDeliveryResponse struct {
+	Message string                          `json:"message"`
+	Data    schemas.WebhookDeliveryResponse `json:"data"`
+}
+
+type FindWebhookDeliveriesResponse struct {
+	Message    string                            `json:"message"`
+	Data       []schemas.WebhookDeliveryResponse `json:"data"`
+	Pagination schemas.Pagination                `json:"pagination"`
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Revoke API key
+// @Description Revoke an API key. The record is kept for auditing.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id query string true "API key identification"
+// @Success 200 {object} RevokeAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /apikeys [delete]
+func RevokeAPIKeyService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var key schemas.APIKey
+	if err := requestDB(ctx).First(&key, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "api key not found")
+		return
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := requestDB(ctx).Model(&key).Update("revoked_at", now).Error; err != nil {
+			requestLogger(ctx).Errorf("error revoking api key: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error revoking api key")
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, RevokeAPIKeyResponse{
+		Message: "operation from handler: revoke-api-key successful",
+		Data:    toAPIKeyResponse(key),
+	})
+}
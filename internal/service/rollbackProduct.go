@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+// @BasePath /v1
+
+// @Summary Rollback product
+// @Description Restore a product to a previous revision; the restore is stored as a new revision
+// @Tags Revisions
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param revision query int true "Revision to restore"
+// @Param Idempotency-Key header string false "Key to safely retry the request"
+// @Success 200 {object} RollbackProductResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 422 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/rollback [post]
+func RollbackProductService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	revisionNumber := ctx.Query("revision")
+	if revisionNumber == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("revision", "queryParameter").Error())
+		return
+	}
+
+	var revision schemas.ProductRevision
+	if err := requestDB(ctx).Where("product_id = ? AND revision = ?", id, revisionNumber).First(&revision).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "revision not found")
+		return
+	}
+
+	var snapshot schemas.ProductResponse
+	if err := json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		requestLogger(ctx).Errorf("error decoding revision %d: %v", revision.Revision, err)
+		sendError(ctx, http.StatusInternalServerError, "error decoding product revision")
+		return
+	}
+
+	var (
+		product schemas.Product
+		before  int32
+	)
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// a deleted product can be rolled back too, so look past the soft
+		// delete; the lock keeps a concurrent receipt or update from
+		// changing the stock the movement is computed from
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
+			return err
+		}
+		before = product.Quantity
+		applyProductSnapshot(&product, snapshot)
+		product.DeletedAt = gorm.DeletedAt{}
+		if product.BrandID != nil {
+			// a marca pode ter sido apagada depois; o nome fica como texto
+			if err := linkBrand(tx, &product, *product.BrandID); errors.Is(err, errBrandNotFound) {
+				product.BrandID = nil
+			} else if err != nil {
+				return fmt.Errorf("error loading brand: %v", err)
+			}
+		}
+
+		if err := tx.Unscoped().Save(&product).Error; err != nil {
+			return err
+		}
+		if err := recordRevision(tx, product, schemas.RevisionActionRollback, middleware.Actor(ctx)); err != nil {
+			return err
+		}
+		return recordStockMovement(tx, product, before, schemas.StockMovementRollback, nil, middleware.Actor(ctx))
+	})
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		sendError(ctx, http.StatusNotFound, "product not found")
+		return
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		sendError(ctx, http.StatusConflict, "the sku of this revision is now used by another product")
+		return
+	case err != nil:
+		requestLogger(ctx).Errorf("error rolling back product: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error rolling back product")
+		return
+	}
+
+	indexProduct(ctx, product)
+	watchStock(ctx, product, before)
+
+	ctx.JSON(http.StatusOK, RollbackProductResponse{
+		Message: "operation from handler: rollback-product successful",
+		Data:    toProductResponse(product),
+	})
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Rotate API key
+// @Description Replace the secret of an API key, keeping its name and scopes. The old secret stops working immediately.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Param id query string true "API key identification"
+// @Success 200 {object} APIKeySecretResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /apikeys/rotate [post]
+func RotateAPIKeyService(ctx *gin.Context) {
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var key schemas.APIKey
+	if err := requestDB(ctx).First(&key, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "api key not found")
+		return
+	}
+
+	if key.RevokedAt != nil {
+		sendError(ctx, http.StatusConflict, "api key is revoked")
+		return
+	}
+
+	// the new secret carries the key's scopes, so the caller must hold them
+	if missing, ok := auth.Missing(ctx, auth.SplitScopes(key.Scopes)...); ok {
+		sendError(ctx, http.StatusForbidden, middleware.MissingPermissionMessage(missing))
+		return
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		requestLogger(ctx).Errorf("error generating api key: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error generating api key")
+		return
+	}
+
+	key.Prefix = prefix
+	key.Hash = hash
+	key.LastUsedAt = nil
+
+	if err := requestDB(ctx).Save(&key).Error; err != nil {
+		requestLogger(ctx).Errorf("error rotating api key: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error rotating api key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, APIKeySecretResponse{
+		Message: "operation from handler: rotate-api-key successful",
+		Data:    schemas.APIKeySecretResponse{APIKeyResponse: toAPIKeyResponse(key), Secret: plain},
+	})
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Search products
+// @Description Full-text search over name and description, best match first. Every word must match, also as a prefix, ignoring case and accents. Highlights are HTML-escaped with the matched words in <mark>.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param q query string true "Search text"
+// @Param category query []string false "Category (repeat for any of several)" collectionFormat(multi)
+// @Param brand query []string false "Brand (repeat for any of several)" collectionFormat(multi)
+// @Param status query []string false "Status: active, draft or archived" collectionFormat(multi)
+// @Param minPrice query int false "Lowest price (inclusive)"
+// @Param maxPrice query int false "Highest price (exclusive)"
+// @Param inStock query bool false "Only products with (true) or without (false) stock"
+// @Param attr[key] query string false "Custom attribute: a value (repeat for any of several) or, for numbers, a range such as 1..5, ..5 or 1.."
+// @Param facets query bool false "Include facet counts; with a category filter, also for its enum and boolean attributes (attr.<key>)"
+// @Param page query int false "Page (starts at 1)"
+// @Param pageSize query int false "Page size (max 200)"
+// @Success 200 {object} SearchProductsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/search [get]
+func SearchProductsService(ctx *gin.Context) {
+	var q SearchProductsQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	q.Attributes = attributeParams(ctx.Request.URL.Query())
+	if err := q.Validate(); err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+	q.Normalize()
+
+	defs, err := attributeDefinitions(ctx, q.AttributeKeys())
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading attribute definitions: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error searching products")
+		return
+	}
+	filter := q.Filter()
+	if filter.Attributes, err = q.AttributeFilters(defs); err != nil {
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := search.Query{Text: q.Q, Filter: filter, Offset: q.Offset(), Limit: q.PageSize}
+	if q.Facets {
+		spec, err := facetsFor(ctx, filter)
+		if err != nil {
+			requestLogger(ctx).Errorf("error loading attribute facets: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error searching products")
+			return
+		}
+		query.Facets = &spec
+	}
+	result, err := searchIndex.Search(ctx.Request.Context(), query)
+	if err != nil {
+		requestLogger(ctx).Errorf("error searching products: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error searching products")
+		return
+	}
+
+	var found []schemas.Product
+	if len(result.Hits) > 0 {
+		ids := make([]uint, 0, len(result.Hits))
+		for _, h := range result.Hits {
+			ids = append(ids, h.ID)
+		}
+
+		if err := requestDB(ctx).Where("id IN ?", ids).Find(&found).Error; err != nil {
+			requestLogger(ctx).Errorf("error loading search results: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error searching products")
+			return
+		}
+	}
+	details, err := productResponses(ctx, found)
+	if err != nil {
+		requestLogger(ctx).Errorf("error loading search results: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error searching products")
+		return
+	}
+	products := make(map[uint]int, len(found))
+	for i, p := range found {
+		products[p.ID] = i
+	}
+
+	terms := search.Terms(q.Q)
+	snippet := config.GetSearch().SnippetLength
+
+	// a hit whose product is gone (deleted by another replica) is skipped
+	resp := make([]schemas.ProductSearchHit, 0, len(result.Hits))
+	for _, h := range result.Hits {
+		i, ok := products[h.ID]
+		if !ok {
+			continue
+		}
+		p := found[i]
+		resp = append(resp, schemas.ProductSearchHit{
+			Product: details[i],
+			Score:   h.Score,
+			Highlights: schemas.SearchHighlights{
+				Name:        search.Highlight(p.Name, terms, 0),
+				Description: search.Highlight(p.Description, terms, snippet),
+			},
+		})
+	}
+
+	ctx.JSON(http.StatusOK, SearchProductsResponse{
+		Message:    "operation from handler: search-products successful",
+		Data:       resp,
+		Pagination: schemas.Pagination{Page: q.Page, PageSize: q.PageSize, Total: result.Total},
+		Facets:     result.Facets,
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+func setupGinSearch() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(tenant.WithTenant(ctx.Request.Context(), tenant.Default))
+	})
+	r.GET("/v1/products/search", SearchProductsService)
+	return r
+}
+
+func TestSearchProductsHandler(t *testing.T) {
+	r := setupGinSearch()
+
+	index := search.NewMemoryIndex()
+	ctx := context.Background()
+	for _, p := range []schemas.Product{
+		{Model: gorm.Model{ID: 1}, TenantID: tenant.Default, Name: "Café torrado", Description: "Grãos arábica"},
+		{Model: gorm.Model{ID: 2}, TenantID: tenant.Default, Name: "Caneca", Description: "Ideal para café & chá"},
+		{Model: gorm.Model{ID: 3}, TenantID: tenant.Default, Name: "Chaleira", Description: "Inox"},
+	} {
+		require.NoError(t, index.Put(ctx, p))
+	}
+	orig := searchIndex
+	searchIndex = index
+	defer func() { searchIndex = orig }()
+
+	t.Run("retorna 400 sem palavras para buscar", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/search?q=de", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "searchable word")
+	})
+
+	t.Run("retorna 200 na ordem da relevância com destaques", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		origDB := db
+		db = gdb
+		defer func() { db = origDB }()
+
+		now := time.Now()
+		cols := []string{"id", "name", "price", "quantity", "description", "created_at", "updated_at", "deleted_at"}
+		mock.ExpectQuery(`(?is)SELECT \* FROM .products. WHERE id IN \(\?,\?\)`).
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows(cols).
+				AddRow(2, "Caneca", 2500, 4, "Ideal para café & chá", now, now, nil).
+				AddRow(1, "Café torrado", 3990, 10, "Grãos arábica", now, now, nil))
+		expectMedia(mock)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/search?q=CAFE", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body SearchProductsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 2)
+		require.Equal(t, uint(1), body.Data[0].Product.ID)
+		require.Equal(t, "<mark>Café</mark> torrado", body.Data[0].Highlights.Name)
+		require.Equal(t, "Ideal para <mark>café</mark> &amp; chá", body.Data[1].Highlights.Description)
+		require.Greater(t, body.Data[0].Score, body.Data[1].Score)
+		require.Equal(t, int64(2), body.Pagination.Total)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Send purchase order
+// @Description Mark a draft purchase order as sent to the supplier. Its lines can no longer change and goods can be received against it.
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param id query string true "Purchase order identification"
+// @Success 200 {object} PurchaseOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-order/send [post]
+func SendPurchaseOrderService(ctx *gin.Context) {
+	changeOrderStatus(ctx, "send-purchase-order", schemas.PurchaseOrderSent, schemas.PurchaseOrderDraft)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/media"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"github.com/gin-gonic/gin"
+)
+
+// ServeProductMediaService serves a file of the local storage to callers
+// of the tenant that owns it. Keys start with the tenant, see
+// mediaKeyPrefix; anything else, including directories, is not found.
+func ServeProductMediaService(ctx *gin.Context) {
+	local, ok := mediaStore.(*media.LocalStorage)
+	if !ok {
+		sendError(ctx, http.StatusNotFound, "image not found")
+		return
+	}
+
+	t, ok := tenant.FromContext(ctx.Request.Context())
+	if !ok {
+		t = tenant.Default
+	}
+	key := strings.TrimPrefix(ctx.Param("key"), "/")
+	if !strings.HasPrefix(key, t+"/") {
+		sendError(ctx, http.StatusNotFound, "image not found")
+		return
+	}
+
+	name, err := local.Path(key)
+	if err != nil {
+		sendError(ctx, http.StatusNotFound, "image not found")
+		return
+	}
+	if info, err := os.Stat(name); err != nil || !info.Mode().IsRegular() {
+		sendError(ctx, http.StatusNotFound, "image not found")
+		return
+	}
+
+	// keys never change content, but the answer depends on who asks
+	ctx.Header("Cache-Control", "private, max-age=31536000, immutable")
+	ctx.File(name)
+}
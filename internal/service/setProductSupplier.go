@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Set product supplier
+// @Description Link a supplier to a product, or update the code, cost and lead time of an existing link
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Param id query string true "Product identification"
+// @Param request body ProductSupplierRequest true "Request body"
+// @Success 200 {object} ProductSupplierResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /product/suppliers [put]
+func SetProductSupplierService(ctx *gin.Context) {
+	var req ProductSupplierRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+	product := schemas.Product{}
+	if err := requestDB(ctx).First(&product, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "product not found")
+		return
+	}
+	supplier := schemas.Supplier{}
+	if err := requestDB(ctx).First(&supplier, req.SupplierID).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "supplier not found")
+		return
+	}
+
+	link, err := saveProductSupplier(requestDB(ctx), product.ID, supplier.ID, &req)
+	if err != nil {
+		requestLogger(ctx).Errorf("error saving product supplier: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error saving product supplier")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ProductSupplierResponse{
+		Message: "operation from handler: set-product-supplier successful",
+		Data:    toProductSupplierResponse(link, supplier.Name),
+	})
+}
+
+// saveProductSupplier updates the link between the product and the supplier
+// or creates it. The tenant plugin refuses upserts, so when a concurrent
+// request creates the link between our lookup and insert, the insert hits
+// idx_product_suppliers_pair and the link is loaded again and updated.
+func saveProductSupplier(db *gorm.DB, productID, supplierID uint, req *ProductSupplierRequest) (schemas.ProductSupplier, error) {
+	var link schemas.ProductSupplier
+	for attempt := 0; attempt < 2; attempt++ {
+		link = schemas.ProductSupplier{}
+		err := db.Where("product_id = ? AND supplier_id = ?", productID, supplierID).Take(&link).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return link, err
+		}
+		link.ProductID, link.SupplierID = productID, supplierID
+		link.SupplierSKU = req.SupplierSKU
+		link.CostPrice = req.CostPrice
+		link.LeadTimeDays = req.LeadTimeDays
+
+		created := link.ID == 0
+		err = db.Save(&link).Error
+		if created && errors.Is(err, gorm.ErrDuplicatedKey) {
+			continue
+		}
+		return link, err
+	}
+	return link, gorm.ErrDuplicatedKey
+}
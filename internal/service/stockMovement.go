@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/stockalert"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordStockMovement appends to the product's stock history when its
+// quantity is no longer the one it had before the change.
+func recordStockMovement(tx *gorm.DB, p schemas.Product, before int32, reason string, orderID *uint, actor string) error {
+	if p.Quantity == before {
+		return nil
+	}
+	movement := schemas.StockMovement{
+		ProductID:       p.ID,
+		Delta:           p.Quantity - before,
+		Quantity:        p.Quantity,
+		Reason:          reason,
+		PurchaseOrderID: orderID,
+		Actor:           actor,
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return fmt.Errorf("error saving stock movement: %v", err)
+	}
+	return publishEvent(tx, schemas.EventStockChanged, p.ID, toStockMovementResponse(movement))
+}
+
+func toStockMovementResponse(m schemas.StockMovement) schemas.StockMovementResponse {
+	return schemas.StockMovementResponse{
+		ID:              m.ID,
+		ProductID:       m.ProductID,
+		Delta:           m.Delta,
+		Quantity:        m.Quantity,
+		Reason:          m.Reason,
+		PurchaseOrderID: m.PurchaseOrderID,
+		Actor:           m.Actor,
+		CreatedAt:       m.CreatedAt,
+	}
+}
+
+// watchStock hands a committed stock change to the low stock evaluator.
+func watchStock(ctx *gin.Context, p schemas.Product, before int32) {
+	if p.Quantity == before {
+		return
+	}
+	if !stockAlerts.Observe(stockalert.Change{Product: p, Before: before}) {
+		requestLogger(ctx).Warnf("low stock queue is full; product %d was not evaluated", p.ID)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Suggest products
+// @Description Autocomplete for product names: the last word is completed as a prefix and typos are tolerated. Ranked by match quality, then by how often the product is opened.
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Param q query string true "Text typed so far"
+// @Param limit query int false "Maximum suggestions (default 8, max 20)"
+// @Success 200 {object} SuggestProductsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /products/suggest [get]
+func SuggestProductsService(ctx *gin.Context) {
+	var q SuggestProductsQuery
+	if err := ctx.ShouldBindQuery(&q); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid query parameters")
+		return
+	}
+	q.Normalize()
+
+	// without a suggester there is nothing to suggest
+	var suggestions []search.Suggestion
+	if suggester != nil {
+		var err error
+		suggestions, err = suggester.Suggest(ctx.Request.Context(), q.Q, q.Limit)
+		if err != nil {
+			requestLogger(ctx).Errorf("error suggesting products: %v", err)
+			sendError(ctx, http.StatusInternalServerError, "error suggesting products")
+			return
+		}
+	}
+
+	resp := make([]schemas.ProductSuggestion, 0, len(suggestions))
+	for _, s := range suggestions {
+		resp = append(resp, schemas.ProductSuggestion{ID: s.ID, Name: s.Name, Highlight: s.Highlight})
+	}
+
+	ctx.JSON(http.StatusOK, SuggestProductsResponse{
+		Message: "operation from handler: suggest-products successful",
+		Data:    resp,
+	})
+}
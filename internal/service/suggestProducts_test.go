@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/search"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+)
+
+func setupGinSuggest() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(tenant.WithTenant(ctx.Request.Context(), tenant.Default))
+	})
+	r.GET("/v1/products/suggest", SuggestProductsService)
+	return r
+}
+
+func TestSuggestProductsHandler(t *testing.T) {
+	r := setupGinSuggest()
+
+	names := search.NewSuggester()
+	for _, p := range []schemas.Product{
+		{Model: gorm.Model{ID: 1}, TenantID: tenant.Default, Name: "Teclado mecânico"},
+		{Model: gorm.Model{ID: 2}, TenantID: tenant.Default, Name: "Mouse sem fio"},
+	} {
+		require.NoError(t, names.Put(context.Background(), p))
+	}
+	orig := suggester
+	suggester = names
+	defer func() { suggester = orig }()
+
+	t.Run("retorna 400 com limite inválido", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/suggest?q=tec&limit=muitos", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("retorna 200 com sugestões mesmo com erro de digitação", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/suggest?q=tecal", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body SuggestProductsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data, 1)
+		require.Equal(t, uint(1), body.Data[0].ID)
+		require.Equal(t, "<mark>Teclado</mark> mecânico", body.Data[0].Highlight)
+	})
+	t.Run("retorna lista vazia sem suggester", func(t *testing.T) {
+		suggester = nil
+		defer func() { suggester = names }()
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/products/suggest?q=tec", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body SuggestProductsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Empty(t, body.Data)
+	})
+}
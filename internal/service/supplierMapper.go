@@ -0,0 +1,28 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toSupplierResponse(s schemas.Supplier) schemas.SupplierResponse {
+	return schemas.SupplierResponse{
+		ID:          s.ID,
+		Name:        s.Name,
+		ContactName: s.ContactName,
+		Email:       s.Email,
+		Phone:       s.Phone,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func toProductSupplierResponse(ps schemas.ProductSupplier, supplierName string) schemas.ProductSupplierResponse {
+	return schemas.ProductSupplierResponse{
+		SupplierID:   ps.SupplierID,
+		SupplierName: supplierName,
+		SupplierSKU:  ps.SupplierSKU,
+		CostPrice:    ps.CostPrice,
+		LeadTimeDays: ps.LeadTimeDays,
+		UpdatedAt:    ps.UpdatedAt,
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+type productSupplierRow struct {
+	schemas.ProductSupplier
+	SupplierName string
+}
+
+// loadSuppliers fetches what each supplier charges for the products, by
+// product, cheapest first.
+func loadSuppliers(ctx *gin.Context, ids ...uint) (map[uint][]schemas.ProductSupplierResponse, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var rows []productSupplierRow
+	err := requestDB(ctx).Model(&schemas.ProductSupplier{}).
+		Select("product_suppliers.*, suppliers.name AS supplier_name").
+		Joins("JOIN suppliers ON suppliers.id = product_suppliers.supplier_id").
+		Where("product_suppliers.product_id IN ?", ids).
+		Order("product_suppliers.product_id, product_suppliers.cost_price, product_suppliers.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byProduct := make(map[uint][]schemas.ProductSupplierResponse, len(ids))
+	for _, r := range rows {
+		byProduct[r.ProductID] = append(byProduct[r.ProductID], toProductSupplierResponse(r.ProductSupplier, r.SupplierName))
+	}
+	return byProduct, nil
+}
+
+// applyCosts adds the purchasing data to a product response: the cost is
+// the cheapest supplier's, and the margin is what the price leaves over it.
+func applyCosts(resp *schemas.ProductResponse, suppliers []schemas.ProductSupplierResponse) {
+	resp.Suppliers = suppliers
+	if len(suppliers) == 0 {
+		return
+	}
+	cost := suppliers[0].CostPrice
+	margin := resp.Price - cost
+	resp.Cost, resp.Margin = &cost, &margin
+}
+
+// canSeeCosts tells whether product reads carry costs and margins.
+func canSeeCosts(ctx *gin.Context) bool {
+	return auth.Can(ctx, auth.CostRead)
+}
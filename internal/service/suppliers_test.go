@@ -0,0 +1,222 @@
+package service
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+)
+
+// expectSuppliers espera a consulta dos fornecedores dos produtos; cada linha
+// é product_id, supplier_id, supplier_name, supplier_sku, cost_price, lead_time_days.
+func expectSuppliers(mock sqlmock.Sqlmock, rows ...[]driver.Value) {
+	result := sqlmock.NewRows([]string{"product_id", "supplier_id", "supplier_name", "supplier_sku", "cost_price", "lead_time_days"})
+	for _, r := range rows {
+		result.AddRow(r...)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM `product_suppliers` JOIN suppliers")).WillReturnRows(result)
+}
+
+func setupGinSuppliers(roles ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(roles...))
+	r.GET("/v1/product", FindProductService)
+	r.PUT("/v1/product/suppliers", SetProductSupplierService)
+	r.DELETE("/v1/suppliers", DeleteSupplierService)
+	return r
+}
+
+func TestProductCosts(t *testing.T) {
+	now := time.Now()
+	product := []string{"id", "name", "price", "quantity", "description", "created_at", "updated_at"}
+
+	t.Run("mostra fornecedores, custo e margem a quem pode ver custos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*products.*WHERE.*id`).
+			WillReturnRows(sqlmock.NewRows(product).AddRow(7, "Teclado", 29900, 5, "ABNT2", now, now))
+		expectMedia(mock)
+		expectSuppliers(mock,
+			[]driver.Value{7, 2, "Distribuidora Sul", "DS-88", 18000, 5},
+			[]driver.Value{7, 1, "Importadora Norte", "", 21000, 30})
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/product?id=7", nil)
+		w := httptest.NewRecorder()
+		setupGinSuppliers(auth.RolePurchasing).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var body FindProductResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Len(t, body.Data.Suppliers, 2)
+		require.Equal(t, "Distribuidora Sul", body.Data.Suppliers[0].SupplierName)
+		require.Equal(t, int64(18000), *body.Data.Cost)
+		require.Equal(t, int64(11900), *body.Data.Margin)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("esconde custos de quem só lê produtos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*products.*WHERE.*id`).
+			WillReturnRows(sqlmock.NewRows(product).AddRow(7, "Teclado", 29900, 5, "ABNT2", now, now))
+		expectMedia(mock)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/product?id=7", nil)
+		w := httptest.NewRecorder()
+		setupGinSuppliers(auth.RoleViewer).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NotContains(t, w.Body.String(), "margin")
+		require.NotContains(t, w.Body.String(), "suppliers")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSupplierHandlers(t *testing.T) {
+	r := setupGinSuppliers(auth.RolePurchasing)
+
+	t.Run("liga fornecedor ao produto quando ainda não há vínculo", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE `products`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Teclado"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE `suppliers`.`id` = ?")).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_suppliers` WHERE product_id = ? AND supplier_id = ?")).
+			WithArgs(7, 2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `product_suppliers`")).
+			WithArgs(sqlmock.AnyArg(), 7, 2, "DS-88", 18000, 5, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product/suppliers?id=7",
+			bytesOf(`{"supplierId":2,"supplierSku":"DS-88","costPrice":18000,"leadTimeDays":5}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"supplierName":"Distribuidora Sul"`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("atualiza o vínculo criado por uma requisição concorrente", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE `products`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "Teclado"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE `suppliers`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_suppliers` WHERE product_id = ? AND supplier_id = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `product_suppliers`")).
+			WillReturnError(gorm.ErrDuplicatedKey)
+		mock.ExpectRollback()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `product_suppliers` WHERE product_id = ? AND supplier_id = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "supplier_id", "cost_price"}).AddRow(3, 7, 2, 15000))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `product_suppliers`")).
+			WithArgs(sqlmock.AnyArg(), 7, 2, "DS-88", 18000, 5, sqlmock.AnyArg(), sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product/suppliers?id=7",
+			bytesOf(`{"supplierId":2,"supplierSku":"DS-88","costPrice":18000,"leadTimeDays":5}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Contains(t, w.Body.String(), `"costPrice":18000`)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa custo que não é positivo", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/v1/product/suppliers?id=7",
+			bytesOf(`{"supplierId":2,"costPrice":-5}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "costPrice must be positive")
+	})
+
+	t.Run("não apaga fornecedor ligado a produtos", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE `suppliers`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `product_suppliers` WHERE supplier_id = ?")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/suppliers?id=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "supplier is linked to products")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("não apaga fornecedor com pedidos de compra", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `suppliers` WHERE `suppliers`.`id` = ?")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "Distribuidora Sul"))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `product_suppliers` WHERE supplier_id = ?")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `purchase_orders` WHERE supplier_id = ?")).
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/suppliers?id=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.Contains(t, w.Body.String(), "supplier has purchase orders")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
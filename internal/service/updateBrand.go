@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Update brand
+// @Description Update a brand. A new name is copied to the products linked to it, recording a revision and an event for each one.
+// @Tags Brands
+// @Accept json
+// @Produce json
+// @Param id query string true "Brand identification"
+// @Param request body BrandRequest true "Brand data"
+// @Success 200 {object} BrandResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /brands [put]
+func UpdateBrandService(ctx *gin.Context) {
+	var req BrandRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var brand schemas.Brand
+	if err := requestDB(ctx).First(&brand, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "brand not found")
+		return
+	}
+	renamed := brand.Name != req.Name
+	brand.Name, brand.Website = req.Name, req.Website
+
+	var linked []schemas.Product
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&brand).Error; err != nil {
+			return err
+		}
+		if !renamed {
+			return nil
+		}
+		// deleted products are renamed too, so restoring one brings the current name back
+		if err := tx.Unscoped().Model(&schemas.Product{}).Where("brand_id = ?", brand.ID).
+			Update("brand", brand.Name).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("brand_id = ?", brand.ID).Order("id").Find(&linked).Error; err != nil {
+			return err
+		}
+		for _, p := range linked {
+			if err := recordRevision(tx, p, schemas.RevisionActionUpdate, middleware.Actor(ctx)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		sendError(ctx, http.StatusConflict, "a brand with this name already exists")
+		return
+	}
+	if err != nil {
+		requestLogger(ctx).Errorf("error updating brand: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error updating brand")
+		return
+	}
+
+	for _, p := range linked {
+		indexProduct(ctx, p)
+	}
+
+	ctx.JSON(http.StatusOK, BrandResponse{
+		Message: "operation from handler: update-brand successful",
+		Data:    toBrandResponse(brand),
+	})
+}
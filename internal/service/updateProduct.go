@@ -2,11 +2,17 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/middleware"
 	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // @BasePath /v1
@@ -19,18 +25,22 @@ import (
 // @Param request body UpdateProductRequest true "Product data to update"
 // @Success 200 {object} UpdateProductResponse
 // @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
 // @Router /product [put]
 func UpdateProductService(ctx *gin.Context) {
 	var req UpdateProductRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		logger.Errorf("bind error: %v", err)
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
 		sendError(ctx, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
 	if err := req.Validate(); err != nil {
-		logger.Errorf("validation error: %v", err)
+		requestLogger(ctx).Errorf("validation error: %v", err)
 		sendError(ctx, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -41,34 +51,119 @@ func UpdateProductService(ctx *gin.Context) {
 		return
 	}
 
-	var product schemas.Product
-	if err := db.First(&product, id).Error; err != nil {
+	if missing, ok := auth.Missing(ctx, req.RequiredPermissions()...); ok {
+		sendError(ctx, http.StatusForbidden, middleware.MissingPermissionMessage(missing))
+		return
+	}
+
+	var (
+		product schemas.Product
+		before  int32
+		invalid error
+	)
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// the lock keeps a concurrent change to the columns this request
+		// doesn't touch, such as a goods receipt, from being overwritten
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&product, id).Error; err != nil {
+			return err
+		}
+		before = product.Quantity
+
+		if err := applyProductUpdate(tx, &product, &req); err != nil {
+			if errors.Is(err, errBrandNotFound) {
+				invalid = err
+			}
+			return err
+		}
+		if req.TouchesAttributes() {
+			var defs []schemas.AttributeDefinition
+			if product.Category != "" {
+				var err error
+				if defs, err = categoryAttributes(tx, product.Category); err != nil {
+					return fmt.Errorf("error loading attribute definitions: %v", err)
+				}
+			}
+			attributes, err := req.ValidateAttributes(product.Category, defs, product.Attributes)
+			if err != nil {
+				invalid = err
+				return err
+			}
+			product.Attributes = attributes
+		}
+
+		if err := tx.Save(&product).Error; err != nil {
+			return err
+		}
+		if err := recordRevision(tx, product, schemas.RevisionActionUpdate, middleware.Actor(ctx)); err != nil {
+			return err
+		}
+		return recordStockMovement(tx, product, before, schemas.StockMovementAdjustment, nil, middleware.Actor(ctx))
+	})
+	switch {
+	case invalid != nil:
+		requestLogger(ctx).Errorf("validation error: %v", invalid)
+		sendError(ctx, http.StatusBadRequest, invalid.Error())
+		return
+	case errors.Is(err, gorm.ErrRecordNotFound):
 		sendError(ctx, http.StatusNotFound, "product not found")
 		return
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		sendError(ctx, http.StatusConflict, "a product with this sku already exists")
+		return
+	case err != nil:
+		requestLogger(ctx).Errorf("error updating product: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error updating product")
+		return
 	}
 
+	indexProduct(ctx, product)
+	watchStock(ctx, product, before)
+
+	ctx.JSON(http.StatusOK, UpdateProductResponse{
+		Message: "operation from handler: update-product successful",
+		Data:    toProductResponse(product),
+	})
+}
+
+// applyProductUpdate copies the fields set in the request onto product,
+// resolving the brand. Attributes are left to the caller, which checks them
+// against the category.
+func applyProductUpdate(tx *gorm.DB, product *schemas.Product, req *UpdateProductRequest) error {
+	if req.SKU != "" {
+		product.SKU = &req.SKU
+	}
 	if req.Name != "" {
 		product.Name = req.Name
 	}
 	if req.Price > 0 {
 		product.Price = req.Price
 	}
-	if req.Quantity >= 0 {
-		product.Quantity = req.Quantity
+	if req.Quantity != nil {
+		product.Quantity = *req.Quantity
 	}
 
 	if req.Description != "" {
 		product.Description = req.Description
 	}
-
-	if err := db.Save(&product).Error; err != nil {
-		logger.Errorf("error updating product: %v", err)
-		sendError(ctx, http.StatusInternalServerError, "error updating product")
-		return
+	if req.Category != "" {
+		product.Category = req.Category
 	}
-
-	ctx.JSON(http.StatusOK, UpdateProductResponse{
-		Message: "operation from handler: update-product successful",
-		Data:    toProductResponse(product),
-	})
+	if req.Brand != "" {
+		product.Brand, product.BrandID = req.Brand, nil
+	}
+	if req.BrandID != nil {
+		if err := linkBrand(tx, product, *req.BrandID); err != nil {
+			return err
+		}
+	}
+	if req.Status != "" {
+		product.Status = req.Status
+	}
+	if req.ReorderPoint.Set {
+		product.ReorderPoint = req.ReorderPoint.Value
+	}
+	if req.ReorderQuantity != nil {
+		product.ReorderQuantity = *req.ReorderQuantity
+	}
+	return nil
 }
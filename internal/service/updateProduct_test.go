@@ -2,11 +2,14 @@ package service
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,18 +20,29 @@ import (
 	"gorm.io/gorm"
 	glogger "gorm.io/gorm/logger"
 
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
 	"github.com/alissonmunhoz/go-crud-products/internal/config"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/stockalert"
 )
 
 func init() { logger = config.GetLogger("test") }
 
-func setupGinUpdate() *gin.Engine {
+func setupGinUpdate(roles ...string) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.PUT("/v1/product", UpdateProductService)
+	r.PUT("/v1/product", withRoles(roles...), UpdateProductService)
 	return r
 }
 
+// withRoles simula o middleware de autenticação com um usuário dos papéis informados
+func withRoles(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		auth.SetPrincipal(ctx, &auth.Principal{Subject: "tester", Roles: roles})
+		ctx.Next()
+	}
+}
+
 func newMockGormUpdate(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, *sql.DB) {
 	sqlDB, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -47,7 +61,7 @@ func newMockGormUpdate(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, *sql.DB) {
 }
 
 func TestUpdateProductHandler(t *testing.T) {
-	r := setupGinUpdate()
+	r := setupGinUpdate(auth.RoleAdmin)
 
 	t.Run("retorna 400 quando JSON é inválido (bind error)", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=1", bytesOf(`{"name": "Novo Nome",`))
@@ -69,6 +83,38 @@ func TestUpdateProductHandler(t *testing.T) {
 		require.Contains(t, w.Body.String(), "id")
 	})
 
+	t.Run("retorna 403 quando estoquista tenta alterar preço", func(t *testing.T) {
+		r := setupGinUpdate(auth.RoleWarehouse)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"price":349,"quantity":6}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "missing permission: price:write")
+	})
+
+	t.Run("retorna 400 quando quantidade é negativa", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"quantity":-1}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "quantity")
+	})
+
+	t.Run("retorna 400 quando o sku passa de 64 caracteres", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"sku":"`+strings.Repeat("A", 65)+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "sku must have at most 64 characters")
+	})
+
 	t.Run("retorna 404 quando produto não existe", func(t *testing.T) {
 		gdb, mock, sqlDB := newMockGormUpdate(t)
 		defer sqlDB.Close()
@@ -77,7 +123,9 @@ func TestUpdateProductHandler(t *testing.T) {
 		defer func() { db = orig }()
 
 		selectRegex := `(?is)SELECT.*FROM.*products.*WHERE.*id`
-		mock.ExpectQuery(selectRegex).WillReturnError(sql.ErrNoRows)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectRegex).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectRollback()
 
 		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=999", bytesOf(`{"name":"X"}`))
 		req.Header.Set("Content-Type", "application/json")
@@ -100,9 +148,8 @@ func TestUpdateProductHandler(t *testing.T) {
 		cols := []string{"id", "name", "price", "quantity", "description", "created_at", "updated_at", "deleted_at"}
 		now := time.Now()
 		row := sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 5, "ABNT2", now, now, nil)
-		mock.ExpectQuery(selectRegex).WillReturnRows(row)
-
 		mock.ExpectBegin()
+		mock.ExpectQuery(selectRegex).WillReturnRows(row)
 		updateRegex := `(?is)UPDATE.*products.*SET.*WHERE.*id`
 		mock.ExpectExec(updateRegex).WillReturnError(errors.New("save failed"))
 		mock.ExpectRollback()
@@ -128,11 +175,12 @@ func TestUpdateProductHandler(t *testing.T) {
 		cols := []string{"id", "name", "price", "quantity", "description", "created_at", "updated_at", "deleted_at"}
 		now := time.Now()
 		row := sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 5, "ABNT2", now, now, nil)
-		mock.ExpectQuery(selectRegex).WillReturnRows(row)
-
 		mock.ExpectBegin()
+		mock.ExpectQuery(selectRegex).WillReturnRows(row)
 		updateRegex := `(?is)UPDATE.*products.*SET.*WHERE.*id`
 		mock.ExpectExec(updateRegex).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		expectStockMovement(mock, 1, 6, schemas.StockMovementAdjustment)
 		mock.ExpectCommit()
 
 		reqBody := `{"name":"Teclado Gamer","price":349,"quantity":6,"description":"ABNT2 RGB"}`
@@ -163,8 +211,172 @@ func TestUpdateProductHandler(t *testing.T) {
 
 		require.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("mantém a quantidade de um recebimento concorrente", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGormUpdate(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		// o recebimento levou o estoque de 5 para 15 enquanto a edição
+		// esperava a trava; a linha relida já vem com 15
+		cols := []string{"id", "name", "price", "quantity", "created_at", "updated_at", "deleted_at"}
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `products` WHERE `products`.`id` = ? AND `products`.`deleted_at` IS NULL ORDER BY `products`.`id` LIMIT ? FOR UPDATE")).
+			WithArgs("7", 1).
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 15, now, now, nil))
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*WHERE.*id`).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"price":349}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Data struct {
+				Price    float64 `json:"price"`
+				Quantity int64   `json:"quantity"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, float64(349), body.Data.Price)
+		require.Equal(t, int64(15), body.Data.Quantity)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("estoquista ajusta só a quantidade e mantém o resto", func(t *testing.T) {
+		r := setupGinUpdate(auth.RoleWarehouse)
+
+		gdb, mock, sqlDB := newMockGormUpdate(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		selectRegex := `(?is)SELECT.*FROM.*products.*WHERE.*id`
+		cols := []string{"id", "name", "price", "quantity", "description", "created_at", "updated_at", "deleted_at"}
+		now := time.Now()
+		row := sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 5, "ABNT2", now, now, nil)
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectRegex).WillReturnRows(row)
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*WHERE.*id`).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		expectStockMovement(mock, -5, 0, schemas.StockMovementAdjustment)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"quantity":0}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body UpdateProductResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, int32(0), body.Data.Quantity)
+		require.Equal(t, int64(299), body.Data.Price)
+		require.Equal(t, "Teclado", body.Data.Name)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("baixa até o ponto de reposição dispara alerta", func(t *testing.T) {
+		r := setupGinUpdate(auth.RoleWarehouse)
+
+		gdb, mock, sqlDB := newMockGormUpdate(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		alerts := make(chan stockalert.Alert, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		origAlerts := stockAlerts
+		stockAlerts = stockalert.NewEvaluator(1, notifierFunc(func(_ context.Context, a stockalert.Alert) error {
+			alerts <- a
+			return nil
+		}))
+		defer func() { stockAlerts = origAlerts }()
+		go stockAlerts.Run(ctx, func(err error) { t.Error(err) })
+
+		cols := []string{"id", "name", "price", "quantity", "reorder_point", "reorder_quantity", "created_at", "updated_at", "deleted_at"}
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*products.*WHERE.*id`).
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 12, nil, 0, now, now, nil))
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*WHERE.*id`).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		expectStockMovement(mock, -4, 8, schemas.StockMovementAdjustment)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"quantity":8,"reorderPoint":10,"reorderQuantity":40}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body UpdateProductResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, int32(10), *body.Data.ReorderPoint)
+		require.Equal(t, int32(40), body.Data.ReorderQuantity)
+		require.NoError(t, mock.ExpectationsWereMet())
+
+		select {
+		case a := <-alerts:
+			require.Equal(t, uint(7), a.ProductID)
+			require.Equal(t, int32(8), a.Quantity)
+			require.Equal(t, int32(12), a.PreviousQuantity)
+			require.Equal(t, int32(40), a.ReorderQuantity)
+		case <-time.After(time.Second):
+			t.Fatal("alerta de estoque baixo não enviado")
+		}
+	})
+
+	t.Run("reorderPoint null desliga os alertas", func(t *testing.T) {
+		r := setupGinUpdate(auth.RoleWarehouse)
+
+		gdb, mock, sqlDB := newMockGormUpdate(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		cols := []string{"id", "name", "price", "quantity", "reorder_point", "reorder_quantity", "created_at", "updated_at", "deleted_at"}
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*products.*WHERE.*id`).
+			WillReturnRows(sqlmock.NewRows(cols).AddRow(7, "Teclado", 299, 12, 10, 40, now, now, nil))
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*WHERE.*id`).WillReturnResult(sqlmock.NewResult(0, 1))
+		expectRevision(mock)
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"reorderPoint":null}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body UpdateProductResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Nil(t, body.Data.ReorderPoint)
+		require.Equal(t, int32(40), body.Data.ReorderQuantity)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
+// notifierFunc adapta uma função a stockalert.Notifier
+type notifierFunc func(ctx context.Context, a stockalert.Alert) error
+
+func (f notifierFunc) Notify(ctx context.Context, a stockalert.Alert) error { return f(ctx, a) }
+
 func bytesOf(s string) *bytes.Buffer {
 	return bytes.NewBufferString(s)
 }
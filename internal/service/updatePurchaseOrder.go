@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Update purchase order
+// @Description Replace the supplier, notes and lines of a draft purchase order
+// @Tags Purchase orders
+// @Accept json
+// @Produce json
+// @Param id query string true "Purchase order identification"
+// @Param request body PurchaseOrderRequest true "Request body"
+// @Success 200 {object} PurchaseOrderResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /purchase-order [put]
+func UpdatePurchaseOrderService(ctx *gin.Context) {
+	var req PurchaseOrderRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var order schemas.PurchaseOrder
+	if err := requestDB(ctx).First(&order, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "purchase order not found")
+		return
+	}
+	if order.Status != schemas.PurchaseOrderDraft {
+		sendError(ctx, http.StatusConflict, "only draft purchase orders can be changed")
+		return
+	}
+
+	lines, ok := resolveOrderLines(ctx, req, "error updating purchase order")
+	if !ok {
+		return
+	}
+	order.SupplierID, order.Notes = req.SupplierID, req.Notes
+
+	err := requestDB(ctx).Transaction(func(tx *gorm.DB) error {
+		// só muda se ainda for rascunho: outra requisição pode tê-lo enviado
+		res := tx.Model(&order).Where("status = ?", schemas.PurchaseOrderDraft).
+			Updates(map[string]any{"supplier_id": order.SupplierID, "notes": order.Notes})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errOrderStatusChanged
+		}
+		if err := tx.Where("purchase_order_id = ?", order.ID).Delete(&schemas.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].PurchaseOrderID = order.ID
+		}
+		return tx.Create(&lines).Error
+	})
+	if errors.Is(err, errOrderStatusChanged) {
+		sendError(ctx, http.StatusConflict, "only draft purchase orders can be changed")
+		return
+	}
+	if err != nil {
+		requestLogger(ctx).Errorf("error updating purchase order: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error updating purchase order")
+		return
+	}
+
+	sendPurchaseOrder(ctx, "update-purchase-order", order)
+}
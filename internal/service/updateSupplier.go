@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// @BasePath /v1
+
+// @Summary Update supplier
+// @Description Replace the data of a supplier
+// @Tags Suppliers
+// @Accept json
+// @Produce json
+// @Param id query string true "Supplier identification"
+// @Param request body SupplierRequest true "Supplier data"
+// @Success 200 {object} SupplierResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /suppliers [put]
+func UpdateSupplierService(ctx *gin.Context) {
+	var req SupplierRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var supplier schemas.Supplier
+	if err := requestDB(ctx).First(&supplier, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "supplier not found")
+		return
+	}
+	supplier.Name = req.Name
+	supplier.ContactName = req.ContactName
+	supplier.Email = req.Email
+	supplier.Phone = req.Phone
+
+	err := requestDB(ctx).Save(&supplier).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		sendError(ctx, http.StatusConflict, "a supplier with this name already exists")
+		return
+	}
+	if err != nil {
+		requestLogger(ctx).Errorf("error updating supplier: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error updating supplier")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, SupplierResponse{
+		Message: "operation from handler: update-supplier successful",
+		Data:    toSupplierResponse(supplier),
+	})
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/gin-gonic/gin"
+)
+
+// @BasePath /v1
+
+// @Summary Update webhook
+// @Description Replace the URL, description, events and active flag of a webhook. The secret is kept.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param id query string true "Webhook identification"
+// @Param request body WebhookRequest true "Request body"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /webhooks [put]
+func UpdateWebhookService(ctx *gin.Context) {
+	var req WebhookRequest
+	if err := bindJSON(ctx, &req); err != nil {
+		requestLogger(ctx).Errorf("bind error: %v", err)
+		sendError(ctx, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.CheckTarget(webhookCfg.AllowPrivateTargets); err != nil {
+		requestLogger(ctx).Errorf("validation error: %v", err)
+		sendError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := ctx.Query("id")
+	if id == "" {
+		sendError(ctx, http.StatusBadRequest, errParamIsRequired("id", "queryParameter").Error())
+		return
+	}
+
+	var sub schemas.WebhookSubscription
+	if err := requestDB(ctx).First(&sub, id).Error; err != nil {
+		sendError(ctx, http.StatusNotFound, "webhook not found")
+		return
+	}
+	req.Apply(&sub)
+
+	if err := requestDB(ctx).Save(&sub).Error; err != nil {
+		requestLogger(ctx).Errorf("error updating webhook: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error updating webhook")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, WebhookResponse{
+		Message: "operation from handler: update-webhook successful",
+		Data:    toWebhookResponse(sub),
+	})
+}
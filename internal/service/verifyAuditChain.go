@@ -0,0 +1,59 @@
+package service
+
+import (
+	"net/http"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/audit"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const auditVerifyBatchSize = 500
+
+// @BasePath /v1
+
+// @Summary Verify audit chain
+// @Description Recompute the audit hash chain of every tenant and report the first tampered entry, if any. Requires platform:admin.
+// @Tags Audit
+// @Accept json
+// @Produce json
+// @Success 200 {object} VerifyAuditChainResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /audit/verify [get]
+func VerifyAuditChainService(ctx *gin.Context) {
+	var (
+		entries []schemas.AuditEntry
+		prev    string
+		result  = schemas.AuditVerifyResponse{Valid: true}
+	)
+
+	// the chain spans every tenant, so it is checked as a whole; the route
+	// is for platform admins only
+	chain := db.WithContext(tenant.WithoutScope(ctx.Request.Context()))
+
+	err := chain.Order("id").FindInBatches(&entries, auditVerifyBatchSize, func(tx *gorm.DB, batch int) error {
+		var brokenAt uint
+		prev, brokenAt = audit.VerifyChain(entries, prev)
+		if brokenAt != 0 {
+			result.Valid = false
+			result.BrokenAt = brokenAt
+			return gorm.ErrInvalidData
+		}
+		result.Checked += len(entries)
+		return nil
+	}).Error
+	if err != nil && result.Valid {
+		requestLogger(ctx).Errorf("error verifying audit chain: %v", err)
+		sendError(ctx, http.StatusInternalServerError, "error verifying audit chain")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, VerifyAuditChainResponse{
+		Message: "operation from handler: verify-audit-chain successful",
+		Data:    result,
+	})
+}
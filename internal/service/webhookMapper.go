@@ -0,0 +1,41 @@
+package service
+
+import (
+	"encoding/json"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func toWebhookResponse(s schemas.WebhookSubscription) schemas.WebhookSubscriptionResponse {
+	return schemas.WebhookSubscriptionResponse{
+		ID:          s.ID,
+		URL:         s.URL,
+		Description: s.Description,
+		Events:      s.EventList(),
+		Active:      s.Active,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(d schemas.WebhookDelivery) schemas.WebhookDeliveryResponse {
+	resp := schemas.WebhookDeliveryResponse{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		Event:          d.Event,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseCode:   d.ResponseCode,
+		Error:          d.Error,
+		RedeliveryOf:   d.RedeliveryOf,
+		DeliveredAt:    d.DeliveredAt,
+		Payload:        json.RawMessage(d.Payload),
+		CreatedAt:      d.CreatedAt,
+	}
+	if d.Status == schemas.WebhookDeliveryPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
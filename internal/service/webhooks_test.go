@@ -0,0 +1,207 @@
+package service
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/auth"
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+// expectWebhooks espera a busca das inscrições ativas feita a cada evento;
+// cada linha é id, url, events, secret, active.
+func expectWebhooks(mock sqlmock.Sqlmock, rows ...[]driver.Value) {
+	result := sqlmock.NewRows([]string{"id", "url", "events", "secret", "active"})
+	for _, r := range rows {
+		result.AddRow(r...)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_subscriptions` WHERE active = ?")).
+		WithArgs(true).
+		WillReturnRows(result)
+}
+
+func setupGinWebhooks() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(withRoles(auth.RoleAdmin))
+	r.PUT("/v1/product", UpdateProductService)
+	r.POST("/v1/webhooks", CreateWebhookService)
+	r.POST("/v1/webhooks/deliveries/redeliver", RedeliverWebhookService)
+	return r
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	r := setupGinWebhooks()
+	now := time.Now()
+
+	t.Run("retorna 400 para evento desconhecido", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", bytesOf(`{"url":"https://erp.example.com/hook","events":["product.exploded"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `unknown event \"product.exploded\"`)
+	})
+
+	t.Run("retorna 400 para url em rede privada", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", bytesOf(`{"url":"http://169.254.169.254/latest","events":["product.created"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "public address")
+	})
+
+	t.Run("retorna 400 para url que não é http", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks", bytesOf(`{"url":"ftp://erp.example.com","events":["product.created"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), "http(s) URL")
+	})
+
+	t.Run("cria ativo mostrando o segredo uma única vez", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_subscriptions`")).
+			WithArgs(sqlmock.AnyArg(), "https://erp.example.com/hook", "", "product.created,stock.changed",
+				sqlmock.AnyArg(), true, "tester", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks",
+			bytesOf(`{"url":"https://erp.example.com/hook","events":["product.created","stock.changed","product.created"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body WebhookSecretResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.True(t, strings.HasPrefix(body.Data.Secret, "whsec_"))
+		require.Equal(t, []string{"product.created", "stock.changed"}, body.Data.Events)
+		require.True(t, body.Data.Active)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("alteração de produto enfileira entregas na mesma transação", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*products.*WHERE.*id`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "quantity", "created_at", "updated_at"}).
+				AddRow(7, "Teclado", 299, 5, now, now))
+		mock.ExpectExec(`(?is)UPDATE.*products.*SET.*WHERE.*id`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `products` WHERE id = ? FOR UPDATE")).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectQuery(`(?is)SELECT.*MAX\(revision\).*FROM.*product_revisions`).
+			WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `product_revisions`")).WillReturnResult(sqlmock.NewResult(2, 1))
+		expectOutbox(mock)
+		expectWebhooks(mock,
+			[]driver.Value{1, "https://loja.example.com/hook", "product.updated", "whsec_a", true},
+			[]driver.Value{2, "https://erp.example.com/hook", "product.created,stock.changed", "whsec_b", true})
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_deliveries`")).
+			WithArgs(sqlmock.AnyArg(), 1, sqlmock.AnyArg(), schemas.EventProductUpdated, sqlmock.AnyArg(),
+				schemas.WebhookDeliveryPending, 0, sqlmock.AnyArg(), nil, "", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(10, 1))
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `stock_movements`")).WillReturnResult(sqlmock.NewResult(1, 1))
+		expectOutbox(mock)
+		expectWebhooks(mock,
+			[]driver.Value{1, "https://loja.example.com/hook", "product.updated", "whsec_a", true},
+			[]driver.Value{2, "https://erp.example.com/hook", "product.created,stock.changed", "whsec_b", true})
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_deliveries`")).
+			WithArgs(sqlmock.AnyArg(), 2, sqlmock.AnyArg(), schemas.EventStockChanged, sqlmock.AnyArg(),
+				schemas.WebhookDeliveryPending, 0, sqlmock.AnyArg(), nil, "", nil, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(11, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/product?id=7", bytesOf(`{"quantity":2}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("reenvia como nova entrega com o mesmo evento", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*webhook_deliveries`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event", "payload", "status", "attempts", "response_code"}).
+				AddRow(10, 1, "ev-1", schemas.EventProductUpdated, `{"id":"ev-1"}`, schemas.WebhookDeliveryFailed, 8, 503))
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*webhook_subscriptions`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "url", "events", "secret", "active"}).
+				AddRow(1, "https://loja.example.com/hook", "product.updated", "whsec_a", true))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `webhook_deliveries`")).
+			WithArgs(sqlmock.AnyArg(), 1, "ev-1", schemas.EventProductUpdated, `{"id":"ev-1"}`,
+				schemas.WebhookDeliveryPending, 0, sqlmock.AnyArg(), nil, "", 10, nil, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(12, 1))
+		mock.ExpectCommit()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/deliveries/redeliver?id=10", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body WebhookDeliveryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, uint(12), body.Data.ID)
+		require.Equal(t, uint(10), *body.Data.RedeliveryOf)
+		require.Equal(t, schemas.WebhookDeliveryPending, body.Data.Status)
+		require.JSONEq(t, `{"id":"ev-1"}`, string(body.Data.Payload))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("retorna 409 ao reenviar para webhook pausado", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+		orig := db
+		db = gdb
+		defer func() { db = orig }()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*webhook_deliveries`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event", "payload", "status"}).
+				AddRow(10, 1, "ev-1", schemas.EventProductUpdated, `{}`, schemas.WebhookDeliveryFailed))
+		mock.ExpectQuery(`(?is)SELECT.*FROM.*webhook_subscriptions`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "active"}).AddRow(1, false))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/webhooks/deliveries/redeliver?id=10", nil)
+		w := httptest.NewRecorder()
+
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusConflict, w.Code)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,128 @@
+// Package stockalert watches stock changes and alerts when a product falls
+// to its reorder point.
+package stockalert
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+// Alert tells that the stock of a product fell to or below its reorder
+// point.
+type Alert struct {
+	TenantID         string    `json:"tenantId"`
+	ProductID        uint      `json:"productId"`
+	SKU              string    `json:"sku,omitempty"`
+	Name             string    `json:"name"`
+	Quantity         int32     `json:"quantity"`
+	PreviousQuantity int32     `json:"previousQuantity"`
+	ReorderPoint     int32     `json:"reorderPoint"`
+	ReorderQuantity  int32     `json:"reorderQuantity,omitempty"`
+	At               time.Time `json:"at"`
+}
+
+func (a Alert) Subject() string {
+	return fmt.Sprintf("Low stock: %s", a.Name)
+}
+
+func (a Alert) String() string {
+	s := fmt.Sprintf("product %d (%s) is down to %d units, reorder point is %d", a.ProductID, a.Name, a.Quantity, a.ReorderPoint)
+	if a.SKU != "" {
+		s = fmt.Sprintf("product %d (%s, sku %s) is down to %d units, reorder point is %d", a.ProductID, a.Name, a.SKU, a.Quantity, a.ReorderPoint)
+	}
+	if a.ReorderQuantity > 0 {
+		s += fmt.Sprintf("; reorder %d units", a.ReorderQuantity)
+	}
+	return s
+}
+
+// Notifier delivers alerts somewhere people will see them.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// Change is a committed change to the stock of a product: the product as
+// it is now and the quantity it had before.
+type Change struct {
+	Product schemas.Product
+	Before  int32
+}
+
+// Crossed reports whether the change took the stock from above the reorder
+// point to at or below it. Staying low doesn't count again, so a product
+// alerts once per stockout until it is restocked above the point.
+func (c Change) Crossed() bool {
+	rp := c.Product.ReorderPoint
+	return rp != nil && c.Before > *rp && c.Product.Quantity <= *rp
+}
+
+func (c Change) alert(at time.Time) Alert {
+	a := Alert{
+		TenantID:         c.Product.TenantID,
+		ProductID:        c.Product.ID,
+		Name:             c.Product.Name,
+		Quantity:         c.Product.Quantity,
+		PreviousQuantity: c.Before,
+		ReorderPoint:     *c.Product.ReorderPoint,
+		ReorderQuantity:  c.Product.ReorderQuantity,
+		At:               at,
+	}
+	if c.Product.SKU != nil {
+		a.SKU = *c.Product.SKU
+	}
+	return a
+}
+
+// Evaluator checks stock changes in the background, so requests never wait
+// on a notifier. A nil Evaluator ignores every change.
+type Evaluator struct {
+	notifiers []Notifier
+	changes   chan Change
+}
+
+func NewEvaluator(queueSize int, notifiers ...Notifier) *Evaluator {
+	return &Evaluator{notifiers: notifiers, changes: make(chan Change, queueSize)}
+}
+
+// Observe queues a committed change for evaluation. It never blocks: with
+// the queue full the change is dropped and Observe returns false.
+func (e *Evaluator) Observe(c Change) bool {
+	if e == nil || len(e.notifiers) == 0 || c.Product.ReorderPoint == nil {
+		return true
+	}
+	select {
+	case e.changes <- c:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run evaluates the queued changes until ctx is cancelled, sending every
+// crossing through all notifiers. A failing notifier is reported to onError
+// and doesn't keep the others from being tried.
+func (e *Evaluator) Run(ctx context.Context, onError func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case c := <-e.changes:
+			e.evaluate(ctx, c, onError)
+		}
+	}
+}
+
+func (e *Evaluator) evaluate(ctx context.Context, c Change, onError func(error)) {
+	if !c.Crossed() {
+		return
+	}
+	a := c.alert(time.Now())
+	for _, n := range e.notifiers {
+		if err := n.Notify(ctx, a); err != nil {
+			onError(fmt.Errorf("error sending low stock alert for product %d: %v", a.ProductID, err))
+		}
+	}
+}
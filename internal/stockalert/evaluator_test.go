@@ -0,0 +1,93 @@
+package stockalert
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	alerts chan Alert
+	err    error
+}
+
+func (r *recorder) Notify(_ context.Context, a Alert) error {
+	r.alerts <- a
+	return r.err
+}
+
+func product(quantity int32, reorderPoint *int32) schemas.Product {
+	p := schemas.Product{TenantID: "loja1", Name: "Mouse", Quantity: quantity, ReorderPoint: reorderPoint, ReorderQuantity: 50}
+	p.ID = 7
+	return p
+}
+
+func ptr(v int32) *int32 { return &v }
+
+func TestChangeCrossed(t *testing.T) {
+	cases := []struct {
+		name   string
+		change Change
+		want   bool
+	}{
+		{"desce até o ponto", Change{Product: product(10, ptr(10)), Before: 11}, true},
+		{"desce abaixo do ponto", Change{Product: product(2, ptr(10)), Before: 30}, true},
+		{"continua acima", Change{Product: product(11, ptr(10)), Before: 30}, false},
+		{"já estava baixo", Change{Product: product(3, ptr(10)), Before: 8}, false},
+		{"reposição", Change{Product: product(40, ptr(10)), Before: 3}, false},
+		{"sem ponto de reposição", Change{Product: product(0, nil), Before: 30}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.want, c.change.Crossed())
+		})
+	}
+}
+
+func TestEvaluator(t *testing.T) {
+	t.Run("avisa todos os notificadores mesmo com falha", func(t *testing.T) {
+		failing := &recorder{alerts: make(chan Alert, 1), err: errors.New("fora do ar")}
+		ok := &recorder{alerts: make(chan Alert, 1)}
+		e := NewEvaluator(4, failing, ok)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		errs := make(chan error, 1)
+		go e.Run(ctx, func(err error) { errs <- err })
+
+		require.True(t, e.Observe(Change{Product: product(30, ptr(10)), Before: 40}))
+		require.True(t, e.Observe(Change{Product: product(5, ptr(10)), Before: 30}))
+
+		for _, r := range []*recorder{failing, ok} {
+			select {
+			case a := <-r.alerts:
+				require.Equal(t, uint(7), a.ProductID)
+				require.Equal(t, "loja1", a.TenantID)
+				require.Equal(t, int32(5), a.Quantity)
+				require.Equal(t, int32(30), a.PreviousQuantity)
+				require.Equal(t, int32(10), a.ReorderPoint)
+			case <-time.After(time.Second):
+				t.Fatal("alerta não enviado")
+			}
+		}
+		require.ErrorContains(t, <-errs, "fora do ar")
+		require.Empty(t, ok.alerts)
+	})
+
+	t.Run("descarta com a fila cheia", func(t *testing.T) {
+		e := NewEvaluator(1, &recorder{})
+
+		require.True(t, e.Observe(Change{Product: product(5, ptr(10)), Before: 30}))
+		require.False(t, e.Observe(Change{Product: product(4, ptr(10)), Before: 30}))
+	})
+
+	t.Run("sem avaliador ou sem notificadores ignora", func(t *testing.T) {
+		var e *Evaluator
+		require.True(t, e.Observe(Change{Product: product(5, ptr(10)), Before: 30}))
+		require.True(t, NewEvaluator(0).Observe(Change{Product: product(5, ptr(10)), Before: 30}))
+	})
+}
@@ -0,0 +1,166 @@
+package stockalert
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// LogNotifier writes alerts to the application log.
+type LogNotifier struct {
+	logf func(format string, args ...any)
+}
+
+func NewLogNotifier(logf func(format string, args ...any)) *LogNotifier {
+	return &LogNotifier{logf: logf}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, a Alert) error {
+	n.logf("low stock alert for tenant %s: %s", a.TenantID, a)
+	return nil
+}
+
+// WebhookNotifier POSTs each alert as JSON to a URL. Any status outside
+// 2xx is a failure.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, a Alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s answered %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+// SMTPNotifier emails alerts. Pointed at a local stand-in such as Mailpit
+// it needs no credentials; with a username it authenticates with PLAIN,
+// which net/smtp only allows over TLS or to localhost. The whole exchange
+// must finish within timeout, or earlier if ctx ends first.
+type SMTPNotifier struct {
+	addr    string
+	host    string
+	from    string
+	to      []string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+func NewSMTPNotifier(addr, username, password, from string, to []string, timeout time.Duration) *SMTPNotifier {
+	host, _, _ := net.SplitHostPort(addr)
+	n := &SMTPNotifier{addr: addr, host: host, from: from, to: to, timeout: timeout}
+	if username != "" {
+		n.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return n
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, a Alert) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", encodeHeader(a.Subject()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", a.At.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s.\r\n", a)
+
+	if err := n.send(ctx, []byte(msg.String())); err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	return nil
+}
+
+// encodeHeader keeps a value that carries product data on one header line:
+// line breaks could add headers such as Bcc, and anything outside ASCII is
+// Q-encoded.
+func encodeHeader(v string) string {
+	v = strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+	return mime.QEncoding.Encode("utf-8", v)
+}
+
+// send does what smtp.SendMail does, over a connection with a deadline so
+// a server that stops answering cannot hold the alert worker.
+func (n *SMTPNotifier) send(ctx context.Context, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	// closing the connection unblocks the exchange when ctx is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err := c.Auth(n.auth); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.Mail(n.from); err != nil {
+		return err
+	}
+	for _, to := range n.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,148 @@
+package stockalert
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var alert = Alert{TenantID: "loja1", ProductID: 7, SKU: "MS-01", Name: "Mouse", Quantity: 4, PreviousQuantity: 12, ReorderPoint: 5, ReorderQuantity: 50, At: time.Now()}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("envia o alerta em JSON", func(t *testing.T) {
+		var got Alert
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		require.NoError(t, NewWebhookNotifier(srv.URL, time.Second).Notify(context.Background(), alert))
+		require.Equal(t, uint(7), got.ProductID)
+		require.Equal(t, "MS-01", got.SKU)
+	})
+
+	t.Run("status fora de 2xx é erro", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		err := NewWebhookNotifier(srv.URL, time.Second).Notify(context.Background(), alert)
+		require.ErrorContains(t, err, "502")
+	})
+}
+
+// fakeSMTP accepts one message without authentication and hands its data
+// to the returned channel.
+func fakeSMTP(t *testing.T) (string, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 localhost")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				lines, _ := tp.ReadDotLines()
+				data <- strings.Join(lines, "\n")
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), data
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, data := fakeSMTP(t)
+
+	n := NewSMTPNotifier(addr, "", "", "estoque@localhost", []string{"compras@example.com"}, time.Second)
+	require.NoError(t, n.Notify(context.Background(), alert))
+
+	msg := <-data
+	require.Contains(t, msg, "To: compras@example.com")
+	require.Contains(t, msg, "Subject: Low stock: Mouse")
+	require.Contains(t, msg, "product 7 (Mouse, sku MS-01) is down to 4 units, reorder point is 5; reorder 50 units")
+
+	t.Run("não deixa o nome do produto criar headers", func(t *testing.T) {
+		addr, data := fakeSMTP(t)
+
+		injected := alert
+		injected.Name = "Mouse\r\nBcc: fora@example.com"
+		n := NewSMTPNotifier(addr, "", "", "estoque@localhost", []string{"compras@example.com"}, time.Second)
+		require.NoError(t, n.Notify(context.Background(), injected))
+
+		headers, _, _ := strings.Cut(<-data, "\n\n")
+		require.NotContains(t, headers, "\nBcc:")
+		require.Contains(t, headers, "Subject: Low stock: Mouse  Bcc: fora@example.com")
+	})
+
+	t.Run("codifica o assunto fora de ASCII", func(t *testing.T) {
+		addr, data := fakeSMTP(t)
+
+		accented := alert
+		accented.Name = "Teclado sem fio ç"
+		n := NewSMTPNotifier(addr, "", "", "estoque@localhost", []string{"compras@example.com"}, time.Second)
+		require.NoError(t, n.Notify(context.Background(), accented))
+
+		require.Contains(t, <-data, "Subject: =?utf-8?q?Low_stock:_Teclado_sem_fio_=C3=A7?=")
+	})
+}
+
+func TestSMTPNotifierTimeout(t *testing.T) {
+	// aceita a conexão e nunca responde
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	t.Run("desiste depois do prazo", func(t *testing.T) {
+		n := NewSMTPNotifier(ln.Addr().String(), "", "", "estoque@localhost", []string{"compras@example.com"}, 50*time.Millisecond)
+		start := time.Now()
+		require.Error(t, n.Notify(context.Background(), alert))
+		require.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("desiste quando o contexto acaba", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		n := NewSMTPNotifier(ln.Addr().String(), "", "", "estoque@localhost", []string{"compras@example.com"}, time.Minute)
+		start := time.Now()
+		require.Error(t, n.Notify(ctx, alert))
+		require.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,49 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+)
+
+// Default is the tenant every row belongs to while multi-tenancy is off.
+const Default = "default"
+
+type ctxKey int
+
+const (
+	tenantKey ctxKey = iota
+	skipKey
+)
+
+var validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+func IsValidID(id string) bool {
+	return validID.MatchString(id)
+}
+
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey, id)
+}
+
+func FromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(tenantKey).(string)
+	return id, ok && id != ""
+}
+
+// WithoutScope marks a context whose queries must see every tenant. It is
+// meant for system lookups that happen before a tenant is known, such as
+// resolving an API key, and must never be derived from request input.
+func WithoutScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipKey, true)
+}
+
+func scopeSkipped(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	skip, _ := ctx.Value(skipKey).(bool)
+	return skip
+}
@@ -0,0 +1,111 @@
+package tenant
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const fieldName = "TenantID"
+
+var (
+	ErrMissingTenant  = errors.New("tenant: no tenant in query context")
+	ErrTenantMismatch = errors.New("tenant: record belongs to another tenant")
+	ErrUpsert         = errors.New("tenant: upserts are not allowed on tenant scoped tables")
+)
+
+// Plugin scopes every statement on models with a TenantID field to the tenant
+// carried by the statement context (db.WithContext). Statements without a
+// tenant fail instead of silently reaching every tenant's rows.
+type Plugin struct{}
+
+func (Plugin) Name() string {
+	return "tenant"
+}
+
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+
+	if err := cb.Create().Before("gorm:create").Register("tenant:create", assignTenant); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+	return db.Statement.Schema.LookUpField(fieldName)
+}
+
+func scopeTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || scopeSkipped(db.Statement.Context) {
+		return
+	}
+
+	id, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrMissingTenant)
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: id},
+	}})
+}
+
+func assignTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || scopeSkipped(db.Statement.Context) {
+		return
+	}
+
+	// Save falls back to an upsert that could take over another tenant's row
+	if _, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		_ = db.AddError(ErrUpsert)
+		return
+	}
+
+	id, ok := FromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrMissingTenant)
+		return
+	}
+
+	ctx := db.Statement.Context
+	rv := db.Statement.ReflectValue
+
+	assign := func(v reflect.Value) {
+		current, zero := field.ValueOf(ctx, v)
+		if !zero && current != id {
+			_ = db.AddError(ErrTenantMismatch)
+			return
+		}
+		if err := field.Set(ctx, v, id); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			assign(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		assign(rv)
+	}
+}
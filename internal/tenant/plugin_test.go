@@ -0,0 +1,116 @@
+package tenant
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+func newMockGorm(t *testing.T) (*gorm.DB, sqlmock.Sqlmock, *sql.DB) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+
+	dialector := mysql.New(mysql.Config{
+		Conn:                      sqlDB,
+		SkipInitializeWithVersion: true,
+	})
+
+	gdb, err := gorm.Open(dialector, &gorm.Config{
+		Logger: glogger.Default.LogMode(glogger.Silent),
+	})
+	require.NoError(t, err)
+	require.NoError(t, gdb.Use(Plugin{}))
+
+	return gdb, mock, sqlDB
+}
+
+func TestPlugin(t *testing.T) {
+	ctx := WithTenant(context.Background(), "loja1")
+
+	t.Run("filtra consultas pelo tenant do contexto", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectQuery(`(?is)SELECT.*FROM .products. WHERE .products.\..tenant_id. = \?`).
+			WithArgs("loja1").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		var products []schemas.Product
+		require.NoError(t, gdb.WithContext(ctx).Find(&products).Error)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("falha sem tenant no contexto em vez de ler tudo", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		var products []schemas.Product
+		err := gdb.Find(&products).Error
+		require.ErrorIs(t, err, ErrMissingTenant)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("remoção só alcança o tenant do contexto", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`(?is)UPDATE .products. SET .deleted_at.=\? WHERE .products.\..id. = \? AND .products.\..tenant_id. = \?`).
+			WithArgs(sqlmock.AnyArg(), 7, "loja1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		require.NoError(t, gdb.WithContext(ctx).Delete(&schemas.Product{}, 7).Error)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("preenche o tenant na criação", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `products`")).
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "loja1", sqlmock.AnyArg(),
+				"Mouse", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+				sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		p := schemas.Product{Name: "Mouse"}
+		require.NoError(t, gdb.WithContext(ctx).Create(&p).Error)
+		require.Equal(t, "loja1", p.TenantID)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa gravar registro de outro tenant", func(t *testing.T) {
+		gdb, _, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		p := schemas.Product{TenantID: "loja2", Name: "Mouse"}
+		err := gdb.WithContext(ctx).Create(&p).Error
+		require.ErrorIs(t, err, ErrTenantMismatch)
+	})
+
+	t.Run("WithoutScope libera consultas de sistema", func(t *testing.T) {
+		gdb, mock, sqlDB := newMockGorm(t)
+		defer sqlDB.Close()
+
+		mock.ExpectQuery(`(?is)SELECT \* FROM .api_keys. WHERE prefix = \? ORDER BY`).
+			WithArgs("abc", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		var key schemas.APIKey
+		err := gdb.WithContext(WithoutScope(context.Background())).Where("prefix = ?", "abc").First(&key).Error
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/config"
+)
+
+func TestInit(t *testing.T) {
+	t.Run("propaga o trace context W3C recebido", func(t *testing.T) {
+		shutdown, err := Init(context.Background(), config.TracingConfig{Exporter: "none"})
+		require.NoError(t, err)
+		defer shutdown(context.Background())
+
+		header := http.Header{}
+		header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+		sc := trace.SpanContextFromContext(ctx)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+		require.True(t, sc.IsRemote())
+	})
+
+	t.Run("exporter file grava os spans no shutdown", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "traces.json")
+		shutdown, err := Init(context.Background(), config.TracingConfig{
+			Exporter:    "file",
+			File:        file,
+			ServiceName: "test",
+			SampleRatio: 1,
+		})
+		require.NoError(t, err)
+
+		_, span := Tracer().Start(context.Background(), "update product")
+		span.End()
+		require.NoError(t, shutdown(context.Background()))
+
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Contains(t, string(data), `"Name":"update product"`)
+	})
+}
@@ -0,0 +1,217 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"github.com/alissonmunhoz/go-crud-products/internal/tenant"
+	"gorm.io/gorm"
+)
+
+type Options struct {
+	// how long to wait for the receiver to answer
+	Timeout time.Duration
+	// attempts before a delivery is given up as failed
+	MaxAttempts int
+	// wait after the first failure, doubled on each new one up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// deliveries claimed per poll
+	BatchSize int
+	// deliveries of a batch sent at the same time
+	Concurrency int
+	// lets receivers on loopback and private networks through; meant for
+	// development only
+	AllowPrivateTargets bool
+}
+
+// Backoff is the wait before the next try of a delivery that failed
+// attempts times.
+func (o Options) Backoff(attempts int) time.Duration {
+	d := o.InitialBackoff
+	for i := 1; i < attempts && d < o.MaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, o.MaxBackoff)
+}
+
+// Dispatcher sends due deliveries of every tenant. Deliveries are claimed
+// before they are sent, so several replicas can run it side by side.
+type Dispatcher struct {
+	db     *gorm.DB
+	client *http.Client
+	opts   Options
+	now    func() time.Time
+}
+
+func NewDispatcher(db *gorm.DB, opts Options) *Dispatcher {
+	return &Dispatcher{db: db, client: newClient(opts.Timeout, opts.AllowPrivateTargets), opts: opts, now: time.Now}
+}
+
+// Run delivers what is due every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.DeliverDue(ctx); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// DeliverDue tries one batch of due deliveries and reports how many it
+// attempted. Up to Concurrency deliveries are sent at a time, so one slow
+// receiver does not hold up the others; each is claimed only once it has a
+// slot, so its lease starts when it is sent. A failed attempt is not an
+// error; it is recorded on the delivery and retried later.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	db := d.db.WithContext(tenant.WithoutScope(ctx))
+
+	var due []schemas.WebhookDelivery
+	if err := db.Where("status = ? AND next_attempt_at <= ?", schemas.WebhookDeliveryPending, d.now()).
+		Order("next_attempt_at, id").Limit(d.opts.BatchSize).Find(&due).Error; err != nil {
+		return 0, fmt.Errorf("error loading due webhook deliveries: %v", err)
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		errs    []error
+		claimed int
+		slots   = make(chan struct{}, max(1, d.opts.Concurrency))
+	)
+	for _, delivery := range due {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			lease, ok, err := d.claim(db, delivery)
+			if err == nil && ok {
+				err = d.deliver(ctx, db, delivery, lease)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if ok {
+				claimed++
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return claimed, errors.Join(errs...)
+}
+
+// claim pushes the next attempt past the send timeout, so no one else
+// picks the delivery up while it is in flight, and a crash mid-send just
+// makes it due again. The new next_attempt_at is the lease: results are
+// only recorded while it is still in place.
+func (d *Dispatcher) claim(db *gorm.DB, delivery schemas.WebhookDelivery) (time.Time, bool, error) {
+	// MySQL keeps milliseconds; the lease is compared with what is stored
+	lease := d.now().Add(2 * d.opts.Timeout).Truncate(time.Millisecond)
+	res := db.Model(&schemas.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, schemas.WebhookDeliveryPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", lease)
+	if res.Error != nil {
+		return time.Time{}, false, fmt.Errorf("error claiming webhook delivery %d: %v", delivery.ID, res.Error)
+	}
+	return lease, res.RowsAffected == 1, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, db *gorm.DB, delivery schemas.WebhookDelivery, lease time.Time) error {
+	var sub schemas.WebhookSubscription
+	err := db.First(&sub, delivery.SubscriptionID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return d.giveUp(db, delivery, lease, "webhook was removed")
+	case err != nil:
+		return fmt.Errorf("error loading webhook %d: %v", delivery.SubscriptionID, err)
+	case !sub.Active:
+		return d.giveUp(db, delivery, lease, "webhook is inactive")
+	}
+
+	code, sendErr := d.send(ctx, sub, delivery)
+
+	attempts := delivery.Attempts + 1
+	updates := map[string]any{"attempts": attempts, "response_code": code, "error": ""}
+	switch {
+	case sendErr == nil:
+		updates["status"] = schemas.WebhookDeliverySucceeded
+		updates["delivered_at"] = d.now()
+	case attempts >= d.opts.MaxAttempts:
+		updates["status"] = schemas.WebhookDeliveryFailed
+		updates["error"] = truncate(sendErr.Error())
+	default:
+		updates["next_attempt_at"] = d.now().Add(d.opts.Backoff(attempts))
+		updates["error"] = truncate(sendErr.Error())
+	}
+	// once the lease has passed another replica owns the delivery and its
+	// result wins
+	if err := leased(db, delivery, lease).Updates(updates).Error; err != nil {
+		return fmt.Errorf("error recording webhook delivery %d: %v", delivery.ID, err)
+	}
+	return nil
+}
+
+func leased(db *gorm.DB, delivery schemas.WebhookDelivery, lease time.Time) *gorm.DB {
+	return db.Model(&schemas.WebhookDelivery{}).Where("id = ? AND next_attempt_at = ?", delivery.ID, lease)
+}
+
+// send POSTs the payload and returns the status the receiver answered,
+// nil when it couldn't be reached. Anything but a 2xx is a failure.
+func (d *Dispatcher) send(ctx context.Context, sub schemas.WebhookSubscription, delivery schemas.WebhookDelivery) (*int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	ts := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	code := resp.StatusCode
+	if code < 200 || code > 299 {
+		return &code, fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return &code, nil
+}
+
+func (d *Dispatcher) giveUp(db *gorm.DB, delivery schemas.WebhookDelivery, lease time.Time, reason string) error {
+	err := leased(db, delivery, lease).
+		Updates(map[string]any{"status": schemas.WebhookDeliveryFailed, "error": reason}).Error
+	if err != nil {
+		return fmt.Errorf("error recording webhook delivery %d: %v", delivery.ID, err)
+	}
+	return nil
+}
+
+func truncate(s string) string {
+	if len(s) > 1024 {
+		return s[:1024]
+	}
+	return s
+}
@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	glogger "gorm.io/gorm/logger"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+)
+
+// os receptores de teste escutam em 127.0.0.1
+var opts = Options{Timeout: time.Second, MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, BatchSize: 10, AllowPrivateTargets: true}
+
+func newDispatcher(t *testing.T) (*Dispatcher, sqlmock.Sqlmock, time.Time) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	gdb, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{Logger: glogger.Default.LogMode(glogger.Silent)})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	d := NewDispatcher(gdb, opts)
+	d.now = func() time.Time { return now }
+	return d, mock, now
+}
+
+// expectDue espera a busca e a reserva de uma entrega com attempts
+// tentativas já feitas.
+func expectDue(mock sqlmock.Sqlmock, now time.Time, attempts int, url string) {
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_deliveries` WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?")).
+		WithArgs(schemas.WebhookDeliveryPending, now, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event", "payload", "status", "attempts", "next_attempt_at"}).
+			AddRow(10, 1, "ev-1", schemas.EventStockChanged, `{"id":"ev-1"}`, schemas.WebhookDeliveryPending, attempts, now))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at`=?,`updated_at`=? WHERE id = ? AND status = ? AND next_attempt_at = ?")).
+		WithArgs(now.Add(2*time.Second), sqlmock.AnyArg(), 10, schemas.WebhookDeliveryPending, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_subscriptions` WHERE `webhook_subscriptions`.`id` = ?")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "events", "secret", "active"}).
+			AddRow(1, url, schemas.EventStockChanged, "whsec_teste", true))
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("entrega assinada e registra o sucesso", func(t *testing.T) {
+		var got *http.Request
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		expectDue(mock, now, 0, srv.URL)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`delivered_at`=?,`error`=?,`response_code`=?,`status`=?,`updated_at`=? WHERE id = ? AND next_attempt_at = ?")).
+			WithArgs(1, now, "", 200, schemas.WebhookDeliverySucceeded, sqlmock.AnyArg(), 10, now.Add(2*time.Second)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		n, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		require.Equal(t, `{"id":"ev-1"}`, string(body))
+		require.Equal(t, schemas.EventStockChanged, got.Header.Get(HeaderEvent))
+		require.Equal(t, "ev-1", got.Header.Get(HeaderEventID))
+		require.Equal(t, "10", got.Header.Get(HeaderDelivery))
+		ts := strconv.FormatInt(now.Unix(), 10)
+		require.Equal(t, ts, got.Header.Get(HeaderTimestamp))
+		require.Equal(t, Sign("whsec_teste", now.Unix(), body), got.Header.Get(HeaderSignature))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("falha agenda nova tentativa com backoff", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		expectDue(mock, now, 1, srv.URL)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`error`=?,`next_attempt_at`=?,`response_code`=?,`updated_at`=? WHERE id = ? AND next_attempt_at = ?")).
+			WithArgs(2, "receiver answered 503 Service Unavailable", now.Add(2*time.Minute), 503, sqlmock.AnyArg(), 10, now.Add(2*time.Second)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("desiste após a última tentativa", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		expectDue(mock, now, 2, srv.URL)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`error`=?,`response_code`=?,`status`=?,`updated_at`=? WHERE id = ? AND next_attempt_at = ?")).
+			WithArgs(3, "receiver answered 410 Gone", 410, schemas.WebhookDeliveryFailed, sqlmock.AnyArg(), 10, now.Add(2*time.Second)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("não segue redirecionamentos", func(t *testing.T) {
+		reached := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/interno" {
+				reached = true
+				return
+			}
+			http.Redirect(w, r, "/interno", http.StatusFound)
+		}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		expectDue(mock, now, 0, srv.URL)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`error`=?,`next_attempt_at`=?,`response_code`=?,`updated_at`=? WHERE id = ? AND next_attempt_at = ?")).
+			WithArgs(1, "receiver answered 302 Found", now.Add(time.Minute), 302, sqlmock.AnyArg(), 10, now.Add(2*time.Second)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.False(t, reached)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recusa receptor em endereço privado", func(t *testing.T) {
+		reached := false
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reached = true
+		}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		d.client = newClient(time.Second, false)
+		expectDue(mock, now, 0, srv.URL)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`error`=?,`next_attempt_at`=?,`response_code`=?,`updated_at`=? WHERE id = ? AND next_attempt_at = ?")).
+			WithArgs(1, sqlmock.AnyArg(), now.Add(time.Minute), nil, sqlmock.AnyArg(), 10, now.Add(2*time.Second)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.False(t, reached)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("pula entrega reservada por outra réplica", func(t *testing.T) {
+		d, mock, now := newDispatcher(t)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_deliveries`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "next_attempt_at"}).AddRow(10, schemas.WebhookDeliveryPending, now))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at`=?")).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		n, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, n)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("envia as entregas do lote ao mesmo tempo", func(t *testing.T) {
+		// cada receptor só responde depois que o outro também recebeu
+		arrived := make(chan struct{}, 2)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			arrived <- struct{}{}
+			deadline := time.After(500 * time.Millisecond)
+			for len(arrived) < 2 {
+				select {
+				case <-deadline:
+					w.WriteHeader(http.StatusGatewayTimeout)
+					return
+				case <-time.After(time.Millisecond):
+				}
+			}
+		}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		d.opts.Concurrency = 2
+		mock.MatchExpectationsInOrder(false)
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_deliveries`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event", "payload", "status", "attempts", "next_attempt_at"}).
+				AddRow(10, 1, "ev-1", schemas.EventStockChanged, `{}`, schemas.WebhookDeliveryPending, 0, now).
+				AddRow(11, 1, "ev-2", schemas.EventStockChanged, `{}`, schemas.WebhookDeliveryPending, 0, now))
+		for _, id := range []int{10, 11} {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at`=?")).
+				WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), id, schemas.WebhookDeliveryPending, now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_subscriptions`")).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "url", "events", "secret", "active"}).
+					AddRow(1, srv.URL, schemas.EventStockChanged, "whsec_teste", true))
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`delivered_at`=?")).
+				WithArgs(1, now, "", 200, schemas.WebhookDeliverySucceeded, sqlmock.AnyArg(), id, now.Add(2*time.Second)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+
+		n, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("reserva cada entrega só quando ela vai ser enviada", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		d.opts.Concurrency = 1
+		mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_deliveries`")).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "subscription_id", "event_id", "event", "payload", "status", "attempts", "next_attempt_at"}).
+				AddRow(10, 1, "ev-1", schemas.EventStockChanged, `{}`, schemas.WebhookDeliveryPending, 0, now).
+				AddRow(11, 1, "ev-2", schemas.EventStockChanged, `{}`, schemas.WebhookDeliveryPending, 0, now))
+		for _, id := range []int{10, 11} {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `next_attempt_at`=?")).
+				WithArgs(now.Add(2*time.Second), sqlmock.AnyArg(), id, schemas.WebhookDeliveryPending, now).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+			mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `webhook_subscriptions`")).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "url", "events", "secret", "active"}).
+					AddRow(1, srv.URL, schemas.EventStockChanged, "whsec_teste", true))
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`delivered_at`=?")).
+				WithArgs(1, now, "", 200, schemas.WebhookDeliverySucceeded, sqlmock.AnyArg(), id, now.Add(2*time.Second)).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+
+		n, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("não sobrescreve o resultado depois que a reserva expira", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		d, mock, now := newDispatcher(t)
+		expectDue(mock, now, 0, srv.URL)
+		mock.ExpectBegin()
+		// outra réplica já reservou a entrega de novo
+		mock.ExpectExec(regexp.QuoteMeta("UPDATE `webhook_deliveries` SET `attempts`=?,`delivered_at`=?,`error`=?,`response_code`=?,`status`=?,`updated_at`=? WHERE id = ? AND next_attempt_at = ?")).
+			WithArgs(1, now, "", 200, schemas.WebhookDeliverySucceeded, sqlmock.AnyArg(), 10, now.Add(2*time.Second)).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		_, err := d.DeliverDue(context.Background())
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,85 @@
+// Package webhook delivers product events to the URLs tenants subscribed,
+// signed with each subscription's secret and retried with backoff.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/alissonmunhoz/go-crud-products/internal/schemas"
+	"gorm.io/gorm"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Enqueue queues event for every active subscription of the tenant that
+// listens to it. Called inside the transaction that made the change, the
+// deliveries exist if and only if the change was committed.
+func Enqueue(tx *gorm.DB, event schemas.Event) error {
+	var subs []schemas.WebhookSubscription
+	if err := tx.Where("active = ?", true).Find(&subs).Error; err != nil {
+		return fmt.Errorf("error loading webhook subscriptions: %v", err)
+	}
+
+	var targets []schemas.WebhookSubscription
+	for _, s := range subs {
+		if s.Wants(event.Type) {
+			targets = append(targets, s)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding %s event: %v", event.Type, err)
+	}
+
+	deliveries := make([]schemas.WebhookDelivery, 0, len(targets))
+	for _, s := range targets {
+		deliveries = append(deliveries, schemas.WebhookDelivery{
+			SubscriptionID: s.ID,
+			EventID:        event.ID,
+			Event:          event.Type,
+			Payload:        string(payload),
+			Status:         schemas.WebhookDeliveryPending,
+			NextAttemptAt:  event.OccurredAt,
+		})
+	}
+	if err := tx.Create(&deliveries).Error; err != nil {
+		return fmt.Errorf("error queueing webhook deliveries: %v", err)
+	}
+	return nil
+}
+
+// Sign returns the X-Webhook-Signature of body sent at timestamp: the hex
+// HMAC-SHA256 of "<timestamp>.<body>". Signing the timestamp lets
+// receivers refuse replays of old deliveries.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a new random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	sig := Sign("whsec_teste", 1700000000, []byte(`{"id":"ev-1"}`))
+
+	require.Equal(t, "sha256=96f489548ad5412155363244cbe067b984b32861ef4b8e60f88c30369731477b", sig)
+	require.NotEqual(t, sig, Sign("whsec_teste", 1700000001, []byte(`{"id":"ev-1"}`)))
+	require.NotEqual(t, sig, Sign("whsec_outro", 1700000000, []byte(`{"id":"ev-1"}`)))
+}
+
+func TestBackoff(t *testing.T) {
+	opts := Options{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+
+	require.Equal(t, 30*time.Second, opts.Backoff(1))
+	require.Equal(t, time.Minute, opts.Backoff(2))
+	require.Equal(t, 4*time.Minute, opts.Backoff(4))
+	require.Equal(t, 5*time.Minute, opts.Backoff(5))
+	require.Equal(t, 5*time.Minute, opts.Backoff(40))
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, host := range []string{"localhost", "api.localhost", "127.0.0.1", "10.0.0.7", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fd00::1", "::ffff:127.0.0.1"} {
+		require.ErrorIs(t, CheckHost(host), ErrPrivateTarget, host)
+	}
+	for _, host := range []string{"erp.example.com", "8.8.8.8", "2606:4700:4700::1111"} {
+		require.NoError(t, CheckHost(host), host)
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	for _, addr := range []string{
+		"0.1.2.3",           // 0.0.0.0/8
+		"100.64.0.1",        // 100.64.0.0/10
+		"100.127.255.254",   // 100.64.0.0/10
+		"192.0.0.8",         // 192.0.0.0/24
+		"192.0.2.10",        // 192.0.2.0/24
+		"192.88.99.1",       // 192.88.99.0/24
+		"198.18.0.1",        // 198.18.0.0/15
+		"198.19.255.254",    // 198.18.0.0/15
+		"198.51.100.7",      // 198.51.100.0/24
+		"203.0.113.10",      // 203.0.113.0/24
+		"240.0.0.1",         // 240.0.0.0/4
+		"255.255.255.255",   // 240.0.0.0/4
+		"::a00:1",           // ::/96
+		"64:ff9b::a00:1",    // 64:ff9b::/96
+		"64:ff9b:1::1",      // 64:ff9b:1::/48
+		"100::1",            // 100::/64
+		"2001::1",           // 2001::/23
+		"2001:1ff::1",       // 2001::/23
+		"2001:db8::1",       // 2001:db8::/32
+		"2002:a00:1::1",     // 2002::/16
+		"3fff::1",           // 3fff::/20
+		"5f00::1",           // 5f00::/16
+		"fec0::1",           // fec0::/10
+		"::ffff:100.64.0.1", // IPv4 mapeado
+	} {
+		require.False(t, IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{"8.8.8.8", "100.128.0.1", "198.20.0.1", "223.255.255.1", "2606:4700:4700::1111", "2001:4860::8888"} {
+		require.True(t, IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+}